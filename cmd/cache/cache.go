@@ -0,0 +1,42 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package cache
+
+import (
+	"github.com/spf13/cobra"
+
+	"opendev.org/airship/airshipctl/pkg/config"
+)
+
+const (
+	cacheLong = `
+Provides capabilities for managing the cache of container outputs stored in
+the airshipctl working directory. Outputs of containers which have caching
+enabled are reused as long as their image, configuration and input stay the same.
+`
+)
+
+// NewCacheCommand creates a command for managing cached container outputs
+func NewCacheCommand(cfgFactory config.Factory) *cobra.Command {
+	cacheRootCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Airshipctl command to manage cached container outputs",
+		Long:  cacheLong[1:],
+	}
+
+	cacheRootCmd.AddCommand(NewPruneCommand(cfgFactory))
+
+	return cacheRootCmd
+}
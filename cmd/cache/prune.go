@@ -0,0 +1,59 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package cache
+
+import (
+	"github.com/spf13/cobra"
+
+	"opendev.org/airship/airshipctl/pkg/config"
+	"opendev.org/airship/airshipctl/pkg/container"
+)
+
+const (
+	pruneLong = `
+Remove cached container outputs. By default all the entries are removed,
+use --older-than to remove only entries which weren't used for the given period of time.
+`
+
+	pruneExample = `
+Remove all cached container outputs
+# airshipctl cache prune
+
+Remove cached container outputs which weren't used for a week
+# airshipctl cache prune --older-than 168h
+`
+)
+
+// NewPruneCommand creates a command which removes cached container outputs
+func NewPruneCommand(cfgFactory config.Factory) *cobra.Command {
+	o := &container.CachePruneCommand{Factory: cfgFactory}
+	cmd := &cobra.Command{
+		Use:     "prune",
+		Short:   "Airshipctl command to remove cached container outputs",
+		Long:    pruneLong[1:],
+		Example: pruneExample,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Writer = cmd.OutOrStdout()
+			return o.RunE()
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.DurationVar(&o.OlderThan, "older-than", 0,
+		"remove only entries which weren't used for longer than the given duration, e.g. 24h")
+
+	return cmd
+}
@@ -0,0 +1,74 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"opendev.org/airship/airshipctl/pkg/config"
+)
+
+const (
+	deleteContextLong = `
+Deletes the context from the airshipctl config file. The current context is deleted only
+if the context to switch to is specified with --use-context flag.
+`
+
+	deleteContextExample = `
+Delete a context named "exampleContext"
+# airshipctl config delete-context exampleContext
+
+Delete the current context named "exampleContext" and switch to the context named "otherContext"
+# airshipctl config delete-context exampleContext --use-context otherContext
+`
+
+	deleteContextUseContextFlag = "use-context"
+)
+
+// NewDeleteContextCommand creates a command for deleting a context from the airshipctl config
+func NewDeleteContextCommand(cfgFactory config.Factory) *cobra.Command {
+	var useContext string
+	cmd := &cobra.Command{
+		Use:     "delete-context CONTEXT_NAME",
+		Short:   "Airshipctl command to delete context from airshipctl config file",
+		Long:    deleteContextLong[1:],
+		Example: deleteContextExample,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := cfgFactory()
+			if err != nil {
+				return err
+			}
+			currentContext := cfg.CurrentContext
+			if err = config.RunDeleteContext(args[0], useContext, cfg); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Context %q deleted.\n", args[0])
+			if cfg.CurrentContext != currentContext {
+				fmt.Fprintf(cmd.OutOrStdout(), "Current context switched to %q.\n", cfg.CurrentContext)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&useContext, deleteContextUseContextFlag, "",
+		"switch to the context after the deletion, required to delete the current context")
+
+	return cmd
+}
@@ -0,0 +1,68 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config_test
+
+import (
+	"errors"
+	"testing"
+
+	cmd "opendev.org/airship/airshipctl/cmd/config"
+	"opendev.org/airship/airshipctl/pkg/config"
+	"opendev.org/airship/airshipctl/testutil"
+)
+
+func TestConfigDeleteContext(t *testing.T) {
+	conf, cleanup := testutil.InitConfig(t)
+	defer cleanup(t)
+	conf.Contexts["def_another"] = testutil.DummyContext()
+	settings := func() (*config.Config, error) {
+		return conf, nil
+	}
+	cmdTests := []*testutil.CmdTest{
+		{
+			Name:    "config-delete-context-with-help",
+			CmdLine: "--help",
+			Cmd:     cmd.NewDeleteContextCommand(nil),
+		},
+		{
+			Name:    "config-delete-context",
+			CmdLine: "def_target",
+			Cmd:     cmd.NewDeleteContextCommand(settings),
+		},
+		{
+			Name:    "config-delete-context-no-args",
+			CmdLine: "",
+			Cmd:     cmd.NewDeleteContextCommand(settings),
+			Error:   errors.New("accepts 1 arg(s), received 0"),
+		},
+		{
+			Name:    "config-delete-context-current",
+			CmdLine: "def_ephemeral",
+			Cmd:     cmd.NewDeleteContextCommand(settings),
+			Error: errors.New("context 'def_ephemeral' is the current context, " +
+				"switch to another context using use-context first or specify the context to switch to " +
+				"with --use-context"),
+		},
+		{
+			Name:    "config-delete-context-current-use-context",
+			CmdLine: "def_ephemeral --use-context def_another",
+			Cmd:     cmd.NewDeleteContextCommand(settings),
+		},
+	}
+
+	for _, tt := range cmdTests {
+		testutil.RunTest(t, tt)
+	}
+}
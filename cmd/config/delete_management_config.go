@@ -0,0 +1,72 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"opendev.org/airship/airshipctl/pkg/config"
+)
+
+const (
+	deleteManagementConfigLong = `
+Deletes the management config from the airshipctl config file. The management config referenced
+by contexts is deleted only if --force flag is specified, the references are cleared in that case.
+The management config referenced by the current context is never deleted, switch to another context
+using use-context first.
+`
+
+	deleteManagementConfigExample = `
+Delete a management config named "exampleManagementConfig"
+# airshipctl config delete-management-config exampleManagementConfig
+
+Delete a management config named "exampleManagementConfig" and clear its references in contexts
+# airshipctl config delete-management-config exampleManagementConfig --force
+`
+
+	deleteManagementConfigForceFlag = "force"
+)
+
+// NewDeleteManagementConfigCommand creates a command for deleting a management config from the airshipctl config
+func NewDeleteManagementConfigCommand(cfgFactory config.Factory) *cobra.Command {
+	var force bool
+	cmd := &cobra.Command{
+		Use:     "delete-management-config MGMT_CONFIG_NAME",
+		Short:   "Airshipctl command to delete management config from airshipctl config file",
+		Long:    deleteManagementConfigLong[1:],
+		Example: deleteManagementConfigExample,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := cfgFactory()
+			if err != nil {
+				return err
+			}
+			if err = config.RunDeleteManagementConfig(args[0], force, cfg); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Management config %q deleted.\n", args[0])
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, deleteManagementConfigForceFlag, false,
+		"delete the management config even if it's referenced by contexts")
+
+	return cmd
+}
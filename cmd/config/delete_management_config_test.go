@@ -0,0 +1,72 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config_test
+
+import (
+	"errors"
+	"testing"
+
+	cmd "opendev.org/airship/airshipctl/cmd/config"
+	"opendev.org/airship/airshipctl/pkg/config"
+	"opendev.org/airship/airshipctl/testutil"
+)
+
+func TestConfigDeleteManagementConfig(t *testing.T) {
+	conf, cleanup := testutil.InitConfig(t)
+	defer cleanup(t)
+	conf.AddManagementConfig("dummy_management_config")
+	conf.AddManagementConfig("current_management_config")
+	conf.Contexts["def_another"] = testutil.DummyContext()
+	conf.Contexts["def_ephemeral"].ManagementConfiguration = "current_management_config"
+	settings := func() (*config.Config, error) {
+		return conf, nil
+	}
+	cmdTests := []*testutil.CmdTest{
+		{
+			Name:    "config-delete-management-config-with-help",
+			CmdLine: "--help",
+			Cmd:     cmd.NewDeleteManagementConfigCommand(nil),
+		},
+		{
+			Name:    "config-delete-management-config-no-args",
+			CmdLine: "",
+			Cmd:     cmd.NewDeleteManagementConfigCommand(settings),
+			Error:   errors.New("accepts 1 arg(s), received 0"),
+		},
+		{
+			Name:    "config-delete-management-config-referenced",
+			CmdLine: "dummy_management_config",
+			Cmd:     cmd.NewDeleteManagementConfigCommand(settings),
+			Error: errors.New("management config 'dummy_management_config' is referenced by context(s) def_another, " +
+				"use --force to delete it anyway"),
+		},
+		{
+			Name:    "config-delete-management-config-referenced-by-current-context",
+			CmdLine: "current_management_config --force",
+			Cmd:     cmd.NewDeleteManagementConfigCommand(settings),
+			Error: errors.New("management config 'current_management_config' is referenced by the current context " +
+				"'def_ephemeral' and can't be deleted, switch to another context using use-context first"),
+		},
+		{
+			Name:    "config-delete-management-config",
+			CmdLine: "dummy_management_config --force",
+			Cmd:     cmd.NewDeleteManagementConfigCommand(settings),
+		},
+	}
+
+	for _, tt := range cmdTests {
+		testutil.RunTest(t, tt)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"opendev.org/airship/airshipctl/pkg/config"
+)
+
+const (
+	deleteManifestLong = `
+Deletes the manifest from the airshipctl config file. The manifest referenced by contexts is deleted only
+if --force flag is specified, the references are cleared in that case. The manifest referenced by
+the current context is never deleted, switch to another context using use-context first.
+`
+
+	deleteManifestExample = `
+Delete a manifest named "exampleManifest"
+# airshipctl config delete-manifest exampleManifest
+
+Delete a manifest named "exampleManifest" and clear its references in contexts
+# airshipctl config delete-manifest exampleManifest --force
+`
+
+	deleteManifestForceFlag = "force"
+)
+
+// NewDeleteManifestCommand creates a command for deleting a manifest from the airshipctl config
+func NewDeleteManifestCommand(cfgFactory config.Factory) *cobra.Command {
+	var force bool
+	cmd := &cobra.Command{
+		Use:     "delete-manifest MANIFEST_NAME",
+		Short:   "Airshipctl command to delete manifest from airshipctl config file",
+		Long:    deleteManifestLong[1:],
+		Example: deleteManifestExample,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := cfgFactory()
+			if err != nil {
+				return err
+			}
+			if err = config.RunDeleteManifest(args[0], force, cfg); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Manifest %q deleted.\n", args[0])
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, deleteManifestForceFlag, false,
+		"delete the manifest even if it's referenced by contexts")
+
+	return cmd
+}
@@ -0,0 +1,69 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config_test
+
+import (
+	"errors"
+	"testing"
+
+	cmd "opendev.org/airship/airshipctl/cmd/config"
+	"opendev.org/airship/airshipctl/pkg/config"
+	"opendev.org/airship/airshipctl/testutil"
+)
+
+func TestConfigDeleteManifest(t *testing.T) {
+	conf, cleanup := testutil.InitConfig(t)
+	defer cleanup(t)
+	conf.Manifests["unused_manifest"] = config.NewManifest()
+	settings := func() (*config.Config, error) {
+		return conf, nil
+	}
+	cmdTests := []*testutil.CmdTest{
+		{
+			Name:    "config-delete-manifest-with-help",
+			CmdLine: "--help",
+			Cmd:     cmd.NewDeleteManifestCommand(nil),
+		},
+		{
+			Name:    "config-delete-manifest",
+			CmdLine: "unused_manifest",
+			Cmd:     cmd.NewDeleteManifestCommand(settings),
+		},
+		{
+			Name:    "config-delete-manifest-no-args",
+			CmdLine: "",
+			Cmd:     cmd.NewDeleteManifestCommand(settings),
+			Error:   errors.New("accepts 1 arg(s), received 0"),
+		},
+		{
+			Name:    "config-delete-manifest-referenced",
+			CmdLine: "dummy_manifest",
+			Cmd:     cmd.NewDeleteManifestCommand(settings),
+			Error: errors.New("manifest 'dummy_manifest' is referenced by context(s) def_ephemeral, " +
+				"use --force to delete it anyway"),
+		},
+		{
+			Name:    "config-delete-manifest-referenced-by-current-context",
+			CmdLine: "dummy_manifest --force",
+			Cmd:     cmd.NewDeleteManifestCommand(settings),
+			Error: errors.New("manifest 'dummy_manifest' is referenced by the current context 'def_ephemeral' " +
+				"and can't be deleted, switch to another context using use-context first"),
+		},
+	}
+
+	for _, tt := range cmdTests {
+		testutil.RunTest(t, tt)
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"opendev.org/airship/airshipctl/pkg/config"
+)
+
+const (
+	renameContextLong = `
+Renames the context in the airshipctl config file, the current context is updated if it's the renamed one.
+`
+
+	renameContextExample = `
+Rename a context named "exampleContext" to "newName"
+# airshipctl config rename-context exampleContext newName
+`
+)
+
+// NewRenameContextCommand creates a command for renaming a context in the airshipctl config
+func NewRenameContextCommand(cfgFactory config.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "rename-context CONTEXT_NAME NEW_CONTEXT_NAME",
+		Short:   "Airshipctl command to rename context in airshipctl config file",
+		Long:    renameContextLong[1:],
+		Example: renameContextExample,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := cfgFactory()
+			if err != nil {
+				return err
+			}
+			if err = config.RunRenameContext(args[0], args[1], cfg); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Context %q renamed to %q.\n", args[0], args[1])
+
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,60 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"opendev.org/airship/airshipctl/pkg/config"
+)
+
+const (
+	renameManagementConfigLong = `
+Renames the management config in the airshipctl config file, the contexts referencing the management config are updated.
+`
+
+	renameManagementConfigExample = `
+Rename a management config named "exampleManagementConfig" to "newName"
+# airshipctl config rename-management-config exampleManagementConfig newName
+`
+)
+
+// NewRenameManagementConfigCommand creates a command for renaming a management config in the airshipctl config
+func NewRenameManagementConfigCommand(cfgFactory config.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "rename-management-config MGMT_CONFIG_NAME NEW_MGMT_CONFIG_NAME",
+		Short:   "Airshipctl command to rename management config in airshipctl config file",
+		Long:    renameManagementConfigLong[1:],
+		Example: renameManagementConfigExample,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := cfgFactory()
+			if err != nil {
+				return err
+			}
+			if err = config.RunRenameManagementConfig(args[0], args[1], cfg); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Management config %q renamed to %q.\n", args[0], args[1])
+
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,62 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config_test
+
+import (
+	"errors"
+	"testing"
+
+	cmd "opendev.org/airship/airshipctl/cmd/config"
+	"opendev.org/airship/airshipctl/pkg/config"
+	"opendev.org/airship/airshipctl/testutil"
+)
+
+func TestConfigRenameManagementConfig(t *testing.T) {
+	conf, cleanup := testutil.InitConfig(t)
+	defer cleanup(t)
+	conf.AddManagementConfig("dummy_management_config")
+	conf.Contexts["def_ephemeral"].ManagementConfiguration = "dummy_management_config"
+	settings := func() (*config.Config, error) {
+		return conf, nil
+	}
+	cmdTests := []*testutil.CmdTest{
+		{
+			Name:    "config-rename-management-config-with-help",
+			CmdLine: "--help",
+			Cmd:     cmd.NewRenameManagementConfigCommand(nil),
+		},
+		{
+			Name:    "config-rename-management-config",
+			CmdLine: "dummy_management_config site",
+			Cmd:     cmd.NewRenameManagementConfigCommand(settings),
+		},
+		{
+			Name:    "config-rename-management-config-no-args",
+			CmdLine: "",
+			Cmd:     cmd.NewRenameManagementConfigCommand(settings),
+			Error:   errors.New("accepts 2 arg(s), received 0"),
+		},
+		{
+			Name:    "config-rename-management-config-does-not-exist",
+			CmdLine: "foo bar",
+			Cmd:     cmd.NewRenameManagementConfigCommand(settings),
+			Error:   errors.New("Unknown management configuration 'foo'."),
+		},
+	}
+
+	for _, tt := range cmdTests {
+		testutil.RunTest(t, tt)
+	}
+}
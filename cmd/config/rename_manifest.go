@@ -0,0 +1,60 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"opendev.org/airship/airshipctl/pkg/config"
+)
+
+const (
+	renameManifestLong = `
+Renames the manifest in the airshipctl config file, the contexts referencing the manifest are updated.
+`
+
+	renameManifestExample = `
+Rename a manifest named "exampleManifest" to "newName"
+# airshipctl config rename-manifest exampleManifest newName
+`
+)
+
+// NewRenameManifestCommand creates a command for renaming a manifest in the airshipctl config
+func NewRenameManifestCommand(cfgFactory config.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "rename-manifest MANIFEST_NAME NEW_MANIFEST_NAME",
+		Short:   "Airshipctl command to rename manifest in airshipctl config file",
+		Long:    renameManifestLong[1:],
+		Example: renameManifestExample,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := cfgFactory()
+			if err != nil {
+				return err
+			}
+			if err = config.RunRenameManifest(args[0], args[1], cfg); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Manifest %q renamed to %q.\n", args[0], args[1])
+
+			return nil
+		},
+	}
+
+	return cmd
+}
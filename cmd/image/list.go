@@ -0,0 +1,51 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"opendev.org/airship/airshipctl/pkg/config"
+	"opendev.org/airship/airshipctl/pkg/image"
+)
+
+const (
+	listLong = `
+List container images referenced by the documents of the phase config bundle
+and by the rendered documents of every phase of the site.
+`
+
+	listExample = `
+List images used by the site
+# airshipctl image list
+`
+)
+
+// NewListCommand creates a command which prints images used by the site
+func NewListCommand(cfgFactory config.Factory) *cobra.Command {
+	o := &image.ListCommand{Factory: cfgFactory}
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "Airshipctl command to list container images used by the site",
+		Long:    listLong[1:],
+		Example: listExample,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Writer = cmd.OutOrStdout()
+			return o.RunE()
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,57 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"opendev.org/airship/airshipctl/pkg/container"
+	"opendev.org/airship/airshipctl/pkg/image"
+)
+
+const (
+	loadLong = `
+Load container images from the tarball created by 'airshipctl image save'.
+Airship config and site manifests are not required, so the command can be
+used on the jump host of an air-gapped site.
+`
+
+	loadExample = `
+Load images from images.tar
+# airshipctl image load -i images.tar
+`
+)
+
+// NewLoadCommand creates a command which loads images from a tarball
+func NewLoadCommand() *cobra.Command {
+	o := &image.LoadCommand{}
+	cmd := &cobra.Command{
+		Use:     "load",
+		Short:   "Airshipctl command to load container images from a tarball",
+		Long:    loadLong[1:],
+		Example: loadExample,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Reader = cmd.InOrStdin()
+			o.Writer = cmd.OutOrStdout()
+			return o.RunE()
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&o.Input, "input", "i", "", "path to the tarball, if not specified tarball is read from stdin")
+	flags.StringVar(&o.Driver, "container-runtime", container.DriverDocker, "container runtime used to load images")
+	return cmd
+}
@@ -0,0 +1,57 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"opendev.org/airship/airshipctl/pkg/config"
+	"opendev.org/airship/airshipctl/pkg/container"
+	"opendev.org/airship/airshipctl/pkg/image"
+)
+
+const (
+	saveLong = `
+Save container images used by the site into a single tarball. Images that are
+not present locally are pulled first. The tarball contains a manifest with
+the list of saved images and can be imported with 'airshipctl image load'.
+`
+
+	saveExample = `
+Save images used by the site to images.tar
+# airshipctl image save -o images.tar
+`
+)
+
+// NewSaveCommand creates a command which saves images used by the site into a tarball
+func NewSaveCommand(cfgFactory config.Factory) *cobra.Command {
+	o := &image.SaveCommand{Factory: cfgFactory}
+	cmd := &cobra.Command{
+		Use:     "save",
+		Short:   "Airshipctl command to save container images used by the site into a tarball",
+		Long:    saveLong[1:],
+		Example: saveExample,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Writer = cmd.OutOrStdout()
+			return o.RunE()
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&o.Output, "output", "o", "", "path to the tarball, if not specified tarball is written to stdout")
+	flags.StringVar(&o.Driver, "container-runtime", container.DriverDocker, "container runtime used to save images")
+	return cmd
+}
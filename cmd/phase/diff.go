@@ -0,0 +1,83 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package phase
+
+import (
+	"github.com/spf13/cobra"
+
+	"opendev.org/airship/airshipctl/pkg/config"
+	"opendev.org/airship/airshipctl/pkg/phase"
+)
+
+const (
+	diffLong = `
+Show the differences between the documents of a phase rendered from the current manifests
+and from another version of the phase repository. Documents are matched by group, version,
+kind, namespace and name, the values of Secret data are masked.
+
+The version to compare with is either a path to another checkout of the phase repository
+or a git revision (branch, tag or commit) of it. With --live flag the documents which would be
+deployed by the phase are compared with the objects of the target cluster instead: only the fields
+set in the documents are compared, objects of the phase inventory which are not in the documents
+any more are shown as removed. Kubeconfig and context of the cluster are taken from the cluster map.
+`
+
+	diffExample = `
+Show how the documents of 'initinfra' phase changed since the last commit
+# airshipctl phase diff initinfra --against HEAD~1
+
+Compare the documents of 'initinfra' phase with another checkout of the phase repository
+# airshipctl phase diff initinfra --against /tmp/airshipctl
+
+Show what would be changed in the cluster by 'initinfra' phase
+# airshipctl phase diff initinfra --live
+
+Print the differences in json format
+# airshipctl phase diff initinfra --against origin/master -o json
+`
+)
+
+// NewDiffCommand creates a command which shows the differences between phase renders
+func NewDiffCommand(cfgFactory config.Factory) *cobra.Command {
+	p := &phase.DiffCommand{Factory: cfgFactory}
+	diffCmd := &cobra.Command{
+		Use:     "diff PHASE_NAME",
+		Short:   "Airshipctl command to show the differences between phase renders",
+		Long:    diffLong[1:],
+		Example: diffExample,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p.PhaseID.Name = args[0]
+			p.Writer = cmd.OutOrStdout()
+			return p.RunE()
+		},
+	}
+	addDiffFlags(p, diffCmd)
+	return diffCmd
+}
+
+// addDiffFlags adds flags for phase diff sub-command
+func addDiffFlags(options *phase.DiffCommand, cmd *cobra.Command) {
+	flags := cmd.Flags()
+
+	flags.StringVar(&options.Against, "against", "",
+		"path to another checkout of the phase repository or git revision of it to compare with")
+	flags.BoolVar(&options.Live, "live", false,
+		"compare the phase documents with the objects of the target cluster")
+	flags.StringVarP(&options.Format, "output", "o", phase.TextOutputFormat,
+		"output format. Supported formats are 'text', 'json' and 'yaml'")
+	flags.BoolVarP(&options.FailOnDecryptionError, "decrypt", "d", false,
+		"ensure that decryption of encrypted documents has finished successfully")
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"opendev.org/airship/airshipctl/pkg/config"
+	"opendev.org/airship/airshipctl/pkg/document"
 	"opendev.org/airship/airshipctl/pkg/phase"
 )
 
@@ -33,6 +34,27 @@ Get all 'initinfra' phase documents containing labels "app=helm" and "service=ti
 Get all phase documents containing labels "app=helm" and "service=tiller" and kind 'Deployment'
 # airshipctl phase render initinfra -l app=helm,service=tiller -k Deployment
 
+Get all 'initinfra' phase documents except Secrets and the documents labeled "app=helm"
+# airshipctl phase render initinfra --exclude kind=Secret --exclude label=app=helm
+
+Get all 'initinfra' phase Deployments with 3 replicas and the documents in 'metal3' namespace
+# airshipctl phase render initinfra -k Deployment --field spec.replicas=3 --or namespace=metal3
+
+Get all 'initinfra' phase documents having a container with one of the given images
+# airshipctl phase render initinfra --field '{.spec.template.spec.containers[*].image} in (app:v1,app:v2)'
+
+Get all 'initinfra' phase documents as KRM function ResourceList
+# airshipctl phase render initinfra -o resourcelist
+
+Write each 'initinfra' phase document to a separate file of the directory usable as kustomize root
+# airshipctl phase render initinfra -o dir=/tmp/initinfra
+
+Get all 'initinfra' phase documents annotated with their source files, kustomizations and patches
+# airshipctl phase render initinfra --show-origin
+
+Get all 'initinfra' phase documents logging the values and the fields changed by replacement transformers
+# airshipctl phase render initinfra --trace-replacements
+
 Get all documents from config bundle
 # airshipctl phase render --source config
 
@@ -67,10 +89,26 @@ func addRenderFlags(filterOptions *phase.RenderCommand, cmd *cobra.Command) {
 	flags.StringVarP(&filterOptions.Annotation, "annotation", "a", "", "filter documents by Annotations")
 	flags.StringVarP(&filterOptions.APIVersion, "apiversion", "g", "", "filter documents by API version")
 	flags.StringVarP(&filterOptions.Kind, "kind", "k", "", "filter documents by Kind")
+	flags.StringArrayVar(&filterOptions.Fields, "field", nil,
+		"filter documents by field selector, e.g. 'spec.replicas=3', '{.spec.type} in (A,B)' or '!spec.paused'")
+	flags.StringArrayVar(&filterOptions.Exclude, "exclude", nil,
+		"exclude documents matching selector expression of semicolon separated conditions, e.g. 'kind=Secret;name=creds'.\n"+
+			"Supported keys are apiVersion, kind, name, namespace, label, annotation and field")
+	flags.StringArrayVar(&filterOptions.Or, "or", nil,
+		"add documents matching selector expression to the documents matched by the other filters")
 	flags.StringVarP(&filterOptions.Source, "source", "s", phase.RenderSourcePhase,
 		"phase: phase entrypoint will be rendered by kustomize, if entrypoint is not specified error will be returned\n"+
 			"executor: rendering will be performed by executor if the phase\n"+
 			"config: this will render bundle containing phase and executor documents")
+	flags.StringVarP(&filterOptions.Output, "output", "o", document.YAMLOutputFormat,
+		"output format. Supported formats are 'yaml', 'json' (kubernetes List), 'resourcelist' (KRM function "+
+			"ResourceList)\nand 'dir=<path>' (file per document and kustomization.yaml in the directory)")
+	flags.BoolVar(&filterOptions.ShowOrigin, "show-origin", false,
+		"annotate documents with their source file, the chain of kustomizations and the patches applied,\n"+
+			"supported only for phase source")
+	flags.BoolVar(&filterOptions.TraceReplacements, "trace-replacements", false,
+		"log source values, matched targets and changed fields of replacement transformers, "+
+			"values taken from Secrets are masked")
 	flags.BoolVarP(&filterOptions.FailOnDecryptionError, "decrypt", "d", false,
 		"ensure that decryption of encrypted documents has finished successfully")
 }
@@ -64,5 +64,7 @@ func NewRunCommand(cfgFactory config.Factory) *cobra.Command {
 	flags := runCmd.Flags()
 	flags.BoolVar(&f.DryRun, "dry-run", false, "simulate phase execution")
 	flags.DurationVar(&f.Timeout, "wait-timeout", 0, "wait timeout")
+	flags.BoolVar(&p.TolerateDecryptionFailures, "tolerate-decryption-failures", false,
+		"leave the documents which can't be decrypted encrypted instead of failing")
 	return runCmd
 }
@@ -33,11 +33,15 @@ yaml explorer of a phase with relative path
 
 yaml explorer of a phase with phase name
 # airshipctl phase tree initinfra-ephemeral
+
+Graphviz diagram of a phase
+# airshipctl phase tree initinfra-ephemeral -o dot | dot -Tsvg > initinfra-ephemeral.svg
 `
 )
 
 // NewTreeCommand creates a command to get summarized tree view of the kustomize entrypoints of a phase
 func NewTreeCommand(cfgFactory config.Factory) *cobra.Command {
+	var output string
 	treeCmd := &cobra.Command{
 		Use:     "tree PHASE_NAME",
 		Short:   "Airshipctl command to show tree view of kustomize entrypoints of phase",
@@ -50,10 +54,13 @@ func NewTreeCommand(cfgFactory config.Factory) *cobra.Command {
 				Factory: cfgFactory,
 				PhaseID: ifc.ID{},
 				Writer:  writer,
+				Output:  output,
 			}
 			p.Argument = args[0]
 			return p.RunE()
 		},
 	}
+	treeCmd.Flags().StringVarP(&output, "output", "o", phase.TreeOutputTree,
+		"output format. Supported formats are 'tree', 'json' and 'dot' (Graphviz)")
 	return treeCmd
 }
@@ -24,11 +24,19 @@ import (
 const (
 	validLong = `
 Validates phase and its documents. To list the phases associated with a site, run 'airshipctl phase list'.
+By default the documents are validated by the validation container, with --offline flag they are
+validated in-process against the schemas from local schemaLocation of the phase validation config
+and the CRDs found in the documents or crdList.
+The documents are also checked against the ValidationPolicy documents named in policies of the phase
+validation config, the violations are reported per document and the ones of error severity fail the validation.
 `
 
 	validExample = `
 To validate initinfra phase
 # airshipctl phase validate initinfra
+
+To validate initinfra phase without running validation container
+# airshipctl phase validate initinfra --offline
 `
 )
 
@@ -49,6 +57,10 @@ func NewValidateCommand(cfgFactory config.Factory) *cobra.Command {
 			return p.RunE()
 		},
 	}
-
+	flags := validCmd.Flags()
+	flags.BoolVar(&p.Options.NoCache, "no-cache", false,
+		"execute validation containers even if their output is cached")
+	flags.BoolVar(&p.Options.Offline, "offline", false,
+		"validate documents against local schemas and CRDs without running validation container")
 	return validCmd
 }
@@ -50,6 +50,8 @@ func NewValidateCommand(cfgFactory config.Factory) *cobra.Command {
 			return r.RunE()
 		},
 	}
-
+	flags := runCmd.Flags()
+	flags.BoolVar(&r.Options.NoCache, "no-cache", false,
+		"execute validation containers even if their output is cached")
 	return runCmd
 }
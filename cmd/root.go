@@ -23,12 +23,15 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"opendev.org/airship/airshipctl/cmd/baremetal"
+	"opendev.org/airship/airshipctl/cmd/cache"
 	"opendev.org/airship/airshipctl/cmd/cluster"
 	"opendev.org/airship/airshipctl/cmd/completion"
 	"opendev.org/airship/airshipctl/cmd/config"
 	"opendev.org/airship/airshipctl/cmd/document"
+	"opendev.org/airship/airshipctl/cmd/image"
 	"opendev.org/airship/airshipctl/cmd/phase"
 	"opendev.org/airship/airshipctl/cmd/plan"
+	"opendev.org/airship/airshipctl/cmd/secret"
 	cfg "opendev.org/airship/airshipctl/pkg/config"
 	"opendev.org/airship/airshipctl/pkg/log"
 )
@@ -77,12 +80,15 @@ func NewRootCommand(out io.Writer) (*cobra.Command, *RootOptions) {
 // default commands to airshipctl
 func AddDefaultAirshipCTLCommands(cmd *cobra.Command, factory cfg.Factory) *cobra.Command {
 	cmd.AddCommand(baremetal.NewBaremetalCommand(factory))
+	cmd.AddCommand(cache.NewCacheCommand(factory))
 	cmd.AddCommand(cluster.NewClusterCommand(factory))
 	cmd.AddCommand(completion.NewCompletionCommand())
 	cmd.AddCommand(document.NewDocumentCommand(factory))
 	cmd.AddCommand(config.NewConfigCommand(factory))
+	cmd.AddCommand(image.NewImageCommand(factory))
 	cmd.AddCommand(phase.NewPhaseCommand(factory))
 	cmd.AddCommand(plan.NewPlanCommand(factory))
+	cmd.AddCommand(secret.NewSecretCommand(factory))
 	cmd.AddCommand(NewVersionCommand())
 
 	return cmd
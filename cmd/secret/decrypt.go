@@ -0,0 +1,69 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package secret
+
+import (
+	"github.com/spf13/cobra"
+
+	"opendev.org/airship/airshipctl/pkg/config"
+	"opendev.org/airship/airshipctl/pkg/secret"
+)
+
+const (
+	decryptLong = `
+Decrypt the documents of the given files encrypted with sops. Keys are taken from
+SOPS_AGE_KEY, SOPS_AGE_KEY_FILE, SOPS_IMPORT_AGE, SOPS_IMPORT_PGP and SOPS_PGP_KEY_FILE
+env variables, the age keys are also read from $XDG_CONFIG_HOME/sops/age/keys.txt.
+Key files listed in decryption section of the current context manifest are used as well.
+`
+
+	decryptExample = `
+Decrypt the secrets and print the result
+# airshipctl secret decrypt secrets.yaml
+
+Decrypt the secrets in place
+# SOPS_AGE_KEY_FILE=~/keys.txt airshipctl secret decrypt -i secrets.yaml
+`
+)
+
+// NewDecryptCommand creates a command which decrypts documents
+func NewDecryptCommand(cfgFactory config.Factory) *cobra.Command {
+	o := &secret.DecryptCommand{}
+	cmd := &cobra.Command{
+		Use:     "decrypt FILE...",
+		Short:   "Airshipctl command to decrypt documents encrypted with sops",
+		Long:    decryptLong[1:],
+		Example: decryptExample,
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Files = args
+			o.Writer = cmd.OutOrStdout()
+			keyFiles, err := secret.KeyFilesFromConfig(cfgFactory)
+			if err != nil {
+				return err
+			}
+			o.KeyFiles = keyFiles
+			return o.RunE()
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVar(&o.IgnoreMAC, "ignore-mac", false,
+		"do not verify the message authentication code of the documents")
+	flags.BoolVarP(&o.InPlace, "in-place", "i", false,
+		"write the result back to the files instead of stdout")
+
+	return cmd
+}
@@ -0,0 +1,82 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package secret
+
+import (
+	"github.com/spf13/cobra"
+
+	"opendev.org/airship/airshipctl/pkg/config"
+	"opendev.org/airship/airshipctl/pkg/secret"
+)
+
+const (
+	encryptLong = `
+Encrypt the documents of the given files with sops. A new data key is generated
+for every document and encrypted for each of the age recipients and PGP fingerprints.
+Documents which are already encrypted are left as is.
+
+By default only the values under data and stringData keys are encrypted,
+use --encrypted-regex or --unencrypted-regex to change that.
+`
+
+	encryptExample = `
+Encrypt the secrets for an age recipient and print the result
+# airshipctl secret encrypt --age age1e2atvetucwd2jq3pyz5tlremefc9n35tj2epgqpy6s4267fpnaeq25dp3j secrets.yaml
+
+Encrypt the secrets in place for a PGP key
+# airshipctl secret encrypt -i --pgp FBC7B9E2A4F9289AC0C1D4843D16CEE4A27381B4 secrets.yaml
+`
+)
+
+// NewEncryptCommand creates a command which encrypts documents
+func NewEncryptCommand(cfgFactory config.Factory) *cobra.Command {
+	o := &secret.EncryptCommand{}
+	cmd := &cobra.Command{
+		Use:     "encrypt FILE...",
+		Short:   "Airshipctl command to encrypt documents with sops",
+		Long:    encryptLong[1:],
+		Example: encryptExample,
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// the default encrypted regex doesn't make sense together with an unencrypted one
+			if cmd.Flags().Changed("unencrypted-regex") && !cmd.Flags().Changed("encrypted-regex") {
+				o.Options.EncryptedRegex = ""
+			}
+			o.Files = args
+			o.Writer = cmd.OutOrStdout()
+			keyFiles, err := secret.KeyFilesFromConfig(cfgFactory)
+			if err != nil {
+				return err
+			}
+			o.KeyFiles = keyFiles
+			return o.RunE()
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringSliceVar(&o.Options.AgeRecipients, "age", nil,
+		"age recipients to encrypt the documents for")
+	flags.StringSliceVar(&o.Options.PGPFingerprints, "pgp", nil,
+		"fingerprints of PGP keys to encrypt the documents for, the keys are taken from "+
+			"SOPS_IMPORT_PGP or SOPS_PGP_KEY_FILE env variables")
+	flags.StringVar(&o.Options.EncryptedRegex, "encrypted-regex", secret.DefaultEncryptedRegex,
+		"encrypt only the values of the keys matching the regular expression")
+	flags.StringVar(&o.Options.UnencryptedRegex, "unencrypted-regex", "",
+		"encrypt all the values except the ones of the keys matching the regular expression")
+	flags.BoolVarP(&o.InPlace, "in-place", "i", false,
+		"write the result back to the files instead of stdout")
+
+	return cmd
+}
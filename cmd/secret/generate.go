@@ -0,0 +1,75 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package secret
+
+import (
+	"github.com/spf13/cobra"
+
+	"opendev.org/airship/airshipctl/pkg/config"
+	"opendev.org/airship/airshipctl/pkg/secret"
+)
+
+const (
+	generateLong = `
+Generate the secrets listed in SecretGeneration document of the given file.
+Only the secrets which are missing, were removed from the output, are about to expire
+or whose definitions changed are generated, the rest are kept as is. Certificates are regenerated together with their CA.
+
+The result is written to the output file defined in the document as VariableCatalogue
+encrypted with sops for the recipients from the document. Keys needed to decrypt
+the previously generated secrets are taken from the same env variables as for secret decrypt command.
+`
+
+	generateExample = `
+Generate missing and expiring secrets of the site
+# airshipctl secret generate manifests/site/test-site/secrets/generation.yaml
+
+Regenerate the CA and all the certificates signed by it
+# airshipctl secret generate --force kubernetes-ca manifests/site/test-site/secrets/generation.yaml
+
+Show which secrets would be regenerated without modifying the output
+# airshipctl secret generate --dry-run manifests/site/test-site/secrets/generation.yaml
+`
+)
+
+// NewGenerateCommand creates a command which generates secrets from SecretGeneration document
+func NewGenerateCommand(cfgFactory config.Factory) *cobra.Command {
+	o := &secret.GenerateCommand{}
+	cmd := &cobra.Command{
+		Use:     "generate FILE",
+		Short:   "Airshipctl command to generate secrets",
+		Long:    generateLong[1:],
+		Example: generateExample,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.SpecFile = args[0]
+			o.Writer = cmd.OutOrStdout()
+			keyFiles, err := secret.KeyFilesFromConfig(cfgFactory)
+			if err != nil {
+				return err
+			}
+			o.KeyFiles = keyFiles
+			return o.RunE()
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringSliceVar(&o.Force, "force", nil,
+		"names of the secrets to regenerate even if they aren't expiring, use 'all' to regenerate all the secrets")
+	flags.BoolVar(&o.DryRun, "dry-run", false,
+		"only print which secrets would be generated")
+
+	return cmd
+}
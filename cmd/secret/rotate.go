@@ -0,0 +1,77 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package secret
+
+import (
+	"github.com/spf13/cobra"
+
+	"opendev.org/airship/airshipctl/pkg/config"
+	"opendev.org/airship/airshipctl/pkg/secret"
+)
+
+const (
+	rotateLong = `
+Re-encrypt the documents of the given files encrypted with sops using new data keys.
+Recipients can be added or removed at the same time, the documents must be
+decryptable with the keys available in the environment or airship config.
+`
+
+	rotateExample = `
+Rotate the data keys of the secrets in place
+# airshipctl secret rotate -i secrets.yaml
+
+Replace an age recipient of the secrets
+# airshipctl secret rotate -i --rm-age age1e2atvetucwd2jq3pyz5tlremefc9n35tj2epgqpy6s4267fpnaeq25dp3j \
+  --add-age age1lggyhqrw2nlhcxprm67z43rta597azn8gknawjehu9d9dl0jq3yqqvfafg secrets.yaml
+`
+)
+
+// NewRotateCommand creates a command which rotates data keys of encrypted documents
+func NewRotateCommand(cfgFactory config.Factory) *cobra.Command {
+	o := &secret.RotateCommand{}
+	cmd := &cobra.Command{
+		Use:     "rotate FILE...",
+		Short:   "Airshipctl command to rotate data keys of documents encrypted with sops",
+		Long:    rotateLong[1:],
+		Example: rotateExample,
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Files = args
+			o.Writer = cmd.OutOrStdout()
+			keyFiles, err := secret.KeyFilesFromConfig(cfgFactory)
+			if err != nil {
+				return err
+			}
+			o.KeyFiles = keyFiles
+			return o.RunE()
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringSliceVar(&o.Options.AddAge, "add-age", nil,
+		"age recipients to add")
+	flags.StringSliceVar(&o.Options.RemoveAge, "rm-age", nil,
+		"age recipients to remove")
+	flags.StringSliceVar(&o.Options.AddPGP, "add-pgp", nil,
+		"fingerprints of PGP keys to add")
+	flags.StringSliceVar(&o.Options.RemovePGP, "rm-pgp", nil,
+		"fingerprints of PGP keys to remove")
+	flags.BoolVar(&o.Options.IgnoreMAC, "ignore-mac", false,
+		"do not verify the message authentication code of the documents")
+	flags.BoolVarP(&o.InPlace, "in-place", "i", false,
+		"write the result back to the files instead of stdout")
+
+	return cmd
+}
@@ -0,0 +1,43 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package secret
+
+import (
+	"github.com/spf13/cobra"
+
+	"opendev.org/airship/airshipctl/pkg/config"
+)
+
+const (
+	secretLong = `
+Commands and sub-commnads defined can be used to manage secrets.
+`
+)
+
+// NewSecretCommand creates a command for interacting with secrets
+func NewSecretCommand(cfgFactory config.Factory) *cobra.Command {
+	secretRootCmd := &cobra.Command{
+		Use:   "secret",
+		Short: "Airshipctl command to manage secrets",
+		Long:  secretLong[1:],
+	}
+
+	secretRootCmd.AddCommand(NewEncryptCommand(cfgFactory))
+	secretRootCmd.AddCommand(NewDecryptCommand(cfgFactory))
+	secretRootCmd.AddCommand(NewGenerateCommand(cfgFactory))
+	secretRootCmd.AddCommand(NewRotateCommand(cfgFactory))
+
+	return secretRootCmd
+}
@@ -16,19 +16,7 @@ import (
 )
 
 func main() {
-	fn := func(rl *framework.ResourceList) error {
-		cfg, err := rl.FunctionConfig.Map()
-		if err != nil {
-			return err
-		}
-		plugin, err := templater.New(cfg)
-		if err != nil {
-			return err
-		}
-		rl.Items, err = plugin.Filter(rl.Items)
-		return err
-	}
-	cmd := command.Build(framework.ResourceListProcessorFunc(fn), command.StandaloneEnabled, false)
+	cmd := command.Build(framework.ResourceListProcessorFunc(templater.Process), command.StandaloneEnabled, false)
 	if err := cmd.Execute(); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
@@ -27,6 +27,10 @@ const (
 	GenericContainerTypeAirship GenericContainerType = "airship"
 	// GenericContainerTypeKrm specifies that kustomize krm function will be used
 	GenericContainerTypeKrm GenericContainerType = "krm"
+	// GenericContainerTypeExec specifies that local binary will be run as krm function
+	GenericContainerTypeExec GenericContainerType = "exec"
+	// GenericContainerTypeStarlark specifies that starlark script will be run as krm function
+	GenericContainerTypeStarlark GenericContainerType = "starlark"
 	// KubeConfigEnvKey uses as a key for kubeconfig env variable
 	KubeConfigEnvKey = "KUBECONFIG"
 	// KubeConfigPath is a path for mounted kubeconfig inside container
@@ -61,14 +65,16 @@ type GenericContainer struct {
 	ConfigRef *v1.ObjectReference `json:"configRef,omitempty"`
 }
 
-// GenericContainerType specify type of the container, there are currently two types:
+// GenericContainerType specify type of the container, there are currently four types:
 // airship - airship will run the container
 // krm - kustomize krm function will run the container
+// exec - local binary will be run as kustomize krm function
+// starlark - starlark script will be run as kustomize krm function
 type GenericContainerType string
 
 // GenericContainerSpec container configuration
 type GenericContainerSpec struct {
-	// Supported types are "airship" and "krm"
+	// Supported types are "airship", "krm", "exec" and "starlark"
 	Type GenericContainerType `json:"type,omitempty"`
 
 	// Airship container spec
@@ -77,6 +83,12 @@ type GenericContainerSpec struct {
 	// KRM container function spec
 	KRM KRMContainerSpec `json:"krm,omitempty"`
 
+	// Exec function spec, used if type is "exec"
+	Exec ExecFunctionSpec `json:"exec,omitempty"`
+
+	// Starlark function spec, used if type is "starlark"
+	Starlark StarlarkFunctionSpec `json:"starlark,omitempty"`
+
 	// Executor will write output using kustomize sink if this parameter is specified.
 	// Else it will write output to STDOUT.
 	// This path relative to current site root.
@@ -90,15 +102,23 @@ type GenericContainerSpec struct {
 
 	// EnvVars is a slice of env string that will be exposed to container
 	// ["MY_VAR=my-value, "MY_VAR1=my-value1"]
-	// if passed in format ["MY_ENV"] this env variable will be exported the container
+	// if passed in format ["MY_ENV"] this env variable will be exported the container,
+	// starlark functions don't support it
 	EnvVars []string `json:"envVars,omitempty"`
 
 	// Mounts are the storage or directories to mount into the container
 	StorageMounts []StorageMount `json:"mounts,omitempty" yaml:"mounts,omitempty"`
 
 	// Timeout is the maximum amount of time (in seconds) for container execution
-	// if not specified (0) no timeout will be set and container could run indefinitely
+	// if not specified (0) no timeout will be set and container could run indefinitely,
+	// applies to krm, exec and airship containers, starlark functions don't support it
 	Timeout uint64 `json:"timeout,omitempty"`
+
+	// Cache enables caching of the container output keyed by hash of the image, spec, config
+	// and input, it must be enabled only for containers producing the same output for the same
+	// input, output written to SinkOutputDir is never cached, as well as the output of the
+	// containers with mounts and of starlark scripts fetched from URL
+	Cache bool `json:"cache,omitempty"`
 }
 
 // AirshipContainerSpec airship container settings
@@ -112,12 +132,43 @@ type AirshipContainerSpec struct {
 
 	// Privileged identifies if the container is to be run in a Privileged mode
 	Privileged bool `json:"privileged,omitempty"`
+
+	// KeepFailedContainer prevents removal of the container if it exits with non-zero code
+	// or exceeds the timeout, so it can be inspected for debugging purposes
+	KeepFailedContainer bool `json:"keepFailedContainer,omitempty"`
+
+	// StderrTailLines is the number of last lines of the container stderr that are added
+	// to the returned error if the container fails, if not specified (0) 20 lines are used
+	StderrTailLines int `json:"stderrTailLines,omitempty"`
 }
 
 // KRMContainerSpec defines a spec for running a function as a container
 // empty for now since it has no extra fields from AirshipContainerSpec
 type KRMContainerSpec struct{}
 
+// ExecFunctionSpec defines a spec for running a local binary as krm function
+type ExecFunctionSpec struct {
+	// Path to the binary, if provided path is relative and contains path separator
+	// it will be expanded the same way as Src of the StorageMount
+	Path string `json:"path,omitempty"`
+
+	// Args to pass to the binary
+	Args []string `json:"args,omitempty"`
+}
+
+// StarlarkFunctionSpec defines a spec for running a starlark script as krm function
+type StarlarkFunctionSpec struct {
+	// Name of the function, used in the logs and error messages
+	Name string `json:"name,omitempty"`
+
+	// Path to the script, if provided path is relative it will be expanded
+	// the same way as Src of the StorageMount
+	Path string `json:"path,omitempty"`
+
+	// URL to fetch the script from, ignored if Path is specified
+	URL string `json:"url,omitempty"`
+}
+
 // StorageMount represents a container's mounted storage option(s)
 // copy from https://github.com/kubernetes-sigs/kustomize to avoid imports in this package
 type StorageMount struct {
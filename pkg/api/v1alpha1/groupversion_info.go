@@ -49,11 +49,14 @@ func init() {
 		&IsoConfiguration{},
 		&ClusterMap{},
 		&ReplacementTransformer{},
+		&MergeTransformer{},
 		&Templater{},
 		&BootConfiguration{},
 		&GenericContainer{},
 		&BaremetalManager{},
 		&ManifestMetadata{},
+		&SecretGeneration{},
+		&ValidationPolicy{},
 	)
 	_ = AddToScheme(Scheme) //nolint:errcheck
 }
@@ -0,0 +1,56 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Merge defines the base document and the override documents deep-merged onto it
+type Merge struct {
+	// Target selects exactly one base document by Group, Version, Kind, Name and Namespace
+	Target Target `json:"target" yaml:"target"`
+	// Sources select the override documents, the kind of the target is used if the kind
+	// of a source is empty. Overrides are merged in order, so the values of the later
+	// overrides win
+	Sources []Selector `json:"sources" yaml:"sources"`
+	// ListMergeKeys make the listed fields merged element by element, the lists of
+	// the other fields are replaced by the lists of the overrides
+	ListMergeKeys []ListMergeKey `json:"listMergeKeys,omitempty" yaml:"listMergeKeys,omitempty"`
+	// RemoveSources removes the override documents once they are merged
+	RemoveSources bool `json:"removeSources,omitempty" yaml:"removeSources,omitempty"`
+}
+
+// ListMergeKey identifies the elements of the list field, the elements of the override
+// with the same key are merged into the elements of the base and the rest are appended
+type ListMergeKey struct {
+	// Path is dot separated path to the list field from the document root, * matches
+	// any map key and the list elements are not part of the path,
+	// e.g. spec.commonHostNetworking.networks.routes
+	Path string `json:"path" yaml:"path"`
+	// Key is the field of the list elements identifying them, e.g. id
+	Key string `json:"key" yaml:"key"`
+}
+
+// +kubebuilder:object:root=true
+
+// MergeTransformer plugin configuration for airship document model
+type MergeTransformer struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Merges list of base documents and the overrides merged onto them
+	Merges []Merge `json:"merges,omitempty" yaml:"merges,omitempty"`
+}
@@ -59,6 +59,10 @@ type ValidationConfig struct {
 
 	// CRDList defines list of kustomize entrypoints located in "TARGET_PATH" where to find additional CRD
 	CRDList []string `json:"crdList,omitempty"`
+
+	// Policies are the names of ValidationPolicy documents from the phase config bundle
+	// the rendered documents are checked against
+	Policies []string `json:"policies,omitempty"`
 }
 
 // DefaultPhase can be used to safely unmarshal phase object without nil pointers
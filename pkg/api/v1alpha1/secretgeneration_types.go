@@ -0,0 +1,98 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Secret types supported by SecretGeneration
+const (
+	SecretTypeCA         SecretType = "ca"
+	SecretTypeCert       SecretType = "cert"
+	SecretTypePassword   SecretType = "password"
+	SecretTypeSSHKeyPair SecretType = "sshKeyPair"
+)
+
+// +kubebuilder:object:root=true
+
+// SecretGeneration lists the secrets needed by the site. It is used by airshipctl secret generate
+// to create the missing secrets and to regenerate the ones which are about to expire
+type SecretGeneration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SecretGenerationSpec `json:"spec"`
+}
+
+// SecretGenerationSpec defines the secrets and the place where generated values are stored
+type SecretGenerationSpec struct {
+	// Output defines the file the generated secrets are written to
+	Output SecretGenerationOutput `json:"output"`
+	// RenewBefore defines how long before the expiration the secret is regenerated, defaults to 720h
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+	// Secrets is the list of secrets to generate. Certificates must be listed after their CAs
+	Secrets []SecretDefinition `json:"secrets"`
+}
+
+// SecretGenerationOutput defines the VariableCatalogue document holding generated secrets
+type SecretGenerationOutput struct {
+	// Path to the file relative to the directory of the SecretGeneration document
+	Path string `json:"path"`
+	// Name of the VariableCatalogue document
+	Name string `json:"name"`
+	// Encryption defines the keys the document is encrypted for
+	Encryption SecretEncryption `json:"encryption"`
+}
+
+// SecretEncryption defines the recipients of the encrypted document
+type SecretEncryption struct {
+	// Age is the list of age recipients
+	Age []string `json:"age,omitempty"`
+	// PGP is the list of PGP key fingerprints
+	PGP []string `json:"pgp,omitempty"`
+}
+
+// SecretType defines the kind of the generated secret
+type SecretType string
+
+// SecretDefinition describes a single secret
+type SecretDefinition struct {
+	// Name of the secret, used as a key in the generated document
+	Name string `json:"name"`
+	// Type is one of ca, cert, password or sshKeyPair
+	Type SecretType `json:"type"`
+	// ValidityDays sets the validity of certificates. For passwords and ssh keys
+	// it defines the rotation period, zero means that they never expire
+	ValidityDays int `json:"validityDays,omitempty"`
+	// Subject of the certificate
+	Subject *CertificateSubject `json:"subject,omitempty"`
+	// CA is the name of the ca secret used to sign the certificate
+	CA string `json:"ca,omitempty"`
+	// IPAddresses of the certificate
+	IPAddresses []string `json:"ipAddresses,omitempty"`
+	// DNSNames of the certificate
+	DNSNames []string `json:"dnsNames,omitempty"`
+	// KeyBits is the size of RSA key, defaults to 2048 for certificates and 4096 for ssh keys
+	KeyBits int `json:"keyBits,omitempty"`
+	// Length of the password, defaults to 16
+	Length int `json:"length,omitempty"`
+}
+
+// CertificateSubject defines the subject of generated certificate
+type CertificateSubject struct {
+	CommonName   string   `json:"commonName"`
+	Organization []string `json:"organization,omitempty"`
+}
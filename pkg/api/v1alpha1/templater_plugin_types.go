@@ -33,7 +33,25 @@ type Templater struct {
 	// Values contains map with object parameters to render
 	Values *v1.JSON `json:"values,omitempty"`
 
+	// ValuesFrom lists the documents of templater input which provide values to render,
+	// the values are merged in order and inline Values are merged on top of them
+	ValuesFrom []TemplaterValuesSource `json:"valuesFrom,omitempty"`
+
 	// Template field is used to specify actual go-template which is going
 	// to be used to render the object defined in Spec field
 	Template string `json:"template,omitempty"`
+
+	// Strict disables the template functions which access the environment or return results
+	// that can't be reproduced, random values, keys and certificates are generated from the
+	// secret seed set by TEMPLATER_SEED env variable
+	Strict bool `json:"strict,omitempty"`
+}
+
+// TemplaterValuesSource refers to a document of templater input whose field is used as values
+type TemplaterValuesSource struct {
+	// ObjRef selects exactly one document by Group, Version, Kind, Name and Namespace
+	ObjRef Target `json:"objref"`
+	// FieldRef is JSON path to the field of the document holding the map of values,
+	// the whole document is used if it is empty
+	FieldRef string `json:"fieldref,omitempty"`
 }
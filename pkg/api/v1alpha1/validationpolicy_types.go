@@ -0,0 +1,64 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PolicyLanguage is the language validation policy rules are written in
+type PolicyLanguage string
+
+// PolicySeverity defines how violations of validation policy are treated
+type PolicySeverity string
+
+const (
+	// PolicyLanguageCEL is Common Expression Language, see https://github.com/google/cel-spec
+	PolicyLanguageCEL PolicyLanguage = "cel"
+
+	// PolicySeverityError violations fail the validation
+	PolicySeverityError PolicySeverity = "error"
+	// PolicySeverityWarning violations are reported but don't fail the validation
+	PolicySeverityWarning PolicySeverity = "warning"
+	// PolicySeverityInfo violations are reported for information only
+	PolicySeverityInfo PolicySeverity = "info"
+)
+
+// ValidationPolicySpec defines the rule of validation policy and the documents it applies to
+type ValidationPolicySpec struct {
+	// Language of the rule, cel if empty
+	// +kubebuilder:validation:Enum=cel
+	Language PolicyLanguage `json:"language,omitempty"`
+	// Rule is an expression evaluated against every matching document available as object,
+	// the document violates the policy if the rule evaluates to false
+	Rule string `json:"rule"`
+	// Message describes the violation, the unsatisfied rule is reported if it's empty
+	Message string `json:"message,omitempty"`
+	// Severity of the violations, error if empty
+	Severity PolicySeverity `json:"severity,omitempty"`
+	// Match selects the documents the policy applies to, all the documents if empty
+	Match []Selector `json:"match,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ValidationPolicy is a rule the rendered documents of phases are validated against,
+// phases select the policies by name through ValidationConfig
+type ValidationPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ValidationPolicySpec `json:"spec,omitempty"`
+}
@@ -21,6 +21,7 @@ package v1alpha1
 import (
 	"k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -324,6 +325,26 @@ func (in CAPIImageSpec) DeepCopy() CAPIImageSpec {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateSubject) DeepCopyInto(out *CertificateSubject) {
+	*out = *in
+	if in.Organization != nil {
+		in, out := &in.Organization, &out.Organization
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateSubject.
+func (in *CertificateSubject) DeepCopy() *CertificateSubject {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateSubject)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ChartProperties) DeepCopyInto(out *ChartProperties) {
 	*out = *in
@@ -574,6 +595,26 @@ func (in *EphemeralCluster) DeepCopy() *EphemeralCluster {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecFunctionSpec) DeepCopyInto(out *ExecFunctionSpec) {
+	*out = *in
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecFunctionSpec.
+func (in *ExecFunctionSpec) DeepCopy() *ExecFunctionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecFunctionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FileProperties) DeepCopyInto(out *FileProperties) {
 	*out = *in
@@ -656,6 +697,8 @@ func (in *GenericContainerSpec) DeepCopyInto(out *GenericContainerSpec) {
 	*out = *in
 	in.Airship.DeepCopyInto(&out.Airship)
 	out.KRM = in.KRM
+	in.Exec.DeepCopyInto(&out.Exec)
+	out.Starlark = in.Starlark
 	if in.EnvVars != nil {
 		in, out := &in.EnvVars, &out.EnvVars
 		*out = make([]string, len(*in))
@@ -1199,6 +1242,21 @@ func (in *Link) DeepCopy() *Link {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListMergeKey) DeepCopyInto(out *ListMergeKey) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListMergeKey.
+func (in *ListMergeKey) DeepCopy() *ListMergeKey {
+	if in == nil {
+		return nil
+	}
+	out := new(ListMergeKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ManifestMetadata) DeepCopyInto(out *ManifestMetadata) {
 	*out = *in
@@ -1226,6 +1284,64 @@ func (in *ManifestMetadata) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Merge) DeepCopyInto(out *Merge) {
+	*out = *in
+	out.Target = in.Target
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]Selector, len(*in))
+		copy(*out, *in)
+	}
+	if in.ListMergeKeys != nil {
+		in, out := &in.ListMergeKeys, &out.ListMergeKeys
+		*out = make([]ListMergeKey, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Merge.
+func (in *Merge) DeepCopy() *Merge {
+	if in == nil {
+		return nil
+	}
+	out := new(Merge)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MergeTransformer) DeepCopyInto(out *MergeTransformer) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Merges != nil {
+		in, out := &in.Merges, &out.Merges
+		*out = make([]Merge, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MergeTransformer.
+func (in *MergeTransformer) DeepCopy() *MergeTransformer {
+	if in == nil {
+		return nil
+	}
+	out := new(MergeTransformer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MergeTransformer) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MoveOptions) DeepCopyInto(out *MoveOptions) {
 	*out = *in
@@ -1696,6 +1812,131 @@ func (in *Route) DeepCopy() *Route {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretDefinition) DeepCopyInto(out *SecretDefinition) {
+	*out = *in
+	if in.Subject != nil {
+		in, out := &in.Subject, &out.Subject
+		*out = new(CertificateSubject)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IPAddresses != nil {
+		in, out := &in.IPAddresses, &out.IPAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DNSNames != nil {
+		in, out := &in.DNSNames, &out.DNSNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretDefinition.
+func (in *SecretDefinition) DeepCopy() *SecretDefinition {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretDefinition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretEncryption) DeepCopyInto(out *SecretEncryption) {
+	*out = *in
+	if in.Age != nil {
+		in, out := &in.Age, &out.Age
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PGP != nil {
+		in, out := &in.PGP, &out.PGP
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretEncryption.
+func (in *SecretEncryption) DeepCopy() *SecretEncryption {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretEncryption)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretGeneration) DeepCopyInto(out *SecretGeneration) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretGeneration.
+func (in *SecretGeneration) DeepCopy() *SecretGeneration {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretGeneration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SecretGeneration) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretGenerationOutput) DeepCopyInto(out *SecretGenerationOutput) {
+	*out = *in
+	in.Encryption.DeepCopyInto(&out.Encryption)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretGenerationOutput.
+func (in *SecretGenerationOutput) DeepCopy() *SecretGenerationOutput {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretGenerationOutput)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretGenerationSpec) DeepCopyInto(out *SecretGenerationSpec) {
+	*out = *in
+	in.Output.DeepCopyInto(&out.Output)
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = make([]SecretDefinition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretGenerationSpec.
+func (in *SecretGenerationSpec) DeepCopy() *SecretGenerationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretGenerationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Selector) DeepCopyInto(out *Selector) {
 	*out = *in
@@ -1727,6 +1968,21 @@ func (in *Service) DeepCopy() *Service {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarlarkFunctionSpec) DeepCopyInto(out *StarlarkFunctionSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarlarkFunctionSpec.
+func (in *StarlarkFunctionSpec) DeepCopy() *StarlarkFunctionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StarlarkFunctionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageMount) DeepCopyInto(out *StorageMount) {
 	*out = *in
@@ -1768,6 +2024,11 @@ func (in *Templater) DeepCopyInto(out *Templater) {
 		*out = new(apiextensionsv1.JSON)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ValuesFrom != nil {
+		in, out := &in.ValuesFrom, &out.ValuesFrom
+		*out = make([]TemplaterValuesSource, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Templater.
@@ -1788,6 +2049,22 @@ func (in *Templater) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplaterValuesSource) DeepCopyInto(out *TemplaterValuesSource) {
+	*out = *in
+	out.ObjRef = in.ObjRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplaterValuesSource.
+func (in *TemplaterValuesSource) DeepCopy() *TemplaterValuesSource {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplaterValuesSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ValidationConfig) DeepCopyInto(out *ValidationConfig) {
 	*out = *in
@@ -1811,6 +2088,11 @@ func (in *ValidationConfig) DeepCopyInto(out *ValidationConfig) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValidationConfig.
@@ -1823,6 +2105,52 @@ func (in *ValidationConfig) DeepCopy() *ValidationConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValidationPolicy) DeepCopyInto(out *ValidationPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValidationPolicy.
+func (in *ValidationPolicy) DeepCopy() *ValidationPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ValidationPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ValidationPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValidationPolicySpec) DeepCopyInto(out *ValidationPolicySpec) {
+	*out = *in
+	if in.Match != nil {
+		in, out := &in.Match, &out.Match
+		*out = make([]Selector, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValidationPolicySpec.
+func (in *ValidationPolicySpec) DeepCopy() *ValidationPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ValidationPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VersionsCatalogue) DeepCopyInto(out *VersionsCatalogue) {
 	*out = *in
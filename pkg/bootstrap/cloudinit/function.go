@@ -0,0 +1,129 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"errors"
+	"path/filepath"
+
+	"sigs.k8s.io/kustomize/api/provider"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"opendev.org/airship/airshipctl/pkg/api/v1alpha1"
+	"opendev.org/airship/airshipctl/pkg/document"
+	"opendev.org/airship/airshipctl/pkg/util"
+)
+
+const (
+	builderConfigFileName = "builder-conf.yaml"
+	userDataFileName      = "user-data"
+	networkConfigFileName = "network-data"
+)
+
+var _ framework.ResourceListProcessor = Function{}
+
+// Function is the KRM function which renders cloud-init user and network data
+// together with builder configuration into the directory of the first storage mount
+type Function struct {
+	// OnHost makes function write the data into the source directory of the storage mount
+	// instead of the destination one, it's used when function is executed in-process
+	OnHost bool
+}
+
+// Process implements framework.ResourceListProcessor interface
+func (f Function) Process(rl *framework.ResourceList) error {
+	functionConfigDocument, err := docFromRNode(rl.FunctionConfig)
+	if err != nil {
+		return err
+	}
+	functionConfigYaml, err := functionConfigDocument.AsYAML()
+	if err != nil {
+		return err
+	}
+
+	isoConfiguration := &v1alpha1.IsoConfiguration{}
+	err = functionConfigDocument.ToAPIObject(isoConfiguration, v1alpha1.Scheme)
+	if err != nil {
+		return err
+	}
+
+	docBundle, err := bundleFromRNodes(rl.Items)
+	if err != nil {
+		return err
+	}
+
+	userData, netConf, err := GetCloudData(
+		docBundle,
+		isoConfiguration.Isogen.UserDataSelector,
+		isoConfiguration.Isogen.UserDataKey,
+		isoConfiguration.Isogen.NetworkConfigSelector,
+		isoConfiguration.Isogen.NetworkConfigKey,
+	)
+	if err != nil {
+		return err
+	}
+
+	functionSpec := runtimeutil.GetFunctionSpec(rl.FunctionConfig)
+	if functionSpec == nil || len(functionSpec.Container.StorageMounts) == 0 {
+		return errors.New("cloud-init function requires storage mount to write the data to")
+	}
+	configPath := functionSpec.Container.StorageMounts[0].DstPath
+	if f.OnHost {
+		configPath = functionSpec.Container.StorageMounts[0].Src
+	}
+
+	fls := make(map[string][]byte)
+	fls[filepath.Join(configPath, userDataFileName)] = userData
+	fls[filepath.Join(configPath, networkConfigFileName)] = netConf
+	fls[filepath.Join(configPath, builderConfigFileName)] = functionConfigYaml
+
+	if err = util.WriteFiles(fls, 0600); err != nil {
+		return err
+	}
+
+	rl.Items = []*yaml.RNode{}
+	return nil
+}
+
+func bundleFromRNodes(rnodes []*yaml.RNode) (document.Bundle, error) {
+	p := provider.NewDefaultDepProvider()
+	resmapFactory := resmap.NewFactory(p.GetResourceFactory())
+	resmap, err := resmapFactory.NewResMapFromRNodeSlice(rnodes)
+	if err != nil {
+		return &document.BundleFactory{}, err
+	}
+	return &document.BundleFactory{
+		ResMap: resmap,
+	}, nil
+}
+
+func docFromRNode(rnode *yaml.RNode) (document.Document, error) {
+	rnodes := []*yaml.RNode{rnode}
+	bundle, err := bundleFromRNodes(rnodes)
+	if err != nil {
+		return nil, err
+	}
+	collection, err := bundle.GetAllDocuments()
+	if err != nil {
+		return nil, err
+	}
+	if len(collection) == 0 {
+		return nil, errors.New("error while converting RNode to Document: empty document bundle")
+	}
+	return collection[0], nil
+}
@@ -0,0 +1,96 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"opendev.org/airship/airshipctl/testutil"
+)
+
+const functionConfigTemplate = `apiVersion: airshipit.org/v1alpha1
+kind: IsoConfiguration
+metadata:
+  name: isogen
+  annotations:
+    config.kubernetes.io/function: |
+      container:
+        image: localhost/cloud-init
+        mounts:%s
+builder:
+  userDataKey: userData
+  networkConfigKey: networkData
+`
+
+func TestFunctionProcess(t *testing.T) {
+	tempDir, cleanup := testutil.TempDir(t, "cloud-init-function")
+	defer cleanup(t)
+
+	tests := []struct {
+		name        string
+		mounts      string
+		expectedErr string
+	}{
+		{
+			name:   "data written to the mount source",
+			mounts: fmt.Sprintf("\n        - type: bind\n          src: %s\n          dst: /config", tempDir),
+		},
+		{
+			name:        "no storage mounts",
+			mounts:      " []",
+			expectedErr: "requires storage mount",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			fnConfig, err := yaml.Parse(fmt.Sprintf(functionConfigTemplate, tt.mounts))
+			require.NoError(t, err)
+
+			rl := &framework.ResourceList{FunctionConfig: fnConfig}
+			for _, doc := range getValidDocSet() {
+				item, parseErr := yaml.Parse(doc)
+				require.NoError(t, parseErr)
+				rl.Items = append(rl.Items, item)
+			}
+
+			err = Function{OnHost: true}.Process(rl)
+			if tt.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Empty(t, rl.Items)
+
+			userData, err := ioutil.ReadFile(filepath.Join(tempDir, userDataFileName))
+			require.NoError(t, err)
+			assert.Equal(t, "cloud-init", string(userData))
+			_, err = ioutil.ReadFile(filepath.Join(tempDir, networkConfigFileName))
+			assert.NoError(t, err)
+			_, err = ioutil.ReadFile(filepath.Join(tempDir, builderConfigFileName))
+			assert.NoError(t, err)
+		})
+	}
+}
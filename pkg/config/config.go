@@ -345,6 +345,19 @@ func (c *Config) CurrentContextTargetPath() (string, error) {
 	return ccm.GetTargetPath(), nil
 }
 
+// CurrentContextDecryption returns decryption keys configuration from current context's manifest,
+// the configuration is empty if the manifest doesn't define it
+func (c *Config) CurrentContextDecryption() (*Decryption, error) {
+	ccm, err := c.CurrentContextManifest()
+	if err != nil {
+		return nil, err
+	}
+	if ccm.Decryption == nil {
+		return &Decryption{}, nil
+	}
+	return ccm.Decryption, nil
+}
+
 // CurrentContextPhaseRepositoryDir returns phase repository directory from current context's manifest
 // E.g. let repository url be "http://dummy.org/phaserepo.git" then repo directory under targetPath is "phaserepo"
 func (c *Config) CurrentContextPhaseRepositoryDir() (string, error) {
@@ -528,6 +541,153 @@ func (c *Config) WorkDir() (dir string, err error) {
 	return dir, err
 }
 
+// DeleteContext removes the context and makes newCurrent the current context if it's not empty.
+// The current context is removed only if newCurrent names another context
+func (c *Config) DeleteContext(name, newCurrent string) error {
+	if _, err := c.GetContext(name); err != nil {
+		return err
+	}
+	if newCurrent == name {
+		return ErrInvalidConfig{What: fmt.Sprintf("can't switch to the deleted context '%s'", name)}
+	}
+	if newCurrent == "" && c.CurrentContext == name {
+		return ErrDeleteCurrentContext{Name: name}
+	}
+	if newCurrent != "" {
+		if _, err := c.GetContext(newCurrent); err != nil {
+			return err
+		}
+		c.CurrentContext = newCurrent
+	}
+	delete(c.Contexts, name)
+	return nil
+}
+
+// RenameContext renames the context and updates the current context if it's the renamed one
+func (c *Config) RenameContext(name, newName string) error {
+	context, err := c.GetContext(name)
+	if err != nil {
+		return err
+	}
+	if newName == "" {
+		return ErrEmptyContextName{}
+	}
+	if _, exists := c.Contexts[newName]; exists {
+		return ErrConfigExists{What: fmt.Sprintf("context with name '%s'", newName)}
+	}
+	delete(c.Contexts, name)
+	c.Contexts[newName] = context
+	if c.CurrentContext == name {
+		c.CurrentContext = newName
+	}
+	return nil
+}
+
+// DeleteManifest removes the manifest. The manifest referenced by contexts is removed only if force
+// is set, the references are cleared in that case. The manifest of the current context is never removed
+func (c *Config) DeleteManifest(name string, force bool) error {
+	if _, err := c.GetManifest(name); err != nil {
+		return err
+	}
+	what := fmt.Sprintf("manifest '%s'", name)
+	refs := c.contextsReferencing(func(context *Context) bool { return context.Manifest == name })
+	if err := c.ensureNotReferenced(what, refs, force); err != nil {
+		return err
+	}
+	for _, ctxName := range refs {
+		c.Contexts[ctxName].Manifest = ""
+	}
+	delete(c.Manifests, name)
+	return nil
+}
+
+// RenameManifest renames the manifest and updates the contexts referencing it
+func (c *Config) RenameManifest(name, newName string) error {
+	manifest, err := c.GetManifest(name)
+	if err != nil {
+		return err
+	}
+	if newName == "" {
+		return ErrMissingManifestName{}
+	}
+	if _, exists := c.Manifests[newName]; exists {
+		return ErrConfigExists{What: fmt.Sprintf("manifest with name '%s'", newName)}
+	}
+	for _, ctxName := range c.contextsReferencing(func(context *Context) bool { return context.Manifest == name }) {
+		c.Contexts[ctxName].Manifest = newName
+	}
+	delete(c.Manifests, name)
+	c.Manifests[newName] = manifest
+	return nil
+}
+
+// DeleteManagementConfig removes the management configuration. The management configuration referenced
+// by contexts is removed only if force is set, the references are cleared in that case. The management
+// configuration of the current context is never removed
+func (c *Config) DeleteManagementConfig(name string, force bool) error {
+	if _, err := c.GetManagementConfiguration(name); err != nil {
+		return err
+	}
+	what := fmt.Sprintf("management config '%s'", name)
+	refs := c.contextsReferencing(func(context *Context) bool { return context.ManagementConfiguration == name })
+	if err := c.ensureNotReferenced(what, refs, force); err != nil {
+		return err
+	}
+	for _, ctxName := range refs {
+		c.Contexts[ctxName].ManagementConfiguration = ""
+	}
+	delete(c.ManagementConfiguration, name)
+	return nil
+}
+
+// RenameManagementConfig renames the management configuration and updates the contexts referencing it
+func (c *Config) RenameManagementConfig(name, newName string) error {
+	mgmtCfg, err := c.GetManagementConfiguration(name)
+	if err != nil {
+		return err
+	}
+	if newName == "" {
+		return ErrEmptyManagementConfigurationName{}
+	}
+	if _, exists := c.ManagementConfiguration[newName]; exists {
+		return ErrConfigExists{What: fmt.Sprintf("management config with name '%s'", newName)}
+	}
+	for _, ctxName := range c.contextsReferencing(func(context *Context) bool {
+		return context.ManagementConfiguration == name
+	}) {
+		c.Contexts[ctxName].ManagementConfiguration = newName
+	}
+	delete(c.ManagementConfiguration, name)
+	c.ManagementConfiguration[newName] = mgmtCfg
+	return nil
+}
+
+// ensureNotReferenced checks that the deleted config object can be removed from the contexts referencing it,
+// the reference of the current context is never removed since it would leave the config incomplete
+func (c *Config) ensureNotReferenced(what string, refs []string, force bool) error {
+	for _, ctxName := range refs {
+		if ctxName == c.CurrentContext {
+			return ErrReferencedByCurrentContext{What: what, Context: ctxName}
+		}
+	}
+	if len(refs) > 0 && !force {
+		return ErrConfigReferenced{What: what, Contexts: refs}
+	}
+	return nil
+}
+
+// contextsReferencing returns the sorted names of the contexts matching the reference check
+func (c *Config) contextsReferencing(references func(*Context) bool) []string {
+	var names []string
+	for name, context := range c.Contexts {
+		if context != nil && references(context) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 // AddManagementConfig creates a new instance of ManagementConfig object
 func (c *Config) AddManagementConfig(mgmtCfgName string, opts ...ManagementConfigOption) *ManagementConfiguration {
 	// Create the new Airshipctl config ManagementConfig
@@ -180,6 +180,48 @@ func RunUseContext(desiredContext string, airconfig *Config) error {
 	return nil
 }
 
+// RunDeleteContext deletes the context, switches to newCurrent context if it's set and persists the config
+func RunDeleteContext(name, newCurrent string, airconfig *Config) error {
+	return updateConfig(airconfig, func() error { return airconfig.DeleteContext(name, newCurrent) })
+}
+
+// RunRenameContext renames the context and persists the config
+func RunRenameContext(name, newName string, airconfig *Config) error {
+	return updateConfig(airconfig, func() error { return airconfig.RenameContext(name, newName) })
+}
+
+// RunDeleteManifest deletes the manifest and persists the config
+func RunDeleteManifest(name string, force bool, airconfig *Config) error {
+	return updateConfig(airconfig, func() error { return airconfig.DeleteManifest(name, force) })
+}
+
+// RunRenameManifest renames the manifest and persists the config
+func RunRenameManifest(name, newName string, airconfig *Config) error {
+	return updateConfig(airconfig, func() error { return airconfig.RenameManifest(name, newName) })
+}
+
+// RunDeleteManagementConfig deletes the management config and persists the config
+func RunDeleteManagementConfig(name string, force bool, airconfig *Config) error {
+	return updateConfig(airconfig, func() error { return airconfig.DeleteManagementConfig(name, force) })
+}
+
+// RunRenameManagementConfig renames the management config and persists the config
+func RunRenameManagementConfig(name, newName string, airconfig *Config) error {
+	return updateConfig(airconfig, func() error { return airconfig.RenameManagementConfig(name, newName) })
+}
+
+// updateConfig applies the update to the config and persists it. The updates check everything
+// before changing the config and keep it complete, so the config is verified before the update
+func updateConfig(airconfig *Config, update func() error) error {
+	if err := airconfig.EnsureComplete(); err != nil {
+		return err
+	}
+	if err := update(); err != nil {
+		return err
+	}
+	return airconfig.PersistConfig(true)
+}
+
 // RunSetManifest validates the given command line options and invokes AddManifest/ModifyManifest
 func RunSetManifest(o *ManifestOptions, airconfig *Config, writeToStorage bool) (bool, error) {
 	modified := false
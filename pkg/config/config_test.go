@@ -316,6 +316,25 @@ func TestCurrentTargetPath(t *testing.T) {
 	assert.Equal(t, conf.Manifests[defaultString].TargetPath, targetPath)
 }
 
+func TestCurrentContextDecryption(t *testing.T) {
+	conf, cleanup := testutil.InitConfig(t)
+	defer cleanup(t)
+
+	conf.Manifests[defaultString] = testutil.DummyManifest()
+
+	conf.CurrentContext = currentContextName
+	conf.Contexts[currentContextName].Manifest = defaultString
+
+	decryption, err := conf.CurrentContextDecryption()
+	require.NoError(t, err)
+	assert.Equal(t, &config.Decryption{}, decryption)
+
+	conf.Manifests[defaultString].Decryption = &config.Decryption{AgeKeyFiles: []string{"/keys/age.txt"}}
+	decryption, err = conf.CurrentContextDecryption()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/keys/age.txt"}, decryption.AgeKeyFiles)
+}
+
 func TestCurrentPhaseRepositoryDir(t *testing.T) {
 	conf, cleanup := testutil.InitConfig(t)
 	defer cleanup(t)
@@ -464,3 +483,136 @@ func TestModifyManagementConfig(t *testing.T) {
 		managementConfig.SystemActionRetries)
 	assert.EqualValues(t, conf.ManagementConfiguration["modified_mgmt_config"], managementConfig)
 }
+
+func TestDeleteContext(t *testing.T) {
+	conf := testutil.DummyConfig()
+	conf.Contexts["other_context"] = testutil.DummyContext()
+
+	conf.Contexts["another_context"] = testutil.DummyContext()
+
+	require.NoError(t, conf.DeleteContext("other_context", ""))
+	assert.NotContains(t, conf.Contexts, "other_context")
+
+	assert.Equal(t, config.ErrDeleteCurrentContext{Name: "dummy_context"}, conf.DeleteContext("dummy_context", ""))
+	assert.Equal(t, config.ErrInvalidConfig{What: "can't switch to the deleted context 'dummy_context'"},
+		conf.DeleteContext("dummy_context", "dummy_context"))
+	assert.Equal(t, config.ErrMissingConfig{What: "context with name 'unknown'"},
+		conf.DeleteContext("dummy_context", "unknown"))
+	assert.Contains(t, conf.Contexts, "dummy_context")
+	assert.Equal(t, "dummy_context", conf.CurrentContext)
+
+	require.NoError(t, conf.DeleteContext("dummy_context", "another_context"))
+	assert.NotContains(t, conf.Contexts, "dummy_context")
+	assert.Equal(t, "another_context", conf.CurrentContext)
+
+	assert.Error(t, conf.DeleteContext("unknown", ""))
+}
+
+func TestRenameContext(t *testing.T) {
+	conf := testutil.DummyConfig()
+	conf.Contexts["other_context"] = testutil.DummyContext()
+
+	require.NoError(t, conf.RenameContext("dummy_context", "renamed_context"))
+	assert.NotContains(t, conf.Contexts, "dummy_context")
+	assert.Contains(t, conf.Contexts, "renamed_context")
+	assert.Equal(t, "renamed_context", conf.CurrentContext)
+
+	assert.Equal(t, config.ErrConfigExists{What: "context with name 'other_context'"},
+		conf.RenameContext("renamed_context", "other_context"))
+	assert.Equal(t, config.ErrEmptyContextName{}, conf.RenameContext("renamed_context", ""))
+	assert.Error(t, conf.RenameContext("unknown", "new_context"))
+}
+
+func TestDeleteManifest(t *testing.T) {
+	conf := testutil.DummyConfig()
+	conf.Contexts["other_context"] = testutil.DummyContext()
+	conf.Manifests["unused_manifest"] = testutil.DummyManifest()
+
+	require.NoError(t, conf.DeleteManifest("unused_manifest", false))
+	assert.NotContains(t, conf.Manifests, "unused_manifest")
+
+	assert.Equal(t, config.ErrConfigReferenced{
+		What:     "manifest 'dummy_manifest'",
+		Contexts: []string{"dummy_context", "other_context"},
+	}, conf.DeleteManifest("dummy_manifest", false))
+	assert.Contains(t, conf.Manifests, "dummy_manifest")
+
+	assert.Equal(t, config.ErrReferencedByCurrentContext{
+		What:    "manifest 'dummy_manifest'",
+		Context: "dummy_context",
+	}, conf.DeleteManifest("dummy_manifest", true))
+	assert.Contains(t, conf.Manifests, "dummy_manifest")
+	assert.Equal(t, "dummy_manifest", conf.Contexts["other_context"].Manifest)
+
+	conf.Contexts["dummy_context"].Manifest = "current_manifest"
+	conf.Manifests["current_manifest"] = testutil.DummyManifest()
+	require.NoError(t, conf.DeleteManifest("dummy_manifest", true))
+	assert.NotContains(t, conf.Manifests, "dummy_manifest")
+	assert.Equal(t, "current_manifest", conf.Contexts["dummy_context"].Manifest)
+	assert.Empty(t, conf.Contexts["other_context"].Manifest)
+
+	assert.Error(t, conf.DeleteManifest("unknown", true))
+}
+
+func TestRenameManifest(t *testing.T) {
+	conf := testutil.DummyConfig()
+	conf.Contexts["other_context"] = testutil.DummyContext()
+	conf.Contexts["other_context"].Manifest = "other_manifest"
+	conf.Manifests["other_manifest"] = testutil.DummyManifest()
+
+	require.NoError(t, conf.RenameManifest("dummy_manifest", "renamed_manifest"))
+	assert.NotContains(t, conf.Manifests, "dummy_manifest")
+	assert.Contains(t, conf.Manifests, "renamed_manifest")
+	assert.Equal(t, "renamed_manifest", conf.Contexts["dummy_context"].Manifest)
+	assert.Equal(t, "other_manifest", conf.Contexts["other_context"].Manifest)
+
+	assert.Equal(t, config.ErrConfigExists{What: "manifest with name 'other_manifest'"},
+		conf.RenameManifest("renamed_manifest", "other_manifest"))
+	assert.Equal(t, config.ErrMissingManifestName{}, conf.RenameManifest("renamed_manifest", ""))
+	assert.Error(t, conf.RenameManifest("unknown", "new_manifest"))
+}
+
+func TestDeleteManagementConfig(t *testing.T) {
+	conf := testutil.DummyConfig()
+	conf.Contexts["other_context"] = testutil.DummyContext()
+	conf.ManagementConfiguration["unused_mgmt_config"] = testutil.DummyManagementConfiguration()
+
+	require.NoError(t, conf.DeleteManagementConfig("unused_mgmt_config", false))
+	assert.NotContains(t, conf.ManagementConfiguration, "unused_mgmt_config")
+
+	assert.Equal(t, config.ErrConfigReferenced{
+		What:     "management config 'dummy_management_config'",
+		Contexts: []string{"dummy_context", "other_context"},
+	}, conf.DeleteManagementConfig("dummy_management_config", false))
+
+	assert.Equal(t, config.ErrReferencedByCurrentContext{
+		What:    "management config 'dummy_management_config'",
+		Context: "dummy_context",
+	}, conf.DeleteManagementConfig("dummy_management_config", true))
+	assert.Equal(t, "dummy_management_config", conf.Contexts["other_context"].ManagementConfiguration)
+
+	conf.Contexts["dummy_context"].ManagementConfiguration = "current_mgmt_config"
+	conf.ManagementConfiguration["current_mgmt_config"] = testutil.DummyManagementConfiguration()
+	require.NoError(t, conf.DeleteManagementConfig("dummy_management_config", true))
+	assert.NotContains(t, conf.ManagementConfiguration, "dummy_management_config")
+	assert.Equal(t, "current_mgmt_config", conf.Contexts["dummy_context"].ManagementConfiguration)
+	assert.Empty(t, conf.Contexts["other_context"].ManagementConfiguration)
+
+	assert.Equal(t, config.ErrManagementConfigurationNotFound{Name: "unknown"},
+		conf.DeleteManagementConfig("unknown", false))
+}
+
+func TestRenameManagementConfig(t *testing.T) {
+	conf := testutil.DummyConfig()
+	conf.ManagementConfiguration["other_mgmt_config"] = testutil.DummyManagementConfiguration()
+
+	require.NoError(t, conf.RenameManagementConfig("dummy_management_config", "renamed_mgmt_config"))
+	assert.NotContains(t, conf.ManagementConfiguration, "dummy_management_config")
+	assert.Contains(t, conf.ManagementConfiguration, "renamed_mgmt_config")
+	assert.Equal(t, "renamed_mgmt_config", conf.Contexts["dummy_context"].ManagementConfiguration)
+
+	assert.Equal(t, config.ErrConfigExists{What: "management config with name 'other_mgmt_config'"},
+		conf.RenameManagementConfig("renamed_mgmt_config", "other_mgmt_config"))
+	assert.Equal(t, config.ErrEmptyManagementConfigurationName{},
+		conf.RenameManagementConfig("renamed_mgmt_config", ""))
+}
@@ -286,3 +286,44 @@ type ErrWrongOutputFormat struct {
 func (e ErrWrongOutputFormat) Error() string {
 	return fmt.Sprintf("wrong output format %s, must be one of %s", e.Wrong, strings.Join(e.Possible, " "))
 }
+
+// ErrConfigExists is returned when the new name of renamed config object is already taken
+type ErrConfigExists struct {
+	What string
+}
+
+func (e ErrConfigExists) Error() string {
+	return fmt.Sprintf("%s already exists", e.What)
+}
+
+// ErrConfigReferenced is returned when deleted config object is referenced by contexts
+type ErrConfigReferenced struct {
+	What     string
+	Contexts []string
+}
+
+func (e ErrConfigReferenced) Error() string {
+	return fmt.Sprintf("%s is referenced by context(s) %s, use --force to delete it anyway",
+		e.What, strings.Join(e.Contexts, ", "))
+}
+
+// ErrDeleteCurrentContext is returned when attempted to delete the current context
+type ErrDeleteCurrentContext struct {
+	Name string
+}
+
+func (e ErrDeleteCurrentContext) Error() string {
+	return fmt.Sprintf("context '%s' is the current context, switch to another context using use-context first "+
+		"or specify the context to switch to with --use-context", e.Name)
+}
+
+// ErrReferencedByCurrentContext is returned when deleted config object is referenced by the current context
+type ErrReferencedByCurrentContext struct {
+	What    string
+	Context string
+}
+
+func (e ErrReferencedByCurrentContext) Error() string {
+	return fmt.Sprintf("%s is referenced by the current context '%s' and can't be deleted, "+
+		"switch to another context using use-context first", e.What, e.Context)
+}
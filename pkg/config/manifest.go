@@ -34,6 +34,20 @@ type Manifest struct {
 	TargetPath string `json:"targetPath"`
 	// MetadataPath path to a metadata file relative to TargetPath
 	MetadataPath string `json:"metadataPath"`
+	// Decryption holds the keys used to decrypt the documents encrypted with sops
+	Decryption *Decryption `json:"decryption,omitempty"`
+}
+
+// Decryption holds the locations of the keys used to decrypt the documents encrypted with sops,
+// the keys are used in addition to the ones given by SOPS_* env variables
+type Decryption struct {
+	// AgeKeyFiles are the files with age identities, one per line
+	AgeKeyFiles []string `json:"ageKeyFiles,omitempty"`
+	// PGPKeyFiles are the files with ASCII armored PGP private keys
+	PGPKeyFiles []string `json:"pgpKeyFiles,omitempty"`
+	// IgnoreMAC skips the integrity check of the encrypted documents, it is needed
+	// if the encrypted files were edited after encryption
+	IgnoreMAC bool `json:"ignoreMAC,omitempty"`
 }
 
 // Metadata holds entrypoints for phases, inventory and clusterctl
@@ -15,13 +15,20 @@
 package container
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	// TODO this small library needs to be moved to airshipctl and extended
 	// with splitting streams into Stderr and Stdout
@@ -33,8 +40,10 @@ import (
 
 	"opendev.org/airship/airshipctl/pkg/api/v1alpha1"
 	"opendev.org/airship/airshipctl/pkg/container/runfn"
+	"opendev.org/airship/airshipctl/pkg/document/plugin"
 	"opendev.org/airship/airshipctl/pkg/log"
 	"opendev.org/airship/airshipctl/pkg/util"
+	"opendev.org/airship/airshipctl/pkg/version"
 )
 
 // ClientV1Alpha1 provides airship generic container API
@@ -49,7 +58,18 @@ type ClientV1Alpha1FactoryFunc func(
 	input io.Reader,
 	output io.Writer,
 	conf *v1alpha1.GenericContainer,
-	targetPath string) ClientV1Alpha1
+	targetPath string,
+	opts ...ClientOption) ClientV1Alpha1
+
+// ClientOption allows to set optional parameters of ClientV1Alpha1
+type ClientOption func(*V1Alpha1)
+
+// WithCache enables caching of the container output if it's allowed by the container spec
+func WithCache(cache *Cache) ClientOption {
+	return func(c *V1Alpha1) {
+		c.cache = cache
+	}
+}
 
 // V1Alpha1 reflects inner struct of ClientV1Alpha1 Interface
 type V1Alpha1 struct {
@@ -60,8 +80,13 @@ type V1Alpha1 struct {
 	targetPath string
 
 	containerFunc Func
+	cache         *Cache
 }
 
+// DefaultStderrTailLines is the number of last lines of stderr added to the error
+// returned for the failed airship container if not specified in container spec
+const DefaultStderrTailLines = 20
+
 // Func is type of function which returns Container object
 type Func func(ctx context.Context, driver string, url string) (Container, error)
 
@@ -71,8 +96,9 @@ func NewClientV1Alpha1(
 	input io.Reader,
 	output io.Writer,
 	conf *v1alpha1.GenericContainer,
-	targetPath string) ClientV1Alpha1 {
-	return &V1Alpha1{
+	targetPath string,
+	opts ...ClientOption) ClientV1Alpha1 {
+	client := &V1Alpha1{
 		resultsDir:    resultsDir,
 		output:        output,
 		input:         input,
@@ -80,6 +106,10 @@ func NewClientV1Alpha1(
 		containerFunc: NewContainer,
 		targetPath:    targetPath,
 	}
+	for _, opt := range opts {
+		opt(client)
+	}
+	return client
 }
 
 // NewV1Alpha1 returns V1Alpha1 struct with desired parameters
@@ -88,8 +118,9 @@ func NewV1Alpha1(resultsDir string,
 	output io.Writer,
 	conf *v1alpha1.GenericContainer,
 	targetPath string,
-	containerFunc Func) V1Alpha1 {
-	return V1Alpha1{
+	containerFunc Func,
+	opts ...ClientOption) V1Alpha1 {
+	client := V1Alpha1{
 		resultsDir:    resultsDir,
 		input:         input,
 		output:        output,
@@ -97,23 +128,178 @@ func NewV1Alpha1(resultsDir string,
 		targetPath:    targetPath,
 		containerFunc: containerFunc,
 	}
+	for _, opt := range opts {
+		opt(&client)
+	}
+	return client
 }
 
 // Run will perform container run action based on the configuration
 func (c *V1Alpha1) Run() error {
 	// expand Src paths for mount if they are relative
 	ExpandSourceMounts(c.conf.Spec.StorageMounts, c.targetPath)
-	// set default runtime
+	if c.cache != nil && c.cacheable() {
+		return c.runCached()
+	}
+	return c.run()
+}
+
+// cacheable reports if the container output can be cached, output written to the sink directory
+// isn't cached, as well as the output of the containers with mounts and of the scripts fetched
+// from URL since the key can't cover their contents
+func (c *V1Alpha1) cacheable() bool {
+	switch {
+	case !c.conf.Spec.Cache:
+		return false
+	case c.resultsDir != "", len(c.conf.Spec.StorageMounts) != 0:
+		return false
+	case c.conf.Spec.Type == v1alpha1.GenericContainerTypeStarlark && c.conf.Spec.Starlark.URL != "":
+		return false
+	}
+	return true
+}
+
+func (c *V1Alpha1) run() error {
 	switch c.conf.Spec.Type {
 	case v1alpha1.GenericContainerTypeAirship, "":
 		return c.runAirship()
-	case v1alpha1.GenericContainerTypeKrm:
+	case v1alpha1.GenericContainerTypeKrm, v1alpha1.GenericContainerTypeExec, v1alpha1.GenericContainerTypeStarlark:
 		return c.runKRM()
 	default:
 		return fmt.Errorf("unknown generic container type %s", c.conf.Spec.Type)
 	}
 }
 
+// runCached returns the output from the cache if the container was already executed with the same
+// image, configuration and input, otherwise it runs the container and stores its output in the cache
+func (c *V1Alpha1) runCached() error {
+	var input []byte
+	if c.input != nil {
+		var err error
+		if input, err = ioutil.ReadAll(c.input); err != nil {
+			return err
+		}
+		c.input = bytes.NewReader(input)
+	}
+
+	key, err := c.cacheKey(input)
+	if err != nil {
+		return err
+	}
+
+	output := c.output
+	if output == nil {
+		output = os.Stdout
+	}
+
+	cached, found, err := c.cache.Get(key)
+	if err != nil {
+		return err
+	}
+	if found {
+		log.Debugf("Using cached output of the container with image '%s'", c.conf.Spec.Image)
+		_, err = output.Write(cached)
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	c.output = io.MultiWriter(output, buf)
+	if err = c.run(); err != nil {
+		return err
+	}
+	return c.cache.Put(key, buf.Bytes())
+}
+
+// cacheKey calculates cache key from the image, container spec, values of the exported
+// env variables, config and input
+func (c *V1Alpha1) cacheKey(input []byte) (string, error) {
+	spec, err := json.Marshal(c.conf.Spec)
+	if err != nil {
+		return "", err
+	}
+
+	exported := runtimeutil.NewContainerEnvFromStringSlice(c.conf.Spec.EnvVars).VarsToExport
+	env := make([]string, 0, len(exported))
+	for _, key := range exported {
+		env = append(env, key+"="+os.Getenv(key))
+	}
+
+	image, err := c.imageIdentity()
+	if err != nil {
+		return "", err
+	}
+
+	return c.cache.Key([]byte(image), spec, []byte(strings.Join(env, "\n")), []byte(c.conf.Config), input), nil
+}
+
+// imageIdentity returns the value identifying the code executed by the container, it's the digest
+// of the binary or the script for exec and starlark functions, the script set inline is part of the config
+func (c *V1Alpha1) imageIdentity() (string, error) {
+	switch c.conf.Spec.Type {
+	case v1alpha1.GenericContainerTypeExec:
+		path, err := exec.LookPath(c.execPath())
+		if err != nil {
+			return "", err
+		}
+		return fileDigest(path)
+	case v1alpha1.GenericContainerTypeStarlark:
+		return c.starlarkDigest()
+	}
+
+	// functions executed in-process depend on the airshipctl build
+	if _, found := plugin.Lookup(c.conf.Spec.Image); found && c.conf.Spec.Type == v1alpha1.GenericContainerTypeKrm {
+		info := version.Get()
+		return "airshipctl/" + info.GitVersion + "/" + info.GitCommit, nil
+	}
+
+	runtime := c.conf.Spec.Airship.ContainerRuntime
+	if runtime == "" {
+		runtime = DriverDocker
+	}
+	if c.containerFunc == nil {
+		c.containerFunc = NewContainer
+	}
+	cont, err := c.containerFunc(context.Background(), runtime, c.conf.Spec.Image)
+	if err != nil {
+		return "", err
+	}
+	return cont.GetImageID(c.conf.Spec.Image)
+}
+
+// execPath returns the path of exec function binary, binaries without path separator are looked up in PATH
+func (c *V1Alpha1) execPath() string {
+	path := c.conf.Spec.Exec.Path
+	if strings.ContainsRune(path, filepath.Separator) {
+		return expandPath(path, c.targetPath)
+	}
+	return path
+}
+
+// starlarkDigest returns the digest of the starlark script read from the path
+func (c *V1Alpha1) starlarkDigest() (string, error) {
+	if c.conf.Spec.Starlark.Path == "" {
+		return "", nil
+	}
+	return fileDigest(expandPath(c.conf.Spec.Starlark.Path, c.targetPath))
+}
+
+func fileDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return digest(f)
+}
+
+func digest(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 func (c *V1Alpha1) runAirship() error {
 	if c.conf.Spec.Airship.ContainerRuntime == "" {
 		c.conf.Spec.Airship.ContainerRuntime = DriverDocker
@@ -131,42 +317,19 @@ func (c *V1Alpha1) runAirship() error {
 	if err != nil {
 		return err
 	}
+
+	var failed bool
 	defer func(container Container) {
+		if failed && c.conf.Spec.Airship.KeepFailedContainer {
+			log.Printf("Keeping failed container with id '%s' for debugging", container.GetID())
+			return
+		}
 		if rmErr := container.RmContainer(); rmErr != nil {
 			log.Printf("Failed to remove container with id '%s', err is '%s'", container.GetID(), rmErr.Error())
 		}
 	}(cont)
 
-	// this will split the env vars into the ones to be exported and the ones that have values
-	contEnv := runtimeutil.NewContainerEnvFromStringSlice(c.conf.Spec.EnvVars)
-
-	envs := make([]string, 0)
-	for _, key := range contEnv.VarsToExport {
-		envs = append(envs, strings.Join([]string{key, os.Getenv(key)}, "="))
-	}
-
-	for key, value := range contEnv.EnvVars {
-		envs = append(envs, strings.Join([]string{key, value}, "="))
-	}
-
-	node, err := kyaml.Parse(c.conf.Config)
-	if err != nil {
-		return err
-	}
-
-	decoratedInput := bytes.NewBuffer([]byte{})
-	pipeline := &kio.Pipeline{
-		Inputs: []kio.Reader{&kio.ByteReader{Reader: c.input}},
-		Outputs: []kio.Writer{kio.ByteWriter{
-			Writer:                decoratedInput,
-			KeepReaderAnnotations: true,
-			WrappingKind:          kio.ResourceListKind,
-			WrappingAPIVersion:    kio.ResourceListAPIVersion,
-			FunctionConfig:        node,
-		}},
-	}
-
-	err = pipeline.Execute()
+	decoratedInput, err := c.decoratedInput()
 	if err != nil {
 		return err
 	}
@@ -178,7 +341,7 @@ func (c *V1Alpha1) runAirship() error {
 		Privileged:  c.conf.Spec.Airship.Privileged,
 		Cmd:         c.conf.Spec.Airship.Cmd,
 		Mounts:      convertDockerMount(c.conf.Spec.StorageMounts),
-		EnvVars:     envs,
+		EnvVars:     c.containerEnvs(),
 		Input:       decoratedInput,
 		HostNetwork: c.conf.Spec.HostNetwork,
 	})
@@ -196,10 +359,14 @@ func (c *V1Alpha1) runAirship() error {
 		cErr <- writeLogs(cont)
 	}()
 
-	err = cont.WaitUntilFinished()
+	finished, err := c.waitUntilFinished(cont)
 	if err != nil {
-		<-cErr
-		return err
+		failed = true
+		// logs are streamed until the container is finished
+		if finished {
+			<-cErr
+		}
+		return c.failureDetails(cont, err)
 	}
 
 	// check writeLogs error after container is done waiting
@@ -218,7 +385,109 @@ func (c *V1Alpha1) runAirship() error {
 	return writeSink(c.resultsDir, parsedOut, c.output)
 }
 
+// containerEnvs returns env vars of the container in key=value format, vars to be exported
+// get their values from the current environment
+func (c *V1Alpha1) containerEnvs() []string {
+	// this will split the env vars into the ones to be exported and the ones that have values
+	contEnv := runtimeutil.NewContainerEnvFromStringSlice(c.conf.Spec.EnvVars)
+
+	envs := make([]string, 0)
+	for _, key := range contEnv.VarsToExport {
+		envs = append(envs, strings.Join([]string{key, os.Getenv(key)}, "="))
+	}
+
+	for key, value := range contEnv.EnvVars {
+		envs = append(envs, strings.Join([]string{key, value}, "="))
+	}
+	return envs
+}
+
+// decoratedInput wraps the input documents into ResourceList with the function config
+func (c *V1Alpha1) decoratedInput() (*bytes.Buffer, error) {
+	node, err := kyaml.Parse(c.conf.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	decoratedInput := bytes.NewBuffer([]byte{})
+	pipeline := &kio.Pipeline{
+		Inputs: []kio.Reader{&kio.ByteReader{Reader: c.input}},
+		Outputs: []kio.Writer{kio.ByteWriter{
+			Writer:                decoratedInput,
+			KeepReaderAnnotations: true,
+			WrappingKind:          kio.ResourceListKind,
+			WrappingAPIVersion:    kio.ResourceListAPIVersion,
+			FunctionConfig:        node,
+		}},
+	}
+
+	if err = pipeline.Execute(); err != nil {
+		return nil, err
+	}
+	return decoratedInput, nil
+}
+
+// waitUntilFinished waits until container is finished, if timeout is specified
+// in container spec and container is still running after it expires, the container
+// is killed and ErrContainerTimeout is returned. False is returned if the container
+// couldn't be killed and may be still running
+func (c *V1Alpha1) waitUntilFinished(cont Container) (bool, error) {
+	if c.conf.Spec.Timeout == 0 {
+		return true, cont.WaitUntilFinished()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cont.WaitUntilFinished()
+	}()
+
+	select {
+	case err := <-done:
+		return true, err
+	case <-time.After(time.Duration(c.conf.Spec.Timeout) * time.Second):
+		err := ErrContainerTimeout{Timeout: c.conf.Spec.Timeout}
+		if killErr := cont.KillContainer(); killErr != nil {
+			log.Printf("Failed to kill timed out container with id '%s', err is '%s'", cont.GetID(), killErr.Error())
+			return false, err
+		}
+		// error of the killed container is expected and superseded by the timeout
+		<-done
+		return true, err
+	}
+}
+
+// failureDetails collects state and the last lines of stderr of the failed container,
+// since the container is removed afterwards unless KeepFailedContainer is set
+func (c *V1Alpha1) failureDetails(cont Container, err error) error {
+	result := ErrContainerFailed{
+		Image:       c.conf.Spec.Image,
+		ContainerID: cont.GetID(),
+		Kept:        c.conf.Spec.Airship.KeepFailedContainer,
+		Err:         err,
+	}
+
+	state, inspectErr := cont.InspectContainer()
+	if inspectErr != nil {
+		log.Debugf("Failed to inspect container with id '%s', err is '%s'", cont.GetID(), inspectErr.Error())
+	}
+	result.State = state
+
+	lines := c.conf.Spec.Airship.StderrTailLines
+	if lines <= 0 {
+		lines = DefaultStderrTailLines
+	}
+	stderr, logsErr := tailLogs(cont, lines)
+	if logsErr != nil {
+		log.Debugf("Failed to get logs of container with id '%s', err is '%s'", cont.GetID(), logsErr.Error())
+	}
+	result.Stderr = stderr
+	return result
+}
+
 func (c *V1Alpha1) runKRM() error {
+	if err := c.checkStarlarkOptions(); err != nil {
+		return err
+	}
 	mounts := convertKRMMount(c.conf.Spec.StorageMounts)
 	fns := &runfn.RunFns{
 		Network:               c.conf.Spec.HostNetwork,
@@ -229,6 +498,9 @@ func (c *V1Alpha1) runKRM() error {
 		StorageMounts:         mounts,
 		ContinueOnEmptyResult: true,
 		Timeout:               c.conf.Spec.Timeout,
+		EnableExec:            c.conf.Spec.Type == v1alpha1.GenericContainerTypeExec,
+		EnableStarlark:        c.conf.Spec.Type == v1alpha1.GenericContainerTypeStarlark,
+		ExecArgs:              c.conf.Spec.Exec.Args,
 	}
 	function, err := kyaml.Parse(c.conf.Config)
 	if err != nil {
@@ -236,14 +508,7 @@ func (c *V1Alpha1) runKRM() error {
 	}
 	// Transform GenericContainer.Spec to annotation,
 	// because we need to specify runFns config in annotation
-	spec, err := yaml.Marshal(runtimeutil.FunctionSpec{
-		Container: runtimeutil.ContainerSpec{
-			Image:         c.conf.Spec.Image,
-			Network:       c.conf.Spec.HostNetwork,
-			Env:           c.conf.Spec.EnvVars,
-			StorageMounts: mounts,
-		},
-	})
+	spec, err := yaml.Marshal(c.functionSpec(mounts))
 	if err != nil {
 		return err
 	}
@@ -258,6 +523,65 @@ func (c *V1Alpha1) runKRM() error {
 	return fns.Execute()
 }
 
+// checkStarlarkOptions fails if starlark function sets the options which can't be applied to
+// the script run in-process: the script sees the environment of airshipctl and can't be interrupted
+func (c *V1Alpha1) checkStarlarkOptions() error {
+	if c.conf.Spec.Type != v1alpha1.GenericContainerTypeStarlark {
+		return nil
+	}
+	switch {
+	case c.conf.Spec.Timeout != 0:
+		return ErrUnsupportedOption{Type: string(c.conf.Spec.Type), Option: "timeout"}
+	case len(c.conf.Spec.EnvVars) != 0:
+		return ErrUnsupportedOption{Type: string(c.conf.Spec.Type), Option: "envVars"}
+	}
+	return nil
+}
+
+// functionSpec returns kustomize function spec depending on the type of the generic container,
+// env variables and storage mounts are set for all the types, starlark functions ignore them
+func (c *V1Alpha1) functionSpec(mounts []runtimeutil.StorageMount) runtimeutil.FunctionSpec {
+	spec := runtimeutil.FunctionSpec{
+		Container: runtimeutil.ContainerSpec{
+			Network:       c.conf.Spec.HostNetwork,
+			Env:           c.conf.Spec.EnvVars,
+			StorageMounts: mounts,
+		},
+	}
+	switch c.conf.Spec.Type {
+	case v1alpha1.GenericContainerTypeExec:
+		spec.Exec.Path = c.execPath()
+	case v1alpha1.GenericContainerTypeStarlark:
+		spec.Starlark.Name = c.conf.Spec.Starlark.Name
+		spec.Starlark.URL = c.conf.Spec.Starlark.URL
+		if c.conf.Spec.Starlark.Path != "" {
+			spec.Starlark.Path = expandPath(c.conf.Spec.Starlark.Path, c.targetPath)
+		}
+	default:
+		spec.Container.Image = c.conf.Spec.Image
+	}
+	return spec
+}
+
+// tailLogs returns the last n lines of the container stderr
+func tailLogs(cont Container, n int) ([]string, error) {
+	stderr, err := cont.GetContainerLogs(GetLogOptions{Stderr: true})
+	if err != nil {
+		return nil, err
+	}
+	defer stderr.Close()
+
+	lines := make([]string, 0, n)
+	scanner := bufio.NewScanner(dlog.NewReader(stderr))
+	for scanner.Scan() {
+		if len(lines) == n {
+			lines = lines[1:]
+		}
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
 func writeLogs(cont Container) error {
 	stderr, err := cont.GetContainerLogs(GetLogOptions{
 		Stderr: true,
@@ -319,12 +643,16 @@ func convertDockerMount(airMounts []v1alpha1.StorageMount) (mounts []Mount) {
 // ExpandSourceMounts converts relative paths into absolute ones
 func ExpandSourceMounts(storageMounts []v1alpha1.StorageMount, targetPath string) {
 	for i, mount := range storageMounts {
-		// Try to expand Src path
-		path := util.ExpandTilde(mount.Src)
-		// If still relative - add targetPath prefix
-		if !filepath.IsAbs(path) {
-			path = filepath.Join(targetPath, mount.Src)
-		}
-		storageMounts[i].Src = path
+		storageMounts[i].Src = expandPath(mount.Src, targetPath)
+	}
+}
+
+func expandPath(path, targetPath string) string {
+	// Try to expand path
+	expanded := util.ExpandTilde(path)
+	// If still relative - add targetPath prefix
+	if !filepath.IsAbs(expanded) {
+		expanded = filepath.Join(targetPath, path)
 	}
+	return expanded
 }
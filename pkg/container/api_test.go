@@ -17,9 +17,13 @@ package container_test
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"path/filepath"
 	"testing"
 
@@ -32,6 +36,7 @@ import (
 	aircontainer "opendev.org/airship/airshipctl/pkg/container"
 	"opendev.org/airship/airshipctl/pkg/phase/ifc"
 	"opendev.org/airship/airshipctl/pkg/util"
+	"opendev.org/airship/airshipctl/testutil"
 )
 
 const (
@@ -303,6 +308,356 @@ func TestGenericContainer(t *testing.T) {
 	}
 }
 
+func TestGenericContainerFunctions(t *testing.T) {
+	tests := []struct {
+		name           string
+		spec           v1alpha1.GenericContainerSpec
+		expectedErr    string
+		expectedOutput string
+	}{
+		{
+			name: "exec function",
+			spec: v1alpha1.GenericContainerSpec{
+				Type: v1alpha1.GenericContainerTypeExec,
+				Exec: v1alpha1.ExecFunctionSpec{
+					Path: "cat",
+				},
+			},
+			expectedOutput: "name: test-script",
+		},
+		{
+			name: "exec function error",
+			spec: v1alpha1.GenericContainerSpec{
+				Type: v1alpha1.GenericContainerTypeExec,
+				Exec: v1alpha1.ExecFunctionSpec{
+					Path: "./does-not-exist",
+				},
+			},
+			expectedErr: "no such file or directory",
+		},
+		{
+			name: "starlark function",
+			spec: v1alpha1.GenericContainerSpec{
+				Type: v1alpha1.GenericContainerTypeStarlark,
+				Starlark: v1alpha1.StarlarkFunctionSpec{
+					Name: "set-label",
+					Path: "set-label.star",
+				},
+			},
+			expectedOutput: "starlark: applied",
+		},
+		{
+			name: "starlark function with timeout",
+			spec: v1alpha1.GenericContainerSpec{
+				Type:    v1alpha1.GenericContainerTypeStarlark,
+				Timeout: 10,
+				Starlark: v1alpha1.StarlarkFunctionSpec{
+					Name: "set-label",
+					Path: "set-label.star",
+				},
+			},
+			expectedErr: "option timeout is not supported by starlark generic containers",
+		},
+		{
+			name: "starlark function with env",
+			spec: v1alpha1.GenericContainerSpec{
+				Type:    v1alpha1.GenericContainerTypeStarlark,
+				EnvVars: []string{"MY_VAR=my-value"},
+				Starlark: v1alpha1.StarlarkFunctionSpec{
+					Name: "set-label",
+					Path: "set-label.star",
+				},
+			},
+			expectedErr: "option envVars is not supported by starlark generic containers",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			output := bytes.NewBuffer(nil)
+			conf := &v1alpha1.GenericContainer{
+				Spec:   tt.spec,
+				Config: "kind: FunctionConfig\nlabel: starlark\nvalue: applied\n",
+			}
+			client := aircontainer.NewV1Alpha1("", testInput(t), output, conf, "testdata", nil)
+
+			err := client.Run()
+			if tt.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, output.String(), tt.expectedOutput)
+		})
+	}
+}
+
+func TestGenericContainerCache(t *testing.T) {
+	workDir, cleanup := testutil.TempDir(t, "airship-cache")
+	defer cleanup(t)
+	cache := aircontainer.NewCache(workDir)
+
+	conf := &v1alpha1.GenericContainer{
+		Spec: v1alpha1.GenericContainerSpec{
+			Type:  v1alpha1.GenericContainerTypeExec,
+			Cache: true,
+			Exec: v1alpha1.ExecFunctionSpec{
+				Path: "cat",
+			},
+		},
+	}
+	run := func() string {
+		output := bytes.NewBuffer(nil)
+		client := aircontainer.NewV1Alpha1("", testInput(t), output, conf, "", nil, aircontainer.WithCache(cache))
+		require.NoError(t, client.Run())
+		return output.String()
+	}
+
+	assert.Contains(t, run(), "name: test-script")
+	entries, err := filepath.Glob(filepath.Join(cache.Dir, "*.yaml"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	// second run with the same input must return the cached output without executing the function
+	require.NoError(t, ioutil.WriteFile(entries[0], []byte("cached output\n"), 0600))
+	assert.Equal(t, "cached output\n", run())
+
+	// output isn't cached if the container doesn't allow it
+	conf.Spec.Cache = false
+	assert.Contains(t, run(), "name: test-script")
+}
+
+func TestGenericContainerCacheScriptChanged(t *testing.T) {
+	const script = `def run(items):
+  for item in items:
+    item["metadata"].setdefault("labels", {})["starlark"] = "%s"
+
+run(ctx.resource_list["items"])
+`
+	scriptDir, cleanup := testutil.TempDir(t, "airship-script")
+	defer cleanup(t)
+	scriptPath := filepath.Join(scriptDir, "script.star")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, scriptPath)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		spec            v1alpha1.StarlarkFunctionSpec
+		expectedEntries int
+	}{
+		{
+			name:            "script path",
+			spec:            v1alpha1.StarlarkFunctionSpec{Name: "set-label", Path: scriptPath},
+			expectedEntries: 2,
+		},
+		{
+			// the script fetched from URL is never cached
+			name: "script url",
+			spec: v1alpha1.StarlarkFunctionSpec{Name: "set-label", URL: server.URL},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			workDir, cleanup := testutil.TempDir(t, "airship-cache")
+			defer cleanup(t)
+			cache := aircontainer.NewCache(workDir)
+			conf := &v1alpha1.GenericContainer{
+				Spec: v1alpha1.GenericContainerSpec{
+					Type:     v1alpha1.GenericContainerTypeStarlark,
+					Cache:    true,
+					Starlark: tt.spec,
+				},
+				Config: "kind: FunctionConfig\n",
+			}
+			run := func(version string) string {
+				require.NoError(t, ioutil.WriteFile(scriptPath, []byte(fmt.Sprintf(script, version)), 0600))
+				output := bytes.NewBuffer(nil)
+				client := aircontainer.NewV1Alpha1("", testInput(t), output, conf, "", nil, aircontainer.WithCache(cache))
+				require.NoError(t, client.Run())
+				return output.String()
+			}
+
+			assert.Contains(t, run("v1"), "starlark: v1")
+			// the output of the changed script must not be taken from the cache
+			assert.Contains(t, run("v2"), "starlark: v2")
+			entries, err := filepath.Glob(filepath.Join(cache.Dir, "*.yaml"))
+			require.NoError(t, err)
+			assert.Len(t, entries, tt.expectedEntries)
+		})
+	}
+}
+
+func TestGenericContainerCacheWithMounts(t *testing.T) {
+	workDir, cleanup := testutil.TempDir(t, "airship-cache")
+	defer cleanup(t)
+	cache := aircontainer.NewCache(workDir)
+
+	conf := &v1alpha1.GenericContainer{
+		Spec: v1alpha1.GenericContainerSpec{
+			Type:  v1alpha1.GenericContainerTypeExec,
+			Cache: true,
+			Exec: v1alpha1.ExecFunctionSpec{
+				Path: "cat",
+			},
+			StorageMounts: []v1alpha1.StorageMount{{MountType: "bind", Src: workDir, DstPath: "/workdir"}},
+		},
+	}
+	output := bytes.NewBuffer(nil)
+	client := aircontainer.NewV1Alpha1("", testInput(t), output, conf, "", nil, aircontainer.WithCache(cache))
+	require.NoError(t, client.Run())
+	assert.Contains(t, output.String(), "name: test-script")
+
+	// the contents of the mounts are not part of the key, so the output isn't cached
+	entries, err := filepath.Glob(filepath.Join(cache.Dir, "*.yaml"))
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+// stderrLogs returns docker multiplexed stream containing provided lines as stderr
+func stderrLogs(lines ...string) io.ReadCloser {
+	buf := bytes.NewBuffer([]byte{})
+	for _, line := range lines {
+		header := make([]byte, 8)
+		// stream type 2 is stderr
+		header[0] = 2
+		binary.BigEndian.PutUint32(header[4:], uint32(len(line)+1))
+		buf.Write(header)
+		buf.WriteString(line + "\n")
+	}
+	return ioutil.NopCloser(buf)
+}
+
+func TestAirshipContainerFailure(t *testing.T) {
+	failedState := func() (types.ContainerJSON, error) {
+		return types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{
+				State: &types.ContainerState{
+					Status:    "exited",
+					ExitCode:  137,
+					OOMKilled: true,
+				},
+			},
+		}, nil
+	}
+
+	tests := []struct {
+		name            string
+		timeout         uint64
+		keep            bool
+		tailLines       int
+		hang            bool
+		killErr         error
+		expectedTimeout bool
+		expectedStderr  []string
+		expectedErr     []string
+	}{
+		{
+			name:           "non-zero exit code",
+			tailLines:      2,
+			expectedStderr: []string{"line 2", "line 3"},
+			expectedErr: []string{
+				"container with image 'some image' failed",
+				"status: exited, exit code: 137, killed because of out of memory",
+				"last 2 lines of stderr:\n    line 2\n    line 3",
+			},
+		},
+		{
+			name:            "timeout exceeded and container kept",
+			timeout:         1,
+			keep:            true,
+			hang:            true,
+			expectedTimeout: true,
+			expectedStderr:  []string{"line 1", "line 2", "line 3"},
+			expectedErr: []string{
+				"container timed out after 1 seconds",
+				"container 'testID' is kept for debugging",
+			},
+		},
+		{
+			name:            "timeout exceeded and container can't be killed",
+			timeout:         1,
+			hang:            true,
+			killErr:         fmt.Errorf("kill error"),
+			expectedTimeout: true,
+			expectedStderr:  []string{"line 1", "line 2", "line 3"},
+			expectedErr:     []string{"container timed out after 1 seconds"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			conf := &v1alpha1.GenericContainer{
+				Spec: v1alpha1.GenericContainerSpec{
+					Type:    v1alpha1.GenericContainerTypeAirship,
+					Image:   "some image",
+					Timeout: tt.timeout,
+					Airship: v1alpha1.AirshipContainerSpec{
+						KeepFailedContainer: tt.keep,
+						StderrTailLines:     tt.tailLines,
+					},
+				},
+				Config: `kind: ConfigMap`,
+			}
+			// the container finishes by itself unless it hangs, then only killing finishes it
+			finished := make(chan container.ContainerWaitOKBody, 1)
+			var killed bool
+			execFunc := func(ctx context.Context, driver, url string) (aircontainer.Container, error) {
+				return getDockerContainerMock(mockDockerClient{
+					containerAttach: func() (types.HijackedResponse, error) {
+						return types.HijackedResponse{Conn: mockConn{WData: make([]byte, 8)}}, nil
+					},
+					imageList: func() ([]types.ImageSummary, error) {
+						return []types.ImageSummary{{ID: "imgid"}}, nil
+					},
+					imageInspectWithRaw: func() (types.ImageInspect, []byte, error) {
+						return types.ImageInspect{Config: &container.Config{}}, nil, nil
+					},
+					containerWait: func() (<-chan container.ContainerWaitOKBody, <-chan error) {
+						if !tt.hang {
+							finished <- container.ContainerWaitOKBody{StatusCode: 137}
+						}
+						return finished, nil
+					},
+					containerKill: func() error {
+						killed = true
+						if tt.killErr != nil {
+							return tt.killErr
+						}
+						finished <- container.ContainerWaitOKBody{StatusCode: 137}
+						return nil
+					},
+					containerInspect: failedState,
+					containerLogs: func() (io.ReadCloser, error) {
+						return stderrLogs("line 1", "line 2", "line 3"), nil
+					},
+				}), nil
+			}
+			client := aircontainer.NewV1Alpha1("", testInput(t), ioutil.Discard, conf, "", execFunc)
+
+			err := client.Run()
+			require.Error(t, err)
+			for _, expected := range tt.expectedErr {
+				assert.Contains(t, err.Error(), expected)
+			}
+
+			failedErr := aircontainer.ErrContainerFailed{}
+			require.True(t, errors.As(err, &failedErr))
+			assert.Equal(t, tt.keep, failedErr.Kept)
+			assert.Equal(t, tt.expectedStderr, failedErr.Stderr)
+			assert.Equal(t, 137, failedErr.State.ExitCode)
+			assert.Equal(t, tt.expectedTimeout, errors.As(err, &aircontainer.ErrContainerTimeout{}))
+			assert.Equal(t, tt.expectedTimeout, killed)
+		})
+	}
+}
+
 // Dummy test to keep up with coverage.
 func TestNewClientV1alpha1(t *testing.T) {
 	client := aircontainer.NewClientV1Alpha1("", nil, nil, v1alpha1.DefaultGenericContainer(), "")
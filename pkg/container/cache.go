@@ -0,0 +1,136 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package container
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"opendev.org/airship/airshipctl/pkg/log"
+)
+
+const (
+	// CacheDirName is the name of the directory in airshipctl work dir where container outputs are cached
+	CacheDirName = "cache"
+
+	cacheSubDir  = "containers"
+	cacheFileExt = ".yaml"
+)
+
+// Cache stores output of the containers keyed by hash of their image, configuration and input,
+// so the containers producing the same output for the same input aren't executed over and over
+type Cache struct {
+	Dir string
+}
+
+// NewCache returns container cache located in the work dir of airshipctl
+func NewCache(workDir string) *Cache {
+	return &Cache{Dir: filepath.Join(workDir, CacheDirName, cacheSubDir)}
+}
+
+// Key returns cache key calculated as a hash of provided parts
+func (c *Cache) Key(parts ...[]byte) string {
+	h := sha256.New()
+	for _, part := range parts {
+		// prefix each part with its length, so different splits of the same data give different keys
+		fmt.Fprintf(h, "%d:", len(part))
+		h.Write(part) //nolint:errcheck // hash.Hash never returns an error
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get returns cached output for the key, false is returned if there is no such entry
+func (c *Cache) Get(key string) ([]byte, bool, error) {
+	path := c.path(key)
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	// update modification time, so recently used entries survive prune by age
+	now := time.Now()
+	if err = os.Chtimes(path, now, now); err != nil {
+		log.Debugf("Failed to update modification time of cache entry '%s': %v", path, err)
+	}
+	return data, true, nil
+}
+
+// Put stores output for the key
+func (c *Cache) Put(key string, data []byte) error {
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return err
+	}
+
+	// write to temporary file first, so concurrent readers never see partial entry
+	tmp, err := ioutil.TempFile(c.Dir, key+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// temporary file doesn't exist anymore if it was renamed successfully
+		if rmErr := os.Remove(tmp.Name()); rmErr != nil && !os.IsNotExist(rmErr) {
+			log.Debugf("Failed to remove temporary cache file '%s': %v", tmp.Name(), rmErr)
+		}
+	}()
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+// Prune removes cache entries which weren't used longer than olderThan,
+// if olderThan is 0 all the entries are removed. Number of removed entries is returned
+func (c *Cache) Prune(olderThan time.Duration) (int, error) {
+	entries, err := ioutil.ReadDir(c.Dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), cacheFileExt) {
+			continue
+		}
+		if olderThan > 0 && time.Since(entry.ModTime()) < olderThan {
+			continue
+		}
+		if err = os.Remove(filepath.Join(c.Dir, entry.Name())); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.Dir, key+cacheFileExt)
+}
@@ -0,0 +1,100 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package container_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"opendev.org/airship/airshipctl/pkg/container"
+	"opendev.org/airship/airshipctl/testutil"
+)
+
+func TestCacheKey(t *testing.T) {
+	cache := container.NewCache("")
+	assert.Equal(t, cache.Key([]byte("a"), []byte("b")), cache.Key([]byte("a"), []byte("b")))
+	assert.NotEqual(t, cache.Key([]byte("a"), []byte("b")), cache.Key([]byte("b"), []byte("a")))
+	assert.NotEqual(t, cache.Key([]byte("ab"), []byte("")), cache.Key([]byte("a"), []byte("b")))
+}
+
+func TestCacheGetPut(t *testing.T) {
+	workDir, cleanup := testutil.TempDir(t, "airship-cache")
+	defer cleanup(t)
+
+	cache := container.NewCache(workDir)
+	assert.Equal(t, filepath.Join(workDir, container.CacheDirName, "containers"), cache.Dir)
+
+	key := cache.Key([]byte("input"))
+	_, found, err := cache.Get(key)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, cache.Put(key, []byte("output")))
+	data, found, err := cache.Get(key)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("output"), data)
+}
+
+func TestCachePrune(t *testing.T) {
+	tests := []struct {
+		name            string
+		olderThan       time.Duration
+		expectedRemoved int
+	}{
+		{
+			name:            "remove all entries",
+			expectedRemoved: 2,
+		},
+		{
+			name:            "remove stale entries",
+			olderThan:       time.Hour,
+			expectedRemoved: 1,
+		},
+		{
+			name:      "nothing to remove",
+			olderThan: 48 * time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			workDir, cleanup := testutil.TempDir(t, "airship-cache")
+			defer cleanup(t)
+
+			cache := container.NewCache(workDir)
+			require.NoError(t, cache.Put("fresh", []byte("fresh")))
+			require.NoError(t, cache.Put("stale", []byte("stale")))
+			staleTime := time.Now().Add(-24 * time.Hour)
+			require.NoError(t, os.Chtimes(filepath.Join(cache.Dir, "stale.yaml"), staleTime, staleTime))
+
+			removed, err := cache.Prune(tt.olderThan)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedRemoved, removed)
+		})
+	}
+}
+
+func TestCachePruneNoDir(t *testing.T) {
+	removed, err := container.NewCache("does-not-exist").Prune(0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, removed)
+}
@@ -0,0 +1,48 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package container
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"opendev.org/airship/airshipctl/pkg/config"
+)
+
+// CachePruneCommand holds options for cache prune command
+type CachePruneCommand struct {
+	Factory   config.Factory
+	OlderThan time.Duration
+	Writer    io.Writer
+}
+
+// RunE removes cached container outputs which weren't used longer than OlderThan
+func (c *CachePruneCommand) RunE() error {
+	cfg, err := c.Factory()
+	if err != nil {
+		return err
+	}
+	workDir, err := cfg.WorkDir()
+	if err != nil {
+		return err
+	}
+	removed, err := NewCache(workDir).Prune(c.OlderThan)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(c.Writer, "Removed %d cache entries\n", removed)
+	return err
+}
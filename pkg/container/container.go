@@ -33,6 +33,14 @@ type State struct {
 	ExitCode int
 	// Status: String representation of the container state.
 	Status Status
+	// OOMKilled: indicates that the container was killed because of out of memory condition
+	OOMKilled bool
+	// Error: error message reported by the container runtime
+	Error string
+	// StartedAt: time when the container was started
+	StartedAt string
+	// FinishedAt: time when the container finished
+	FinishedAt string
 }
 
 // Container interface abstraction for container.
@@ -44,8 +52,19 @@ type Container interface {
 	GetContainerLogs(GetLogOptions) (io.ReadCloser, error)
 	InspectContainer() (State, error)
 	WaitUntilFinished() error
+	KillContainer() error
 	RmContainer() error
 	GetID() string
+	GetImageID(url string) (string, error)
+}
+
+// ImageArchive interface abstraction for operations on container images which
+// are not bound to a particular container, e.g. exporting images to a tarball
+// and importing them back on a host without access to the image registry.
+type ImageArchive interface {
+	ImagePull(url string) error
+	ImageSave(out io.Writer, urls []string) error
+	ImageLoad(in io.Reader) error
 }
 
 // RunCommandOptions options for RunCommand
@@ -96,3 +115,21 @@ func NewContainer(ctx context.Context, driver string, url string) (Container, er
 		return nil, ErrContainerDrvNotSupported{Driver: driver}
 	}
 }
+
+// NewImageArchive returns instance of ImageArchive interface implemented by particular driver
+// Supported drivers:
+//   * docker
+func NewImageArchive(ctx context.Context, driver string) (ImageArchive, error) {
+	switch driver {
+	case "":
+		return nil, ErrNoContainerDriver{}
+	case DriverDocker:
+		cli, err := NewDockerClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return NewDockerImageArchive(ctx, cli), nil
+	default:
+		return nil, ErrContainerDrvNotSupported{Driver: driver}
+	}
+}
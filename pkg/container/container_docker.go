@@ -16,6 +16,7 @@ package container
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -84,6 +85,13 @@ type DockerClient interface {
 		string,
 		types.ContainerLogsOptions,
 	) (io.ReadCloser, error)
+	// ContainerKill terminates the container process but does not remove
+	// the container from the docker host.
+	ContainerKill(
+		context.Context,
+		string,
+		string,
+	) error
 	// ContainerRemove kills and removes a container from the docker host.
 	ContainerRemove(
 		context.Context,
@@ -95,6 +103,21 @@ type DockerClient interface {
 		ctx context.Context,
 		containerID string,
 	) (types.ContainerJSON, error)
+	// ImageSave retrieves one or more images from the docker host as an
+	// io.ReadCloser. It's up to the caller to store the images and close
+	// the stream.
+	ImageSave(
+		context.Context,
+		[]string,
+	) (io.ReadCloser, error)
+	// ImageLoad loads an image in the docker host from the client host.
+	// It's up to the caller to close the io.ReadCloser in the
+	// ImageLoadResponse returned by this function.
+	ImageLoad(
+		context.Context,
+		io.Reader,
+		bool,
+	) (types.ImageLoadResponse, error)
 }
 
 // DockerContainer docker container object wrapper
@@ -328,6 +351,11 @@ func (c *DockerContainer) GetContainerLogs(opts GetLogOptions) (io.ReadCloser, e
 	})
 }
 
+// KillContainer kills the container process, the container is kept on the docker host
+func (c *DockerContainer) KillContainer() error {
+	return c.DockerClient.ContainerKill(c.Ctx, c.ID, "KILL")
+}
+
 // RmContainer kills and removes a container from the docker host.
 func (c *DockerContainer) RmContainer() error {
 	return c.DockerClient.ContainerRemove(
@@ -348,8 +376,12 @@ func (c *DockerContainer) InspectContainer() (State, error) {
 	}
 
 	state := State{
-		ExitCode: json.ContainerJSONBase.State.ExitCode,
-		Status:   Status(json.ContainerJSONBase.State.Status),
+		ExitCode:   json.ContainerJSONBase.State.ExitCode,
+		Status:     Status(json.ContainerJSONBase.State.Status),
+		OOMKilled:  json.ContainerJSONBase.State.OOMKilled,
+		Error:      json.ContainerJSONBase.State.Error,
+		StartedAt:  json.ContainerJSONBase.State.StartedAt,
+		FinishedAt: json.ContainerJSONBase.State.FinishedAt,
 	}
 	return state, err
 }
@@ -371,3 +403,70 @@ func (c *DockerContainer) WaitUntilFinished() error {
 	}
 	return nil
 }
+
+// DockerImageArchive docker image archive object wrapper
+type DockerImageArchive struct {
+	DockerClient DockerClient
+	Ctx          context.Context
+}
+
+// NewDockerImageArchive returns instance of DockerImageArchive object wrapper.
+func NewDockerImageArchive(ctx context.Context, cli DockerClient) *DockerImageArchive {
+	return &DockerImageArchive{
+		DockerClient: cli,
+		Ctx:          ctx,
+	}
+}
+
+// ImagePull downloads image if it's not present on the docker host
+func (a *DockerImageArchive) ImagePull(url string) error {
+	cnt := &DockerContainer{
+		ImageURL:     url,
+		DockerClient: a.DockerClient,
+		Ctx:          a.Ctx,
+	}
+	return cnt.ImagePull()
+}
+
+// ImageSave writes images specified by urls to out as a single tarball
+// in the format produced by 'docker save'
+func (a *DockerImageArchive) ImageSave(out io.Writer, urls []string) error {
+	rc, err := a.DockerClient.ImageSave(a.Ctx, urls)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	_, err = io.Copy(out, rc)
+	return err
+}
+
+// ImageLoad imports images from the tarball produced by ImageSave
+func (a *DockerImageArchive) ImageLoad(in io.Reader) error {
+	resp, err := a.DockerClient.ImageLoad(a.Ctx, in, true)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// docker daemon reports load failures as part of the response stream
+	// instead of the error code, so the stream has to be inspected
+	dec := json.NewDecoder(resp.Body)
+	for {
+		msg := struct {
+			Stream string `json:"stream"`
+			Error  string `json:"error"`
+		}{}
+		if err = dec.Decode(&msg); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if msg.Error != "" {
+			return ErrImageLoad{Message: msg.Error}
+		}
+		if msg.Stream != "" {
+			log.Debug(strings.TrimSpace(msg.Stream))
+		}
+	}
+}
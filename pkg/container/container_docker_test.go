@@ -60,6 +60,9 @@ type mockDockerClient struct {
 	containerWait       func() (<-chan container.ContainerWaitOKBody, <-chan error)
 	containerLogs       func() (io.ReadCloser, error)
 	containerInspect    func() (types.ContainerJSON, error)
+	containerKill       func() error
+	imageSave           func() (io.ReadCloser, error)
+	imageLoad           func(io.Reader) (types.ImageLoadResponse, error)
 }
 
 func (mdc *mockDockerClient) ImageInspectWithRaw(context.Context, string) (types.ImageInspect, []byte, error) {
@@ -120,6 +123,13 @@ func (mdc *mockDockerClient) ContainerLogs(context.Context, string, types.Contai
 	return ioutil.NopCloser(strings.NewReader("")), nil
 }
 
+func (mdc *mockDockerClient) ContainerKill(context.Context, string, string) error {
+	if mdc.containerKill != nil {
+		return mdc.containerKill()
+	}
+	return nil
+}
+
 func (mdc *mockDockerClient) ContainerRemove(context.Context, string, types.ContainerRemoveOptions) error {
 	return nil
 }
@@ -131,6 +141,14 @@ func (mdc *mockDockerClient) ContainerInspect(context.Context, string) (types.Co
 	return types.ContainerJSON{}, nil
 }
 
+func (mdc *mockDockerClient) ImageSave(context.Context, []string) (io.ReadCloser, error) {
+	return mdc.imageSave()
+}
+
+func (mdc *mockDockerClient) ImageLoad(_ context.Context, in io.Reader, _ bool) (types.ImageLoadResponse, error) {
+	return mdc.imageLoad(in)
+}
+
 func getDockerContainerMock(mdc mockDockerClient) *aircontainer.DockerContainer {
 	ctx := context.Background()
 	cnt := &aircontainer.DockerContainer{
@@ -616,6 +634,31 @@ func TestRmContainer(t *testing.T) {
 	}
 }
 
+func TestKillContainer(t *testing.T) {
+	testError := fmt.Errorf("kill error")
+	tests := []struct {
+		mockDockerClient mockDockerClient
+		expectedErr      error
+	}{
+		{
+			mockDockerClient: mockDockerClient{},
+			expectedErr:      nil,
+		},
+		{
+			mockDockerClient: mockDockerClient{
+				containerKill: func() error { return testError },
+			},
+			expectedErr: testError,
+		},
+	}
+
+	for _, tt := range tests {
+		cnt := getDockerContainerMock(tt.mockDockerClient)
+		actualErr := cnt.KillContainer()
+		assert.Equal(t, tt.expectedErr, actualErr)
+	}
+}
+
 func TestInspectContainer(t *testing.T) {
 	tests := []struct {
 		cli           mockDockerClient
@@ -707,3 +750,81 @@ func TestInspectContainer(t *testing.T) {
 		assert.Equal(t, tt.expectedErr, actualErr)
 	}
 }
+
+func TestImageArchiveSave(t *testing.T) {
+	saveErr := fmt.Errorf("save error")
+	tests := []struct {
+		cli            mockDockerClient
+		expectedOutput string
+		expectedErr    error
+	}{
+		{
+			cli: mockDockerClient{
+				imageSave: func() (io.ReadCloser, error) {
+					return ioutil.NopCloser(strings.NewReader("image tarball")), nil
+				},
+			},
+			expectedOutput: "image tarball",
+		},
+		{
+			cli: mockDockerClient{
+				imageSave: func() (io.ReadCloser, error) {
+					return nil, saveErr
+				},
+			},
+			expectedErr: saveErr,
+		},
+	}
+
+	for _, tt := range tests {
+		cli := tt.cli
+		archive := aircontainer.NewDockerImageArchive(context.Background(), &cli)
+		out := &bytes.Buffer{}
+		actualErr := archive.ImageSave(out, []string{"quay.io/image:v1"})
+		assert.Equal(t, tt.expectedErr, actualErr)
+		assert.Equal(t, tt.expectedOutput, out.String())
+	}
+}
+
+func TestImageArchiveLoad(t *testing.T) {
+	loadErr := fmt.Errorf("load error")
+	tests := []struct {
+		cli         mockDockerClient
+		expectedErr error
+	}{
+		{
+			cli: mockDockerClient{
+				imageLoad: func(io.Reader) (types.ImageLoadResponse, error) {
+					return types.ImageLoadResponse{
+						Body: ioutil.NopCloser(strings.NewReader(`{"stream":"Loaded image: quay.io/image:v1\n"}`)),
+					}, nil
+				},
+			},
+		},
+		{
+			cli: mockDockerClient{
+				imageLoad: func(io.Reader) (types.ImageLoadResponse, error) {
+					return types.ImageLoadResponse{}, loadErr
+				},
+			},
+			expectedErr: loadErr,
+		},
+		{
+			cli: mockDockerClient{
+				imageLoad: func(io.Reader) (types.ImageLoadResponse, error) {
+					return types.ImageLoadResponse{
+						Body: ioutil.NopCloser(strings.NewReader(`{"error":"unexpected EOF"}`)),
+					}, nil
+				},
+			},
+			expectedErr: aircontainer.ErrImageLoad{Message: "unexpected EOF"},
+		},
+	}
+
+	for _, tt := range tests {
+		cli := tt.cli
+		archive := aircontainer.NewDockerImageArchive(context.Background(), &cli)
+		actualErr := archive.ImageLoad(strings.NewReader("image tarball"))
+		assert.Equal(t, tt.expectedErr, actualErr)
+	}
+}
@@ -16,6 +16,7 @@ package container
 
 import (
 	"fmt"
+	"strings"
 )
 
 // ErrEmptyImageList returned if no image defined in filter found
@@ -52,3 +53,66 @@ type ErrNoContainerDriver struct {
 func (e ErrNoContainerDriver) Error() string {
 	return fmt.Sprintf("container runtime is not defined in airshipctl config")
 }
+
+// ErrImageLoad returned if container runtime failed to import images
+type ErrImageLoad struct {
+	Message string
+}
+
+func (e ErrImageLoad) Error() string {
+	return fmt.Sprintf("failed to load images: %s", e.Message)
+}
+
+// ErrContainerTimeout returned if container is still running after the timeout specified in container spec
+type ErrContainerTimeout struct {
+	Timeout uint64
+}
+
+func (e ErrContainerTimeout) Error() string {
+	return fmt.Sprintf("container timed out after %d seconds", e.Timeout)
+}
+
+// ErrUnsupportedOption returned if generic container spec sets the option its type doesn't support
+type ErrUnsupportedOption struct {
+	Type   string
+	Option string
+}
+
+func (e ErrUnsupportedOption) Error() string {
+	return fmt.Sprintf("option %s is not supported by %s generic containers", e.Option, e.Type)
+}
+
+// ErrContainerFailed returned if airship container exited with non-zero code or timed out,
+// it contains the container state and the last lines of its stderr
+type ErrContainerFailed struct {
+	Image       string
+	ContainerID string
+	State       State
+	Stderr      []string
+	Kept        bool
+	Err         error
+}
+
+func (e ErrContainerFailed) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "container with image '%s' failed: %s", e.Image, e.Err)
+	fmt.Fprintf(&b, "\n  status: %s, exit code: %d", e.State.Status, e.State.ExitCode)
+	if e.State.OOMKilled {
+		b.WriteString(", killed because of out of memory")
+	}
+	if e.State.Error != "" {
+		fmt.Fprintf(&b, "\n  runtime error: %s", e.State.Error)
+	}
+	if e.Kept {
+		fmt.Fprintf(&b, "\n  container '%s' is kept for debugging, remove it manually", e.ContainerID)
+	}
+	if len(e.Stderr) > 0 {
+		fmt.Fprintf(&b, "\n  last %d lines of stderr:\n    %s", len(e.Stderr), strings.Join(e.Stderr, "\n    "))
+	}
+	return b.String()
+}
+
+// Unwrap returns the underlying error
+func (e ErrContainerFailed) Unwrap() error {
+	return e.Err
+}
@@ -15,9 +15,11 @@
 package runfn
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"os/user"
 	"path"
 	"path/filepath"
@@ -25,13 +27,18 @@ import (
 	"strconv"
 	"strings"
 	"sync/atomic"
+	"time"
 
 	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/container"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/starlark"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"opendev.org/airship/airshipctl/pkg/document/plugin"
 )
 
 // RunFns runs the set of configuration functions in a local directory against
@@ -107,6 +114,20 @@ type RunFns struct {
 
 	// Timeout is the maximum amount of time (in seconds) for KRM function execution
 	Timeout uint64
+
+	// NoNativeFunctions if set to true will run all the functions as containers, even
+	// if the function has in-process implementation registered in the plugin registry
+	NoNativeFunctions bool
+
+	// EnableStarlark will enable functions run as starlark scripts
+	EnableStarlark bool
+
+	// EnableExec will enable functions run as local binaries, storage mounts
+	// aren't applied to them since they are executed on the host
+	EnableExec bool
+
+	// ExecArgs are the arguments passed to the functions run as local binaries
+	ExecArgs []string
 }
 
 // Execute runs the command
@@ -327,9 +348,19 @@ func (r RunFns) getFunctionFilters(global bool, fns ...*yaml.RNode) (
 		if c == nil {
 			continue
 		}
-		cf, ok := c.(*container.Filter)
-		if global && ok {
-			cf.Exec.GlobalScope = true
+		switch cf := c.(type) {
+		case *container.Filter:
+			if global {
+				cf.Exec.GlobalScope = true
+			}
+		case *runtimeutil.FunctionFilter:
+			if global {
+				cf.GlobalScope = true
+			}
+		case *starlark.Filter:
+			if global {
+				cf.GlobalScope = true
+			}
 		}
 		fltrs = append(fltrs, c)
 	}
@@ -464,6 +495,15 @@ func (r *RunFns) ffp(spec runtimeutil.FunctionSpec, api *yaml.RNode, currentUser
 			"results-%v.yaml", r.resultsCount))
 		atomic.AddUint32(&r.resultsCount, 1)
 	}
+	if processor, found := plugin.Lookup(spec.Container.Image); found && !r.NoNativeFunctions {
+		return &runtimeutil.FunctionFilter{
+			Run:            runNative(processor),
+			FunctionConfig: api,
+			GlobalScope:    r.GlobalScope,
+			ResultsFile:    resultsFile,
+			DeferFailure:   spec.DeferFailure,
+		}, nil
+	}
 	if spec.Container.Image != "" {
 		// TODO: Add a test for this behavior
 		uidgid, err := getUIDGID(r.AsCurrentUser, currentUser)
@@ -494,9 +534,58 @@ func (r *RunFns) ffp(spec runtimeutil.FunctionSpec, api *yaml.RNode, currentUser
 		return cf, nil
 	}
 
+	if r.EnableStarlark && (spec.Starlark.Path != "" || spec.Starlark.URL != "") {
+		sf := &starlark.Filter{Name: spec.Starlark.Name, Path: spec.Starlark.Path, URL: spec.Starlark.URL}
+		sf.FunctionConfig = api
+		sf.GlobalScope = r.GlobalScope
+		sf.ResultsFile = resultsFile
+		sf.DeferFailure = spec.DeferFailure
+		return sf, nil
+	}
+
+	if r.EnableExec && spec.Exec.Path != "" {
+		return &runtimeutil.FunctionFilter{
+			Run:            r.runExec(spec),
+			FunctionConfig: api,
+			GlobalScope:    r.GlobalScope,
+			ResultsFile:    resultsFile,
+			DeferFailure:   spec.DeferFailure,
+		}, nil
+	}
+
 	return nil, nil
 }
 
+// runExec returns function which executes local binary with resource list passed to stdin,
+// environment variables are exported the same way as for containers
+func (r *RunFns) runExec(spec runtimeutil.FunctionSpec) func(io.Reader, io.Writer) error {
+	return func(reader io.Reader, writer io.Writer) error {
+		ctx := context.Background()
+		if r.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, time.Duration(r.Timeout)*time.Second)
+			defer cancel()
+		}
+
+		cmd := exec.CommandContext(ctx, spec.Exec.Path, r.ExecArgs...)
+		cmd.Stdin = reader
+		cmd.Stdout = writer
+		cmd.Stderr = os.Stderr
+		cmd.Env = os.Environ()
+		for key, value := range runtimeutil.NewContainerEnvFromStringSlice(spec.Container.Env).EnvVars {
+			cmd.Env = append(cmd.Env, key+"="+value)
+		}
+		return cmd.Run()
+	}
+}
+
+// runNative returns function which executes in-process implementation of the KRM function
+func runNative(processor framework.ResourceListProcessor) func(io.Reader, io.Writer) error {
+	return func(reader io.Reader, writer io.Writer) error {
+		return plugin.Run(processor, reader, writer)
+	}
+}
+
 // getArgs returns the command + args to run to spawn the container
 func (r *RunFns) getCommand(c container.Filter) (string, []string) {
 	network := runtimeutil.NetworkNameNone
@@ -29,11 +29,14 @@ import (
 
 	"sigs.k8s.io/kustomize/kyaml/copyutil"
 	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/container"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/kio/filters"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"opendev.org/airship/airshipctl/pkg/document/plugin"
 )
 
 const (
@@ -1167,6 +1170,57 @@ metadata:
 	}
 }
 
+func TestRunFns_nativeFunction(t *testing.T) {
+	plugin.Register("example.com/native", framework.ResourceListProcessorFunc(
+		func(rl *framework.ResourceList) error {
+			value, err := rl.FunctionConfig.Pipe(yaml.Lookup("value"))
+			if err != nil {
+				return err
+			}
+			for _, item := range rl.Items {
+				if err = item.PipeE(yaml.SetLabel("native", yaml.GetValue(value))); err != nil {
+					return err
+				}
+			}
+			return nil
+		}))
+
+	fn, err := yaml.Parse(`
+kind: fakefn
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      container:
+        image: example.com/native:v1
+value: processed
+`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	t.Run("in-process", func(t *testing.T) {
+		ouputBuffer := bytes.Buffer{}
+		instance := RunFns{
+			Input:     bytes.NewReader([]byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm\n")),
+			Output:    &ouputBuffer,
+			Functions: []*yaml.RNode{fn},
+		}
+		if !assert.NoError(t, instance.Execute()) {
+			t.FailNow()
+		}
+		assert.Contains(t, ouputBuffer.String(), "native: processed")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		instance := RunFns{NoNativeFunctions: true, noCmdSet: true}
+		instance.init()
+		spec := runtimeutil.GetFunctionSpec(fn)
+		filter, err := instance.functionFilterProvider(*spec, fn, currentUser)
+		assert.NoError(t, err)
+		assert.IsType(t, &container.Filter{}, filter)
+	})
+}
+
 // setupTest initializes a temp test directory containing test data
 func setupTest(t *testing.T) string {
 	dir, err := ioutil.TempDir("", "kustomize-kyaml-test")
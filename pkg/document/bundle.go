@@ -15,16 +15,22 @@
 package document
 
 import (
+	"encoding/json"
 	"io"
+	"path/filepath"
 	"strings"
 
 	kustfs "sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/filters/patchjson6902"
 	"sigs.k8s.io/kustomize/api/hasher"
 	"sigs.k8s.io/kustomize/api/krusty"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/yaml"
 
+	"opendev.org/airship/airshipctl/pkg/document/sops"
 	"opendev.org/airship/airshipctl/pkg/fs"
 	utilyaml "opendev.org/airship/airshipctl/pkg/util/yaml"
 )
@@ -57,8 +63,21 @@ type Bundle interface {
 	GetByLabel(labelSelector string) ([]Document, error)
 	GetAllDocuments() ([]Document, error)
 	Append(Document) error
+	Remove(selector Selector) error
+	Replace(Document) error
+	Patch(selector Selector, patchType string, patch []byte) error
+	WriteJSON(out io.Writer) error
+	WriteResourceList(out io.Writer) error
+	WriteDir(fSys fs.FileSystem, dir string) error
 }
 
+const (
+	// StrategicMergePatchType is a partial document merged into the patched documents
+	StrategicMergePatchType = "strategic-merge"
+	// JSON6902PatchType is a list of JSON patch operations as defined by RFC 6902, json or yaml encoded
+	JSON6902PatchType = "json6902"
+)
+
 // DocFactoryFunc is a type of function which returns (Document, error) and can be used on demand
 type DocFactoryFunc func() (Document, error)
 
@@ -74,20 +93,20 @@ func BundleFactoryFromBytes(data []byte) BundleFactoryFunc {
 }
 
 // BundleFactoryFromDocRoot is a function which returns BundleFactoryFunc based on new bundle from DocumentRoot path
-func BundleFactoryFromDocRoot(docRootFunc func() (string, error)) BundleFactoryFunc {
+func BundleFactoryFromDocRoot(docRootFunc func() (string, error), opts ...BundleOption) BundleFactoryFunc {
 	return func() (Bundle, error) {
 		path, err := docRootFunc()
 		if err != nil {
 			return nil, err
 		}
-		return NewBundleByPath(path)
+		return NewBundleByPath(path, opts...)
 	}
 }
 
 // NewBundleByPath is a function which builds new document.Bundle from kustomize rootPath using default FS object
 // example: document.NewBundleByPath("path/to/phase-root")
-func NewBundleByPath(rootPath string) (Bundle, error) {
-	return NewBundle(fs.NewDocumentFs(), rootPath)
+func NewBundleByPath(rootPath string, opts ...BundleOption) (Bundle, error) {
+	return NewBundle(fs.NewDocumentFs(), rootPath, opts...)
 }
 
 // NewBundleFromBytes is a function which builds new document.Bundle from raw []bytes
@@ -105,10 +124,82 @@ func NewBundleFromBytes(data []byte) (Bundle, error) {
 	return NewBundle(fSys, "/")
 }
 
+// NewBundleFromStream builds new document.Bundle from multi-document yaml stream. Unlike
+// NewBundleFromBytes the documents are taken as is, they are neither built by kustomize nor reordered
+func NewBundleFromStream(data []byte) (Bundle, error) {
+	resources, err := resource.NewFactory(&hasher.Hasher{}).SliceFromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	resourceMap := resmap.New()
+	for _, res := range resources {
+		if err = resourceMap.Append(res); err != nil {
+			return nil, err
+		}
+	}
+	return &BundleFactory{ResMap: resourceMap, FileSystem: fs.NewDocumentFs()}, nil
+}
+
+// BundleOption is a function that allows to modify the way the bundle is built
+type BundleOption func(*bundleOptions)
+
+type bundleOptions struct {
+	tolerateDecryptionFailures bool
+	ignoreDecryptionMAC        bool
+	decryptionKeyFiles         sops.KeyFiles
+	nativeFunction             func(image string) bool
+	functionAnnotations        []functionAnnotation
+}
+
+// TolerateDecryptionFailures leaves the documents which can't be decrypted encrypted
+// instead of failing the bundle build
+func TolerateDecryptionFailures() BundleOption {
+	return func(o *bundleOptions) {
+		o.tolerateDecryptionFailures = true
+	}
+}
+
+// IgnoreDecryptionMAC skips the integrity check of the encrypted documents, it is needed
+// if the encrypted files were edited after encryption, e.g. unencrypted fields were changed by hand
+func IgnoreDecryptionMAC() BundleOption {
+	return func(o *bundleOptions) {
+		o.ignoreDecryptionMAC = true
+	}
+}
+
+// DecryptionKeyFiles adds the key files to the keys taken from the environment, see sops.KeysFromEnv
+func DecryptionKeyFiles(files sops.KeyFiles) BundleOption {
+	return func(o *bundleOptions) {
+		o.decryptionKeyFiles = files
+	}
+}
+
+// NativeFunctions makes kustomize run the KRM functions which have in-process implementations
+// by executing airshipctl binary itself instead of pulling and running their images, native
+// reports if the image has the implementation, see plugin.Lookup
+func NativeFunctions(native func(image string) bool) BundleOption {
+	return func(o *bundleOptions) {
+		o.nativeFunction = native
+	}
+}
+
+// FunctionAnnotation sets the annotation on the configs of the KRM functions of the kind
+// referenced by kustomizations, e.g. to enable the trace of replacement transformers
+func FunctionAnnotation(kind, key, value string) BundleOption {
+	return func(o *bundleOptions) {
+		o.functionAnnotations = append(o.functionAnnotations, functionAnnotation{kind: kind, key: key, value: value})
+	}
+}
+
 // NewBundle is a convenience function to create a new bundle
 // Over time, it will evolve to support allowing more control
 // for kustomize plugins
-func NewBundle(fSys fs.FileSystem, kustomizePath string) (Bundle, error) {
+func NewBundle(fSys fs.FileSystem, kustomizePath string, opts ...BundleOption) (Bundle, error) {
+	bundleOpts := &bundleOptions{}
+	for _, o := range opts {
+		o(bundleOpts)
+	}
+
 	var options = KustomizeBuildOptions{
 		KustomizationPath: kustomizePath,
 		LoadRestrictions:  types.LoadRestrictionsRootOnly,
@@ -133,13 +224,13 @@ func NewBundle(fSys fs.FileSystem, kustomizePath string) (Bundle, error) {
 			PluginRestrictions: types.PluginRestrictionsNone,
 			BpLoadingOptions:   types.BploUseStaticallyLinked,
 			FnpLoadingOptions: types.FnPluginLoadingOptions{
-				Network: true,
+				Network:    true,
+				EnableExec: bundleOpts.nativeFunction != nil,
 			},
 		},
 	}
 
-	kustomizer := krusty.MakeKustomizer(&o)
-	m, err := kustomizer.Run(fSys, kustomizePath)
+	m, err := build(fSys, kustomizePath, &o, bundleOpts)
 	if err != nil {
 		return nil, err
 	}
@@ -147,6 +238,29 @@ func NewBundle(fSys fs.FileSystem, kustomizePath string) (Bundle, error) {
 	return bundle, err
 }
 
+// build runs kustomize, the documents encrypted with sops are decrypted as they are read and the
+// function configs are rewritten if native functions or function annotations are requested
+func build(fSys fs.FileSystem, kustomizePath string, o *krusty.Options, opts *bundleOptions) (resmap.ResMap, error) {
+	kustomizer := krusty.MakeKustomizer(o)
+	decrypt := newDecryptFs(fSys, opts)
+	run := func(fSys fs.FileSystem) (resmap.ResMap, error) {
+		m, err := kustomizer.Run(fSys, kustomizePath)
+		if err != nil && decrypt.err != nil {
+			return nil, decrypt.err
+		}
+		return m, err
+	}
+	if opts.nativeFunction == nil && len(opts.functionAnnotations) == 0 {
+		return run(decrypt)
+	}
+	functions, err := newFunctionFs(decrypt, opts)
+	if err != nil {
+		return nil, err
+	}
+	m, err := run(functions)
+	return m, functions.cleanup(err)
+}
+
 // GetKustomizeResourceMap returns a Kustomize Resource Map for this bundle
 func (b *BundleFactory) GetKustomizeResourceMap() resmap.ResMap {
 	return b.ResMap
@@ -205,8 +319,7 @@ func (b *BundleFactory) GetByName(name string) (Document, error) {
 // Select offers an interface to pass a Selector, built on top of kustomize Selector
 // to the bundle returning Documents that match the criteria
 func (b *BundleFactory) Select(selector Selector) ([]Document, error) {
-	// use the kustomize select method
-	resources, err := b.ResMap.Select(selector.Selector)
+	resources, err := b.selectResources(selector)
 	if err != nil {
 		return []Document{}, err
 	}
@@ -254,8 +367,7 @@ func (b *BundleFactory) SelectOne(selector Selector) (Document, error) {
 // test cases where you want to pass in custom "filtered" bundles
 // specific to the test case
 func (b *BundleFactory) SelectBundle(selector Selector) (Bundle, error) {
-	// use the kustomize select method
-	resources, err := b.ResMap.Select(selector.Selector)
+	resources, err := b.selectResources(selector)
 	if err != nil {
 		return nil, err
 	}
@@ -278,6 +390,66 @@ func (b *BundleFactory) SelectBundle(selector Selector) (Bundle, error) {
 	}, nil
 }
 
+// selectResources returns the resources matching the selector in the order of the bundle
+func (b *BundleFactory) selectResources(selector Selector) ([]*resource.Resource, error) {
+	selected, err := b.selectedSet(selector)
+	if err != nil {
+		return nil, err
+	}
+	var resources []*resource.Resource
+	for _, res := range b.Resources() {
+		if selected[res] {
+			resources = append(resources, res)
+		}
+	}
+	return resources, nil
+}
+
+// selectedSet returns the set of resources matching the kustomize selector and the field
+// selectors, restricted to the union of AnyOf selectors and without the excluded ones
+func (b *BundleFactory) selectedSet(selector Selector) (map[*resource.Resource]bool, error) {
+	// use the kustomize select method
+	resources, err := b.ResMap.Select(selector.Selector)
+	if err != nil {
+		return nil, err
+	}
+	selected := make(map[*resource.Resource]bool, len(resources))
+	for _, res := range resources {
+		if selected[res], err = selector.matchFields(&res.RNode); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(selector.AnyOf) > 0 {
+		union := map[*resource.Resource]bool{}
+		for _, alternative := range selector.AnyOf {
+			var alternativeSet map[*resource.Resource]bool
+			if alternativeSet, err = b.selectedSet(alternative); err != nil {
+				return nil, err
+			}
+			for res, ok := range alternativeSet {
+				union[res] = union[res] || ok
+			}
+		}
+		for res := range selected {
+			selected[res] = selected[res] && union[res]
+		}
+	}
+
+	for _, excluded := range selector.Exclude {
+		var excludedSet map[*resource.Resource]bool
+		if excludedSet, err = b.selectedSet(excluded); err != nil {
+			return nil, err
+		}
+		for res, ok := range excludedSet {
+			if ok {
+				selected[res] = false
+			}
+		}
+	}
+	return selected, nil
+}
+
 // SelectByFieldValue returns new Bundle with filtered resource documents.
 // Method iterates over all resources in the bundle. If resource has field
 // (i.e. key) specified in JSON path, and the comparison function returns
@@ -399,6 +571,91 @@ func (b *BundleFactory) Append(doc Document) error {
 	return b.ResMap.Append(res)
 }
 
+// Remove deletes the documents matching the selector from the bundle
+func (b *BundleFactory) Remove(selector Selector) error {
+	resources, err := b.selectResources(selector)
+	if err != nil {
+		return err
+	}
+	for _, res := range resources {
+		if err = b.ResMap.Remove(res.CurId()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Replace substitutes the document of the bundle which has the same group, version, kind,
+// namespace and name as the given one, this only works with document interface implementation
+// that is provided by this package
+func (b *BundleFactory) Replace(doc Document) error {
+	data, err := doc.AsYAML()
+	if err != nil {
+		return err
+	}
+	res, err := resource.NewFactory(&hasher.Hasher{}).FromBytes(data)
+	if err != nil {
+		return err
+	}
+	idx, err := b.ResMap.GetIndexOfCurrentId(res.CurId())
+	if err != nil {
+		return err
+	}
+	if idx < 0 {
+		return ErrDocNotFound{Selector: NewSelector().
+			ByGvk(doc.GetGroup(), doc.GetVersion(), doc.GetKind()).
+			ByNamespace(doc.GetNamespace()).
+			ByName(doc.GetName())}
+	}
+	_, err = b.ResMap.Replace(res)
+	return err
+}
+
+// Patch applies the patch of the given type to the documents matching the selector.
+// Documents deleted by the patch, e.g. by strategic merge patch with '$patch: delete',
+// are removed from the bundle
+func (b *BundleFactory) Patch(selector Selector, patchType string, patch []byte) error {
+	resources, err := b.selectResources(selector)
+	if err != nil {
+		return err
+	}
+
+	switch patchType {
+	case StrategicMergePatchType:
+		var patchRes *resource.Resource
+		if patchRes, err = resource.NewFactory(&hasher.Hasher{}).FromBytes(patch); err != nil {
+			return err
+		}
+		for _, res := range resources {
+			// patch is merged using the schema of the patched document
+			patchCopy := patchRes.DeepCopy()
+			patchCopy.SetGvk(res.GetGvk())
+			if err = res.ApplySmPatch(patchCopy); err != nil {
+				return err
+			}
+		}
+	case JSON6902PatchType:
+		for _, res := range resources {
+			if err = res.ApplyFilter(patchjson6902.Filter{Patch: string(patch)}); err != nil {
+				return err
+			}
+		}
+	default:
+		return ErrUnknownPatchType{PatchType: patchType}
+	}
+
+	resourceMap := resmap.New()
+	for _, res := range b.ResMap.Resources() {
+		if res.IsNilOrEmpty() {
+			continue
+		}
+		if err = resourceMap.Append(res); err != nil {
+			return err
+		}
+	}
+	return b.SetKustomizeResourceMap(resourceMap)
+}
+
 // Write will write out the entire bundle resource map
 func (b *BundleFactory) Write(out io.Writer) error {
 	for _, res := range b.ResMap.Resources() {
@@ -409,3 +666,94 @@ func (b *BundleFactory) Write(out io.Writer) error {
 	}
 	return nil
 }
+
+// list is a wrapper of the documents used by WriteJSON and WriteResourceList
+type list struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Items      []json.RawMessage `json:"items"`
+}
+
+func (b *BundleFactory) asList(apiVersion, kind string) (list, error) {
+	l := list{APIVersion: apiVersion, Kind: kind, Items: []json.RawMessage{}}
+	for _, res := range b.ResMap.Resources() {
+		data, err := res.MarshalJSON()
+		if err != nil {
+			return list{}, err
+		}
+		l.Items = append(l.Items, data)
+	}
+	return l, nil
+}
+
+// WriteJSON writes out the bundle as json encoded kubernetes List
+func (b *BundleFactory) WriteJSON(out io.Writer) error {
+	l, err := b.asList(ListAPIVersion, ListKind)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(append(data, '\n'))
+	return err
+}
+
+// WriteResourceList writes out the bundle as KRM function ResourceList
+func (b *BundleFactory) WriteResourceList(out io.Writer) error {
+	l, err := b.asList(kio.ResourceListAPIVersion, kio.ResourceListKind)
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	return err
+}
+
+// WriteDir writes each document of the bundle to a separate file named <kind>_<name>.yaml in the dir,
+// namespace is added to the file name if the names clash. The dir also gets kustomization.yaml listing
+// all the files in the order of the bundle, so that it can be used as kustomize root
+func (b *BundleFactory) WriteDir(fSys fs.FileSystem, dir string) error {
+	if err := fSys.MkdirAll(dir); err != nil {
+		return err
+	}
+
+	resources := b.ResMap.Resources()
+	fileName := func(res *resource.Resource, withNamespace bool) string {
+		parts := []string{strings.ToLower(res.GetKind())}
+		if withNamespace && res.GetNamespace() != "" {
+			parts = append(parts, res.GetNamespace())
+		}
+		parts = append(parts, res.GetName())
+		return strings.NewReplacer("/", "_", ":", "_").Replace(strings.Join(parts, "_")) + ".yaml"
+	}
+	clashes := map[string]int{}
+	for _, res := range resources {
+		clashes[fileName(res, false)]++
+	}
+
+	kustomization := types.Kustomization{
+		TypeMeta: types.TypeMeta{APIVersion: types.KustomizationVersion, Kind: types.KustomizationKind},
+	}
+	for _, res := range resources {
+		name := fileName(res, clashes[fileName(res, false)] > 1)
+		data, err := yaml.Marshal(res)
+		if err != nil {
+			return err
+		}
+		if err = fSys.WriteFile(filepath.Join(dir, name), data); err != nil {
+			return err
+		}
+		kustomization.Resources = append(kustomization.Resources, name)
+	}
+
+	data, err := yaml.Marshal(kustomization)
+	if err != nil {
+		return err
+	}
+	return fSys.WriteFile(filepath.Join(dir, KustomizationFile), data)
+}
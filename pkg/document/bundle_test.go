@@ -16,12 +16,19 @@ package document_test
 
 import (
 	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/kio"
 
 	"opendev.org/airship/airshipctl/pkg/document"
+	"opendev.org/airship/airshipctl/pkg/document/sops"
+	"opendev.org/airship/airshipctl/pkg/fs"
 	"opendev.org/airship/airshipctl/testutil"
 )
 
@@ -293,3 +300,428 @@ func TestSelectBundle(t *testing.T) {
 		assert.Len(t, docs, tt.expectedDocs)
 	}
 }
+
+func TestNewBundleDecryption(t *testing.T) {
+	const ageIdentity = "AGE-SECRET-KEY-1QHWHWU6WP2CFVMHWY8FEQXHEQ7VMFDJ282AE3CS462CDDX9CR8HQZR4VXN"
+
+	// make sure that the default age key file of the user running tests is not used
+	oldConfigHome, configHomeSet := os.LookupEnv("XDG_CONFIG_HOME")
+	os.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	defer func() {
+		if configHomeSet {
+			os.Setenv("XDG_CONFIG_HOME", oldConfigHome)
+		} else {
+			os.Unsetenv("XDG_CONFIG_HOME")
+		}
+	}()
+
+	keyFile := filepath.Join(t.TempDir(), "keys.txt")
+	require.NoError(t, ioutil.WriteFile(keyFile, []byte(ageIdentity+"\n"), 0600))
+
+	tests := []struct {
+		name          string
+		path          string
+		ageKey        string
+		opts          []document.BundleOption
+		expectedValue string
+		expectedErr   bool
+	}{
+		{
+			name:          "decrypted with age key",
+			path:          "testdata/encrypted",
+			ageKey:        ageIdentity,
+			expectedValue: "s3cr3t",
+		},
+		{
+			name:          "decrypted with configured key file",
+			path:          "testdata/encrypted",
+			opts:          []document.BundleOption{document.DecryptionKeyFiles(sops.KeyFiles{Age: []string{keyFile}})},
+			expectedValue: "s3cr3t",
+		},
+		{
+			name:        "no matching key",
+			path:        "testdata/encrypted",
+			expectedErr: true,
+		},
+		{
+			name:          "failures tolerated",
+			path:          "testdata/encrypted",
+			opts:          []document.BundleOption{document.TolerateDecryptionFailures()},
+			expectedValue: "ENC[",
+		},
+		{
+			name:          "decrypted before transformers",
+			path:          "testdata/encrypted-prefixed",
+			ageKey:        ageIdentity,
+			expectedValue: "s3cr3t",
+		},
+		{
+			name:        "modified after encryption",
+			path:        "testdata/encrypted-modified",
+			ageKey:      ageIdentity,
+			expectedErr: true,
+		},
+		{
+			name:          "modified after encryption with MAC ignored",
+			path:          "testdata/encrypted-modified",
+			ageKey:        ageIdentity,
+			opts:          []document.BundleOption{document.IgnoreDecryptionMAC()},
+			expectedValue: "s3cr3t",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			os.Setenv(sops.EnvAgeKey, tt.ageKey)
+			defer os.Unsetenv(sops.EnvAgeKey)
+
+			bundle, err := document.NewBundleByPath(tt.path, tt.opts...)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				assert.IsType(t, document.ErrDecryptionFailed{}, err)
+				return
+			}
+			require.NoError(t, err)
+
+			doc, err := bundle.SelectOne(document.NewSelector().ByKind("Secret"))
+			require.NoError(t, err)
+			password, err := doc.GetString("stringData.password")
+			require.NoError(t, err)
+			assert.Contains(t, password, tt.expectedValue)
+		})
+	}
+}
+
+const mutationTestDocs = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:v1
+      - name: sidecar
+        image: sidecar:v1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: default
+data:
+  key: value
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+  namespace: default
+stringData:
+  password: secret
+`
+
+func documentNames(t *testing.T, bundle document.Bundle) []string {
+	docs, err := bundle.GetAllDocuments()
+	require.NoError(t, err)
+	names := make([]string, len(docs))
+	for i, doc := range docs {
+		names[i] = doc.GetName()
+	}
+	return names
+}
+
+func TestBundleRemove(t *testing.T) {
+	tests := []struct {
+		name          string
+		selector      document.Selector
+		expectedNames []string
+	}{
+		{
+			name:          "remove by kind",
+			selector:      document.NewSelector().ByKind("Secret"),
+			expectedNames: []string{"settings", "app"},
+		},
+		{
+			name:          "remove multiple",
+			selector:      document.NewSelector().ByNamespace("default").Except(document.NewSelector().ByName("app")),
+			expectedNames: []string{"app"},
+		},
+		{
+			name:          "nothing to remove",
+			selector:      document.NewSelector().ByName("non-existent-doc"),
+			expectedNames: []string{"settings", "creds", "app"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			bundle, err := document.NewBundleFromBytes([]byte(mutationTestDocs))
+			require.NoError(t, err)
+			require.NoError(t, bundle.Remove(tt.selector))
+			assert.Equal(t, tt.expectedNames, documentNames(t, bundle))
+		})
+	}
+}
+
+func TestBundleReplace(t *testing.T) {
+	tests := []struct {
+		name        string
+		doc         string
+		expectedErr string
+	}{
+		{
+			name: "replace existing",
+			doc: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: default
+data:
+  key: replaced
+`,
+		},
+		{
+			name: "replace missing",
+			doc: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: other
+  namespace: default
+`,
+			expectedErr: `document filtered by selector [Version="v1", Kind="ConfigMap", ` +
+				`Namespace="default", Name="other"] found no documents`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			bundle, err := document.NewBundleFromBytes([]byte(mutationTestDocs))
+			require.NoError(t, err)
+			doc, err := document.NewDocumentFromBytes([]byte(tt.doc))
+			require.NoError(t, err)
+
+			err = bundle.Replace(doc)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []string{"settings", "creds", "app"}, documentNames(t, bundle))
+			replaced, err := bundle.SelectOne(document.NewSelector().ByKind("ConfigMap").ByName("settings"))
+			require.NoError(t, err)
+			value, err := replaced.GetString("data.key")
+			require.NoError(t, err)
+			assert.Equal(t, "replaced", value)
+		})
+	}
+}
+
+func TestBundlePatch(t *testing.T) {
+	tests := []struct {
+		name          string
+		selector      document.Selector
+		patchType     string
+		patch         string
+		path          string
+		expectedValue interface{}
+		expectedNames []string
+		expectedErr   error
+	}{
+		{
+			name:      "strategic merge",
+			selector:  document.NewSelector().ByKind("Deployment"),
+			patchType: document.StrategicMergePatchType,
+			patch: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:v2
+`,
+			path: "spec.template.spec.containers",
+			expectedValue: []interface{}{
+				map[string]interface{}{"name": "app", "image": "app:v2"},
+				map[string]interface{}{"name": "sidecar", "image": "sidecar:v1"},
+			},
+			expectedNames: []string{"settings", "creds", "app"},
+		},
+		{
+			name:      "strategic merge delete",
+			selector:  document.NewSelector().ByKind("Secret"),
+			patchType: document.StrategicMergePatchType,
+			patch: `apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+$patch: delete
+`,
+			expectedNames: []string{"settings", "app"},
+		},
+		{
+			name:      "json6902",
+			selector:  document.NewSelector().ByKind("Deployment"),
+			patchType: document.JSON6902PatchType,
+			patch:     `[{"op": "replace", "path": "/spec/template/spec/containers/0/image", "value": "app:v2"}]`,
+			path:      "spec.template.spec.containers",
+			expectedValue: []interface{}{
+				map[string]interface{}{"name": "app", "image": "app:v2"},
+				map[string]interface{}{"name": "sidecar", "image": "sidecar:v1"},
+			},
+			expectedNames: []string{"settings", "creds", "app"},
+		},
+		{
+			name:      "json6902 yaml",
+			selector:  document.NewSelector().ByNamespace("default"),
+			patchType: document.JSON6902PatchType,
+			patch: `- op: add
+  path: /metadata/labels
+  value:
+    app: test
+`,
+			path:          "metadata.labels.app",
+			expectedValue: "test",
+			expectedNames: []string{"settings", "creds", "app"},
+		},
+		{
+			name:        "unknown patch type",
+			selector:    document.NewSelector(),
+			patchType:   "merge",
+			expectedErr: document.ErrUnknownPatchType{PatchType: "merge"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			bundle, err := document.NewBundleFromBytes([]byte(mutationTestDocs))
+			require.NoError(t, err)
+
+			err = bundle.Patch(tt.selector, tt.patchType, []byte(tt.patch))
+			if tt.expectedErr != nil {
+				assert.Equal(t, tt.expectedErr, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedNames, documentNames(t, bundle))
+			if tt.path == "" {
+				return
+			}
+			docs, err := bundle.Select(tt.selector)
+			require.NoError(t, err)
+			for _, doc := range docs {
+				var value interface{}
+				if _, ok := tt.expectedValue.(string); ok {
+					value, err = doc.GetString(tt.path)
+				} else {
+					value, err = doc.GetSlice(tt.path)
+				}
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedValue, value)
+			}
+		})
+	}
+}
+
+const writeTestDocs = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: default
+data:
+  key: value
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: system:reader
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: other
+data:
+  key: other
+`
+
+func TestNewBundleFromStream(t *testing.T) {
+	bundle, err := document.NewBundleFromStream([]byte(writeTestDocs))
+	require.NoError(t, err)
+	// order of the stream must be preserved, no kustomize sorting is done
+	assert.Equal(t, []string{"settings", "system:reader", "settings"}, documentNames(t, bundle))
+}
+
+func TestBundleWriteJSON(t *testing.T) {
+	bundle, err := document.NewBundleFromStream([]byte(writeTestDocs))
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, bundle.WriteJSON(buf))
+
+	var list struct {
+		APIVersion string                   `json:"apiVersion"`
+		Kind       string                   `json:"kind"`
+		Items      []map[string]interface{} `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &list))
+	assert.Equal(t, document.ListAPIVersion, list.APIVersion)
+	assert.Equal(t, document.ListKind, list.Kind)
+	require.Len(t, list.Items, 3)
+	assert.Equal(t, "ClusterRole", list.Items[1]["kind"])
+	assert.Equal(t, map[string]interface{}{"key": "other"}, list.Items[2]["data"])
+}
+
+func TestBundleWriteResourceList(t *testing.T) {
+	bundle, err := document.NewBundleFromStream([]byte(writeTestDocs))
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, bundle.WriteResourceList(buf))
+
+	list, err := document.NewDocumentFromBytes(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, kio.ResourceListAPIVersion, list.GetAPIVersion())
+	assert.Equal(t, kio.ResourceListKind, list.GetKind())
+	items, err := list.GetSlice("items")
+	require.NoError(t, err)
+	assert.Len(t, items, 3)
+}
+
+func TestBundleWriteDir(t *testing.T) {
+	bundle, err := document.NewBundleFromStream([]byte(writeTestDocs))
+	require.NoError(t, err)
+
+	dir := filepath.Join(t.TempDir(), "out")
+	require.NoError(t, bundle.WriteDir(fs.NewDocumentFs(), dir))
+
+	expectedFiles := []string{
+		"configmap_default_settings.yaml",
+		"clusterrole_system_reader.yaml",
+		"configmap_other_settings.yaml",
+	}
+	for _, name := range expectedFiles {
+		data, readErr := ioutil.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, readErr)
+		doc, docErr := document.NewDocumentFromBytes(data)
+		require.NoError(t, docErr)
+		assert.NotEmpty(t, doc.GetName())
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, document.KustomizationFile))
+	require.NoError(t, err)
+	result, err := document.NewBundleByPath(dir)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "- configmap_default_settings.yaml")
+	assert.Len(t, documentNames(t, result), 3)
+}
@@ -25,6 +25,26 @@ const (
 	DeployToK8sSelector = "airshipit.org/deploy-k8s notin (False, false)"
 )
 
+// Origin annotations, see NewBundleWithOrigin
+const (
+	// OriginFileAnnotation is the source file of the document
+	OriginFileAnnotation = BaseAirshipSelector + "/origin-file"
+	// OriginKustomizationsAnnotation is comma separated chain of kustomizations from entrypoint to the source file
+	OriginKustomizationsAnnotation = BaseAirshipSelector + "/origin-kustomizations"
+	// OriginPatchesAnnotation is comma separated list of patches that target the document
+	OriginPatchesAnnotation = BaseAirshipSelector + "/origin-patches"
+)
+
+// Native function settings, see NativeFunctions
+const (
+	// FunctionImageAnnotation is the image of the KRM function run by airshipctl binary in-process
+	FunctionImageAnnotation = BaseAirshipSelector + "/function-image"
+	// FunctionExecutable is the name kustomize executes airshipctl binary under, the binary runs
+	// the KRM function set by FunctionImageAnnotation of the function config instead of the
+	// command line interface if it's started under this name
+	FunctionExecutable = "airshipctl-krm-function"
+)
+
 // GVKs
 const (
 	SecretKind        = "Secret"
@@ -66,7 +86,34 @@ const (
 	ApplierContainerKind = "GenericContainer"
 	// ApplierContainerName defines Name for applier container
 	ApplierContainerName = "applier"
+
+	// ValidationPolicyGroup defines Group for validation policy documents
+	ValidationPolicyGroup = "airshipit.org"
+	// ValidationPolicyVersion defines Version for validation policy documents
+	ValidationPolicyVersion = "v1alpha1"
+	// ValidationPolicyKind defines Kind for validation policy documents
+	ValidationPolicyKind = "ValidationPolicy"
 )
 
 // KustomizationFile is used for kustomization file
 const KustomizationFile = "kustomization.yaml"
+
+// Kubernetes List used by bundle json output
+const (
+	// ListAPIVersion defines apiVersion of the list
+	ListAPIVersion = "v1"
+	// ListKind defines kind of the list
+	ListKind = "List"
+)
+
+// Bundle output formats
+const (
+	// YAMLOutputFormat is multi-document yaml stream
+	YAMLOutputFormat = "yaml"
+	// JSONOutputFormat is json encoded kubernetes List
+	JSONOutputFormat = "json"
+	// ResourceListOutputFormat is KRM function ResourceList
+	ResourceListOutputFormat = "resourcelist"
+	// DirOutputFormat is a directory with file per document
+	DirOutputFormat = "dir"
+)
@@ -0,0 +1,86 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package document
+
+import (
+	"bytes"
+
+	"sigs.k8s.io/kustomize/kyaml/kio"
+
+	"opendev.org/airship/airshipctl/pkg/document/sops"
+	"opendev.org/airship/airshipctl/pkg/fs"
+	"opendev.org/airship/airshipctl/pkg/log"
+)
+
+// decryptFs decrypts the documents encrypted with sops when kustomize reads them, so the documents
+// are decrypted before kustomize transformers modify them and their MAC can be verified. Keys are
+// taken from the environment and the key files of the options, see sops.KeysFromEnv
+type decryptFs struct {
+	fs.FileSystem
+	opts *bundleOptions
+	keys *sops.Keys
+	// err keeps the decryption failure since kustomize reports read errors as text
+	err error
+}
+
+func newDecryptFs(fSys fs.FileSystem, opts *bundleOptions) *decryptFs {
+	return &decryptFs{FileSystem: fSys, opts: opts}
+}
+
+// ReadFile decrypts the encrypted documents of the file, the documents which can't be decrypted
+// are left encrypted if decryption failures are tolerated
+func (f *decryptFs) ReadFile(path string) ([]byte, error) {
+	data, err := f.FileSystem.ReadFile(path)
+	if err != nil || !bytes.Contains(data, []byte(sops.MetadataKey+":")) {
+		return data, err
+	}
+	nodes, err := readOriginNodes(data)
+	if err != nil {
+		// leave malformed documents to kustomize to report
+		return data, nil
+	}
+
+	var decrypted bool
+	for _, node := range nodes {
+		if !sops.IsEncrypted(node) {
+			continue
+		}
+		if f.keys == nil {
+			if f.keys, err = sops.KeysFromEnv(f.opts.decryptionKeyFiles); err != nil {
+				f.err = err
+				return nil, err
+			}
+		}
+		if _, err = sops.Decrypt(node, f.keys, f.opts.ignoreDecryptionMAC); err != nil {
+			err = ErrDecryptionFailed{Kind: node.GetKind(), DocName: node.GetName(), Err: err}
+			if !f.opts.tolerateDecryptionFailures {
+				f.err = err
+				return nil, err
+			}
+			log.Debugf("Leaving document encrypted: %v", err)
+			continue
+		}
+		decrypted = true
+	}
+	if !decrypted {
+		return data, nil
+	}
+
+	buf := &bytes.Buffer{}
+	if err = (kio.ByteWriter{Writer: buf}).Write(nodes); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
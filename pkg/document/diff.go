@@ -0,0 +1,262 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package document
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Statuses of the document in the diff
+const (
+	DiffAdded    = "added"
+	DiffRemoved  = "removed"
+	DiffModified = "modified"
+)
+
+// MaskedValue replaces the values of secret data in the diff
+const MaskedValue = "<masked>"
+
+// FieldChange describes the change of a single document field,
+// Old is nil if the field was added and New is nil if the field was removed
+type FieldChange struct {
+	Path string      `json:"path"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// DocumentDiff describes the difference between two versions of the document
+type DocumentDiff struct {
+	APIVersion string        `json:"apiVersion"`
+	Kind       string        `json:"kind"`
+	Namespace  string        `json:"namespace,omitempty"`
+	Name       string        `json:"name"`
+	Status     string        `json:"status"`
+	Changes    []FieldChange `json:"changes,omitempty"`
+}
+
+// DiffBundles matches the documents of two bundles by group, version, kind, namespace
+// and name and returns the differences, documents without changes are omitted
+func DiffBundles(oldBundle, newBundle Bundle) ([]DocumentDiff, error) {
+	oldDocs, err := oldBundle.GetAllDocuments()
+	if err != nil {
+		return nil, err
+	}
+	newDocs, err := newBundle.GetAllDocuments()
+	if err != nil {
+		return nil, err
+	}
+	return DiffDocumentLists(oldDocs, newDocs)
+}
+
+// DiffDocumentLists matches the documents of two lists by group, version, kind, namespace
+// and name and returns the differences, documents without changes are omitted
+func DiffDocumentLists(oldDocs, newDocs []Document) ([]DocumentDiff, error) {
+	oldByKey := make(map[string]Document, len(oldDocs))
+	for _, doc := range oldDocs {
+		oldByKey[diffKey(doc)] = doc
+	}
+
+	var diffs []DocumentDiff
+	matched := make(map[string]bool, len(newDocs))
+	for _, doc := range newDocs {
+		key := diffKey(doc)
+		matched[key] = true
+		oldDoc, exists := oldByKey[key]
+		if !exists {
+			diffs = append(diffs, newDocumentDiff(doc, DiffAdded))
+			continue
+		}
+		changes, err := DiffDocuments(oldDoc, doc)
+		if err != nil {
+			return nil, err
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		diff := newDocumentDiff(doc, DiffModified)
+		diff.Changes = changes
+		diffs = append(diffs, diff)
+	}
+	for _, doc := range oldDocs {
+		if !matched[diffKey(doc)] {
+			diffs = append(diffs, newDocumentDiff(doc, DiffRemoved))
+		}
+	}
+	return diffs, nil
+}
+
+// DiffDocuments returns the changes of the document fields, the values of Secret data are masked
+func DiffDocuments(oldDoc, newDoc Document) ([]FieldChange, error) {
+	oldObj, err := toDiffObject(oldDoc)
+	if err != nil {
+		return nil, err
+	}
+	newObj, err := toDiffObject(newDoc)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []FieldChange
+	diffValues(nil, oldObj, newObj, &changes)
+	if isSecret(newDoc) {
+		for i := range changes {
+			if isSecretDataPath(changes[i].Path) {
+				changes[i].Old = maskValue(changes[i].Old)
+				changes[i].New = maskValue(changes[i].New)
+			}
+		}
+	}
+	return changes, nil
+}
+
+func newDocumentDiff(doc Document, status string) DocumentDiff {
+	apiVersion := doc.GetVersion()
+	if group := doc.GetGroup(); group != "" {
+		apiVersion = group + "/" + apiVersion
+	}
+	return DocumentDiff{
+		APIVersion: apiVersion,
+		Kind:       doc.GetKind(),
+		Namespace:  doc.GetNamespace(),
+		Name:       doc.GetName(),
+		Status:     status,
+	}
+}
+
+func diffKey(doc Document) string {
+	return strings.Join([]string{doc.GetGroup(), doc.GetVersion(), doc.GetKind(),
+		doc.GetNamespace(), doc.GetName()}, "|")
+}
+
+func isSecret(doc Document) bool {
+	return doc.GetGroup() == "" && doc.GetKind() == SecretKind
+}
+
+func isSecretDataPath(path string) bool {
+	for _, field := range []string{"data", "stringData"} {
+		if path == field || strings.HasPrefix(path, field+".") || strings.HasPrefix(path, field+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+func toDiffObject(doc Document) (interface{}, error) {
+	data, err := doc.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var obj interface{}
+	err = json.Unmarshal(data, &obj)
+	return obj, err
+}
+
+func diffValues(path []string, oldVal, newVal interface{}, changes *[]FieldChange) {
+	oldMap, oldIsMap := oldVal.(map[string]interface{})
+	newMap, newIsMap := newVal.(map[string]interface{})
+	if oldIsMap && newIsMap {
+		diffMaps(path, oldMap, newMap, changes)
+		return
+	}
+
+	oldList, oldIsList := oldVal.([]interface{})
+	newList, newIsList := newVal.([]interface{})
+	if oldIsList && newIsList {
+		diffLists(path, oldList, newList, changes)
+		return
+	}
+
+	if !reflect.DeepEqual(oldVal, newVal) {
+		*changes = append(*changes, FieldChange{Path: formatDiffPath(path), Old: oldVal, New: newVal})
+	}
+}
+
+func diffMaps(path []string, oldMap, newMap map[string]interface{}, changes *[]FieldChange) {
+	keys := make([]string, 0, len(oldMap)+len(newMap))
+	for k := range oldMap {
+		keys = append(keys, k)
+	}
+	for k := range newMap {
+		if _, exists := oldMap[k]; !exists {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fieldPath := append(path[:len(path):len(path)], k)
+		o, inOld := oldMap[k]
+		n, inNew := newMap[k]
+		switch {
+		case !inOld:
+			*changes = append(*changes, FieldChange{Path: formatDiffPath(fieldPath), New: n})
+		case !inNew:
+			*changes = append(*changes, FieldChange{Path: formatDiffPath(fieldPath), Old: o})
+		default:
+			diffValues(fieldPath, o, n, changes)
+		}
+	}
+}
+
+func diffLists(path []string, oldList, newList []interface{}, changes *[]FieldChange) {
+	for i := 0; i < len(oldList) || i < len(newList); i++ {
+		itemPath := append(path[:len(path):len(path)], fmt.Sprintf("[%d]", i))
+		switch {
+		case i >= len(oldList):
+			*changes = append(*changes, FieldChange{Path: formatDiffPath(itemPath), New: newList[i]})
+		case i >= len(newList):
+			*changes = append(*changes, FieldChange{Path: formatDiffPath(itemPath), Old: oldList[i]})
+		default:
+			diffValues(itemPath, oldList[i], newList[i], changes)
+		}
+	}
+}
+
+// formatDiffPath joins the path elements with dots, keys containing dots are put to brackets
+func formatDiffPath(path []string) string {
+	sb := strings.Builder{}
+	for _, p := range path {
+		switch {
+		case strings.HasPrefix(p, "["):
+			sb.WriteString(p)
+		case strings.Contains(p, "."):
+			fmt.Fprintf(&sb, "[%s]", p)
+		default:
+			if sb.Len() > 0 {
+				sb.WriteString(".")
+			}
+			sb.WriteString(p)
+		}
+	}
+	return sb.String()
+}
+
+func maskValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		masked := make(map[string]interface{}, len(v))
+		for k := range v {
+			masked[k] = MaskedValue
+		}
+		return masked
+	default:
+		return MaskedValue
+	}
+}
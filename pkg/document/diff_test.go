@@ -0,0 +1,148 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package document_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"opendev.org/airship/airshipctl/pkg/document"
+)
+
+func TestDiffBundles(t *testing.T) {
+	oldDocs := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default
+  annotations:
+    example.com/revision: "1"
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:v1
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+data:
+  password: b2xk
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: removed
+data:
+  key: value
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: same
+data:
+  key: value
+`
+	newDocs := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default
+  annotations:
+    example.com/revision: "2"
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:v2
+      - name: sidecar
+        image: sidecar:v1
+  paused: false
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+data:
+  password: bmV3
+  token: dG9rZW4=
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: same
+data:
+  key: value
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: added
+`
+	oldBundle, err := document.NewBundleFromBytes([]byte(oldDocs))
+	require.NoError(t, err)
+	newBundle, err := document.NewBundleFromBytes([]byte(newDocs))
+	require.NoError(t, err)
+
+	diffs, err := document.DiffBundles(oldBundle, newBundle)
+	require.NoError(t, err)
+	assert.Equal(t, []document.DocumentDiff{
+		{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Namespace:  "default",
+			Name:       "app",
+			Status:     document.DiffModified,
+			Changes: []document.FieldChange{
+				{Path: "metadata.annotations[example.com/revision]", Old: "1", New: "2"},
+				{Path: "spec.paused", New: false},
+				{Path: "spec.replicas", Old: float64(1)},
+				{Path: "spec.template.spec.containers[0].image", Old: "app:v1", New: "app:v2"},
+				{
+					Path: "spec.template.spec.containers[1]",
+					New:  map[string]interface{}{"name": "sidecar", "image": "sidecar:v1"},
+				},
+			},
+		},
+		{
+			APIVersion: "v1",
+			Kind:       "Secret",
+			Name:       "creds",
+			Status:     document.DiffModified,
+			Changes: []document.FieldChange{
+				{Path: "data.password", Old: document.MaskedValue, New: document.MaskedValue},
+				{Path: "data.token", New: document.MaskedValue},
+			},
+		},
+		{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+			Name:       "added",
+			Status:     document.DiffAdded,
+		},
+		{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+			Name:       "removed",
+			Status:     document.DiffRemoved,
+		},
+	}, diffs)
+}
@@ -16,13 +16,17 @@ package document
 
 import (
 	"fmt"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer/json"
 
 	"sigs.k8s.io/kustomize/api/hasher"
 	"sigs.k8s.io/kustomize/api/resource"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
 	"sigs.k8s.io/yaml"
+
+	"opendev.org/airship/airshipctl/pkg/document/plugin/kyamlutils"
 )
 
 // Factory holds document data
@@ -45,12 +49,15 @@ type Document interface {
 	GetMap(path string) (map[string]interface{}, error)
 	GetName() string
 	GetNamespace() string
+	GetOrigin() (Origin, bool)
 	GetSlice(path string) ([]interface{}, error)
 	GetString(path string) (string, error)
 	GetStringMap(path string) (map[string]string, error)
 	GetStringSlice(path string) ([]string, error)
 	GetVersion() string
 	Label(map[string]string)
+	SetField(path string, value interface{}) error
+	DeleteField(path string) error
 	MarshalJSON() ([]byte, error)
 	ToObject(interface{}) error
 	ToAPIObject(runtime.Object, *runtime.Scheme) error
@@ -89,6 +96,90 @@ func (d *Factory) Label(newLabels map[string]string) {
 	}
 }
 
+// SetField sets the value of the field at path, missing fields of the path are created.
+// Path is either a dot separated path, e.g. spec.containers[name=app].image, or a JSON path
+// in braces, e.g. {.spec.containers[?(.name == 'app')].image}
+func (d *Factory) SetField(path string, value interface{}) error {
+	data, err := kyaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	valueNode, err := kyaml.Parse(string(data))
+	if err != nil {
+		return err
+	}
+	found, err := d.RNode.Pipe(kyamlutils.JSONPathFilter{
+		Path:   path,
+		Create: true,
+		Mutator: func(rns []*kyaml.RNode) error {
+			for _, rn := range rns {
+				rn.SetYNode(valueNode.Copy().YNode())
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if found == nil {
+		return ErrDocumentDataKeyNotFound{DocName: d.GetName(), Key: path}
+	}
+	return nil
+}
+
+// DeleteField removes the field at path, path has the same format as in SetField and
+// must end with a field name. Deletion of missing field is not an error
+func (d *Factory) DeleteField(path string) error {
+	parent, field := splitFieldPath(path)
+	if field == "" || strings.HasSuffix(field, "]") {
+		return ErrDocumentMalformed{DocName: d.GetName(),
+			Message: fmt.Sprintf("path %s must end with a field name to be deleted", path)}
+	}
+	deleteField := func(rns []*kyaml.RNode) error {
+		for _, rn := range rns {
+			if _, err := rn.Pipe(kyaml.Clear(field)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if parent == "" {
+		return deleteField([]*kyaml.RNode{&d.RNode})
+	}
+	_, err := d.RNode.Pipe(kyamlutils.JSONPathFilter{Path: parent, Mutator: deleteField})
+	return err
+}
+
+// splitFieldPath splits the path into the path of the parent and the name of the last field,
+// dots inside brackets don't separate the fields
+func splitFieldPath(path string) (string, string) {
+	inner, braced := strings.TrimSpace(path), false
+	if strings.HasPrefix(inner, "{") && strings.HasSuffix(inner, "}") {
+		inner, braced = strings.TrimPrefix(inner[1:len(inner)-1], "."), true
+	}
+	depth, last := 0, -1
+	for i, c := range inner {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '.':
+			if depth == 0 {
+				last = i
+			}
+		}
+	}
+	parent, field := "", inner
+	if last >= 0 {
+		parent, field = inner[:last], inner[last+1:]
+	}
+	if braced && parent != "" {
+		parent = "{." + parent + "}"
+	}
+	return parent, field
+}
+
 // GetNamespace returns the namespace the resource thinks it's in.
 func (d *Factory) GetNamespace() string {
 	r := d.GetKustomizeResource()
@@ -218,6 +309,12 @@ func (d *Factory) GetName() string {
 	return r.GetName()
 }
 
+// GetOrigin returns the origin of the document, false is returned if the document
+// was not built by NewBundleWithOrigin
+func (d *Factory) GetOrigin() (Origin, bool) {
+	return OriginFromAnnotations(d.GetAnnotations())
+}
+
 // GetGroup returns api group from apiVersion field
 func (d *Factory) GetGroup() string {
 	r := d.GetKustomizeResource()
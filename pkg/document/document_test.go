@@ -288,3 +288,163 @@ stringData:
 		})
 	}
 }
+
+const fieldTestDoc = `apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+  labels:
+    app: test
+spec:
+  containers:
+  - name: app
+    image: app:v1
+`
+
+func fieldTestObject(mutate func(obj map[string]interface{})) map[string]interface{} {
+	obj := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name":   "app",
+			"labels": map[string]interface{}{"app": "test"},
+		},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": "app:v1"},
+			},
+		},
+	}
+	mutate(obj)
+	return obj
+}
+
+func metadata(obj map[string]interface{}) map[string]interface{} {
+	return obj["metadata"].(map[string]interface{})
+}
+
+func container(obj map[string]interface{}) map[string]interface{} {
+	return obj["spec"].(map[string]interface{})["containers"].([]interface{})[0].(map[string]interface{})
+}
+
+func TestSetField(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		value    interface{}
+		expected map[string]interface{}
+	}{
+		{
+			name:  "replace value",
+			path:  "metadata.labels.app",
+			value: "prod",
+			expected: fieldTestObject(func(obj map[string]interface{}) {
+				metadata(obj)["labels"] = map[string]interface{}{"app": "prod"}
+			}),
+		},
+		{
+			name:  "create missing fields",
+			path:  "metadata.annotations.owner",
+			value: "team",
+			expected: fieldTestObject(func(obj map[string]interface{}) {
+				metadata(obj)["annotations"] = map[string]interface{}{"owner": "team"}
+			}),
+		},
+		{
+			name:  "list item",
+			path:  "spec.containers[name=app].image",
+			value: "app:v2",
+			expected: fieldTestObject(func(obj map[string]interface{}) {
+				container(obj)["image"] = "app:v2"
+			}),
+		},
+		{
+			name:  "json path with structured value",
+			path:  "{.spec.containers[?(.name == 'app')].ports}",
+			value: []interface{}{map[string]interface{}{"containerPort": 8080}},
+			expected: fieldTestObject(func(obj map[string]interface{}) {
+				container(obj)["ports"] = []interface{}{map[string]interface{}{"containerPort": float64(8080)}}
+			}),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := document.NewDocumentFromBytes([]byte(fieldTestDoc))
+			require.NoError(t, err)
+			require.NoError(t, doc.SetField(tt.path, tt.value))
+
+			obj := map[string]interface{}{}
+			require.NoError(t, doc.ToObject(&obj))
+			assert.Equal(t, tt.expected, obj)
+		})
+	}
+}
+
+func TestDeleteField(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		expected    map[string]interface{}
+		expectedErr error
+	}{
+		{
+			name: "map field",
+			path: "metadata.labels.app",
+			expected: fieldTestObject(func(obj map[string]interface{}) {
+				metadata(obj)["labels"] = map[string]interface{}{}
+			}),
+		},
+		{
+			name: "field of list item",
+			path: "spec.containers[name=app].image",
+			expected: fieldTestObject(func(obj map[string]interface{}) {
+				delete(container(obj), "image")
+			}),
+		},
+		{
+			name: "json path",
+			path: "{.metadata.labels}",
+			expected: fieldTestObject(func(obj map[string]interface{}) {
+				delete(metadata(obj), "labels")
+			}),
+		},
+		{
+			name: "top level field",
+			path: "spec",
+			expected: fieldTestObject(func(obj map[string]interface{}) {
+				delete(obj, "spec")
+			}),
+		},
+		{
+			name:     "missing field",
+			path:     "spec.nodeSelector.zone",
+			expected: fieldTestObject(func(map[string]interface{}) {}),
+		},
+		{
+			name: "list item",
+			path: "spec.containers[name=app]",
+			expectedErr: document.ErrDocumentMalformed{DocName: "app",
+				Message: "path spec.containers[name=app] must end with a field name to be deleted"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := document.NewDocumentFromBytes([]byte(fieldTestDoc))
+			require.NoError(t, err)
+			err = doc.DeleteField(tt.path)
+			if tt.expectedErr != nil {
+				assert.Equal(t, tt.expectedErr, err)
+				return
+			}
+			require.NoError(t, err)
+
+			obj := map[string]interface{}{}
+			require.NoError(t, doc.ToObject(&obj))
+			assert.Equal(t, tt.expected, obj)
+		})
+	}
+}
@@ -16,6 +16,7 @@ package document
 
 import (
 	"fmt"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -56,6 +57,90 @@ type ErrBadValueFormat struct {
 	Actual   string
 }
 
+// ErrDecryptionFailed returned if sops encrypted document can't be decrypted
+type ErrDecryptionFailed struct {
+	Kind    string
+	DocName string
+	Err     error
+}
+
+// ErrInvalidFieldSelector returned if field selector expression can't be parsed
+type ErrInvalidFieldSelector struct {
+	Expression string
+	Reason     string
+}
+
+// ErrInvalidSelectorExpression returned if selector expression can't be parsed
+type ErrInvalidSelectorExpression struct {
+	Expression string
+	Reason     string
+}
+
+// ErrUnknownPatchType returned if bundle patch type is not supported
+type ErrUnknownPatchType struct {
+	PatchType string
+}
+
+// ErrKustomizationNotFound returned if directory of kustomization tree has no kustomization file
+type ErrKustomizationNotFound struct {
+	Dir string
+}
+
+// ErrKustomizationCycle returned if kustomizations of the tree include each other
+type ErrKustomizationCycle struct {
+	Chain []string
+}
+
+// ErrKustomizeSourceNotFound returned if a local source referenced by kustomization doesn't exist
+type ErrKustomizeSourceNotFound struct {
+	Kustomization string
+	Path          string
+}
+
+// ErrInvalidSchema returned if schema file can't be parsed
+type ErrInvalidSchema struct {
+	Path string
+	Err  error
+}
+
+// ErrUnsupportedCRDVersion returned if CustomResourceDefinition has unknown apiVersion
+type ErrUnsupportedCRDVersion struct {
+	APIVersion string
+	Name       string
+}
+
+// ErrDocumentInvalid describes schema violations of a single document
+type ErrDocumentInvalid struct {
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+	// Origin is the file the document comes from, empty if unknown
+	Origin string
+	Errors []string
+}
+
+// ErrSchemaValidationFailed returned if one or more documents don't match their schemas
+type ErrSchemaValidationFailed struct {
+	Documents []ErrDocumentInvalid
+}
+
+// ErrInvalidPolicy returned if validation policy can't be compiled
+type ErrInvalidPolicy struct {
+	Name   string
+	Reason string
+}
+
+// ErrPolicyValidationFailed returned if documents violate validation policies of error severity
+type ErrPolicyValidationFailed struct {
+	Violations []PolicyViolation
+}
+
+// ErrExecFunctionNotAllowed returned if kustomization references KRM function run as local executable
+type ErrExecFunctionNotAllowed struct {
+	Path string
+}
+
 func (e ErrDocNotFound) Error() string {
 	return fmt.Sprintf("document filtered by selector %v found no documents", e.Selector)
 }
@@ -79,3 +164,81 @@ func (e ErrRuntimeObjectKind) Error() string {
 func (e ErrBadValueFormat) Error() string {
 	return fmt.Sprintf("value of %s expected to have %s type, got %s", e.Value, e.Expected, e.Actual)
 }
+
+func (e ErrDecryptionFailed) Error() string {
+	return fmt.Sprintf("failed to decrypt document %s %q: %v", e.Kind, e.DocName, e.Err)
+}
+
+func (e ErrInvalidFieldSelector) Error() string {
+	return fmt.Sprintf("invalid field selector %q: %s", e.Expression, e.Reason)
+}
+
+func (e ErrInvalidSelectorExpression) Error() string {
+	return fmt.Sprintf("invalid selector expression %q: %s", e.Expression, e.Reason)
+}
+
+func (e ErrUnknownPatchType) Error() string {
+	return fmt.Sprintf("unknown patch type %q, supported types are %q and %q",
+		e.PatchType, StrategicMergePatchType, JSON6902PatchType)
+}
+
+func (e ErrKustomizationNotFound) Error() string {
+	return fmt.Sprintf("no kustomization file found in %s", e.Dir)
+}
+
+func (e ErrKustomizationCycle) Error() string {
+	return fmt.Sprintf("kustomization cycle detected: %s", strings.Join(e.Chain, " -> "))
+}
+
+func (e ErrKustomizeSourceNotFound) Error() string {
+	return fmt.Sprintf("source %s referenced by %s not found", e.Path, e.Kustomization)
+}
+
+func (e ErrInvalidSchema) Error() string {
+	return fmt.Sprintf("failed to parse schema %s: %v", e.Path, e.Err)
+}
+
+func (e ErrUnsupportedCRDVersion) Error() string {
+	return fmt.Sprintf("CustomResourceDefinition %q has unsupported apiVersion %s", e.Name, e.APIVersion)
+}
+
+func (e ErrDocumentInvalid) Error() string {
+	return fmt.Sprintf("document %s is invalid:\n  %s",
+		documentID(e.Kind, e.Namespace, e.Name, e.Origin), strings.Join(e.Errors, "\n  "))
+}
+
+func (e ErrSchemaValidationFailed) Error() string {
+	msgs := make([]string, len(e.Documents))
+	for i, doc := range e.Documents {
+		msgs[i] = doc.Error()
+	}
+	return fmt.Sprintf("schema validation failed for %d document(s):\n%s", len(e.Documents), strings.Join(msgs, "\n"))
+}
+
+func (e ErrInvalidPolicy) Error() string {
+	return fmt.Sprintf("invalid validation policy %q: %s", e.Name, e.Reason)
+}
+
+func (e ErrPolicyValidationFailed) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		msgs[i] = violation.String()
+	}
+	return fmt.Sprintf("policy validation failed with %d violation(s):\n%s", len(e.Violations), strings.Join(msgs, "\n"))
+}
+
+func (e ErrExecFunctionNotAllowed) Error() string {
+	return fmt.Sprintf("exec function %s is not allowed, only container functions are supported", e.Path)
+}
+
+// documentID identifies the document in error messages
+func documentID(kind, namespace, name, origin string) string {
+	id := kind + " " + name
+	if namespace != "" {
+		id = kind + " " + namespace + "/" + name
+	}
+	if origin != "" {
+		id += " (" + origin + ")"
+	}
+	return id
+}
@@ -0,0 +1,151 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package document
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"opendev.org/airship/airshipctl/pkg/fs"
+)
+
+// functionFs rewrites function configs of the KRM functions referenced by kustomizations, the functions
+// which have in-process implementations are made to run airshipctl binary as exec function since
+// kustomize can run functions only as separate processes. The binary is executed through a symlink
+// named FunctionExecutable, so only the function processes know that they have to run the function
+type functionFs struct {
+	fs.FileSystem
+	native      func(image string) bool
+	annotations []functionAnnotation
+	// dir keeps the symlink to the binary
+	dir        string
+	executable string
+}
+
+// functionAnnotation is the annotation set on the configs of the functions of the kind
+type functionAnnotation struct {
+	kind  string
+	key   string
+	value string
+}
+
+func newFunctionFs(fSys fs.FileSystem, opts *bundleOptions) (*functionFs, error) {
+	f := &functionFs{FileSystem: fSys, native: opts.nativeFunction, annotations: opts.functionAnnotations}
+	if f.native == nil {
+		return f, nil
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	if f.dir, err = ioutil.TempDir("", "airshipctl-functions-"); err != nil {
+		return nil, err
+	}
+	f.executable = filepath.Join(f.dir, FunctionExecutable)
+	if err = os.Symlink(executable, f.executable); err != nil {
+		return nil, f.cleanup(err)
+	}
+	return f, nil
+}
+
+// cleanup removes the symlink to the binary, err is returned unless it's nil and removal fails
+func (f *functionFs) cleanup(err error) error {
+	if f.dir == "" {
+		return err
+	}
+	if removeErr := os.RemoveAll(f.dir); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// ReadFile sets the annotations on the function configs and replaces container spec of the functions
+// which have in-process implementations with exec spec of airshipctl binary, the image is kept
+// in FunctionImageAnnotation
+func (f *functionFs) ReadFile(path string) ([]byte, error) {
+	data, err := f.FileSystem.ReadFile(path)
+	if err != nil || !(bytes.Contains(data, []byte("config.k")) || bytes.Contains(data, []byte("configFn"))) {
+		return data, err
+	}
+	nodes, err := readOriginNodes(data)
+	if err != nil {
+		// leave malformed documents to kustomize to report
+		return data, nil
+	}
+
+	var rewritten bool
+	for _, node := range nodes {
+		var changed bool
+		if changed, err = f.rewrite(node); err != nil {
+			return nil, err
+		}
+		rewritten = rewritten || changed
+	}
+	if !rewritten {
+		return data, nil
+	}
+
+	buf := &bytes.Buffer{}
+	if err = (kio.ByteWriter{Writer: buf}).Write(nodes); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// rewrite changes the document if it's function config, true is returned if it was changed
+func (f *functionFs) rewrite(node *kyaml.RNode) (bool, error) {
+	spec := runtimeutil.GetFunctionSpec(node)
+	if spec == nil {
+		return false, nil
+	}
+	var changed bool
+	for _, annotation := range f.annotations {
+		if annotation.kind != node.GetKind() {
+			continue
+		}
+		if err := node.PipeE(kyaml.SetAnnotation(annotation.key, annotation.value)); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	if f.native == nil {
+		return changed, nil
+	}
+	switch {
+	case spec.Exec.Path != "":
+		return false, ErrExecFunctionNotAllowed{Path: spec.Exec.Path}
+	case spec.Container.Image != "" && f.native(spec.Container.Image):
+		return true, f.execNative(node, spec.Container.Image)
+	}
+	return changed, nil
+}
+
+// execNative makes the function config run airshipctl binary instead of the image
+func (f *functionFs) execNative(node *kyaml.RNode, image string) error {
+	spec, err := kyaml.Marshal(map[string]runtimeutil.ExecSpec{"exec": {Path: f.executable}})
+	if err != nil {
+		return err
+	}
+	if err = node.PipeE(kyaml.SetAnnotation(runtimeutil.FunctionAnnotationKey, string(spec))); err != nil {
+		return err
+	}
+	return node.PipeE(kyaml.SetAnnotation(FunctionImageAnnotation, image))
+}
@@ -0,0 +1,44 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package document_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"opendev.org/airship/airshipctl/pkg/document"
+	"opendev.org/airship/airshipctl/pkg/document/plugin"
+)
+
+func nativeFunction(image string) bool {
+	_, found := plugin.Lookup(image)
+	return found
+}
+
+func TestNewBundleNativeFunctions(t *testing.T) {
+	bundle, err := document.NewBundleByPath("testdata/native-function", document.NativeFunctions(nativeFunction))
+	require.NoError(t, err)
+	docs, err := bundle.GetAllDocuments()
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "ConfigMap", docs[0].GetKind())
+	assert.Equal(t, "generated", docs[0].GetName())
+
+	_, err = document.NewBundleByPath("testdata/exec-function", document.NativeFunctions(nativeFunction))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), document.ErrExecFunctionNotAllowed{Path: "/bin/true"}.Error())
+}
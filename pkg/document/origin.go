@@ -0,0 +1,520 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package document
+
+import (
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"opendev.org/airship/airshipctl/pkg/fs"
+)
+
+// Origin describes where the document comes from
+type Origin struct {
+	// File is the source file which defines the document
+	File string
+	// Kustomizations is the chain of kustomization files from the entrypoint to the source file
+	Kustomizations []string
+	// Patches are the patches of these kustomizations that target the document
+	Patches []string
+}
+
+// String returns human readable description of the origin
+func (o Origin) String() string {
+	s := o.File
+	if len(o.Kustomizations) > 0 {
+		s += " (via " + strings.Join(o.Kustomizations, " -> ")
+		if len(o.Patches) > 0 {
+			s += "; patched by " + strings.Join(o.Patches, ", ")
+		}
+		s += ")"
+	}
+	return s
+}
+
+// Annotations returns the annotations recording the origin, see OriginFromAnnotations
+func (o Origin) Annotations() map[string]string {
+	annotations := map[string]string{
+		OriginFileAnnotation:           o.File,
+		OriginKustomizationsAnnotation: strings.Join(o.Kustomizations, ","),
+	}
+	if len(o.Patches) > 0 {
+		annotations[OriginPatchesAnnotation] = strings.Join(o.Patches, ",")
+	}
+	return annotations
+}
+
+// OriginFromAnnotations returns the origin recorded in the annotations of the document,
+// false is returned if the document has no origin annotations
+func OriginFromAnnotations(annotations map[string]string) (Origin, bool) {
+	file, ok := annotations[OriginFileAnnotation]
+	if !ok {
+		return Origin{}, false
+	}
+	return Origin{
+		File:           file,
+		Kustomizations: splitOriginList(annotations[OriginKustomizationsAnnotation]),
+		Patches:        splitOriginList(annotations[OriginPatchesAnnotation]),
+	}, true
+}
+
+func splitOriginList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// NewBundleWithOrigin returns a bundle built from kustomizePath like NewBundle does, every
+// document of the bundle is annotated with its origin, see OriginFileAnnotation. The resources are
+// annotated after the build, so transformers never see the annotations, while the configs of generators
+// and transformers are annotated when kustomize reads them to let plugins refer to their source.
+// Paths in the annotations are relative to baseDir unless it is empty
+func NewBundleWithOrigin(fSys fs.FileSystem, kustomizePath, baseDir string, opts ...BundleOption) (Bundle, error) {
+	if baseDir != "" {
+		// source paths are absolute with symlinks evaluated, the base must match them
+		if dir, _, err := fSys.CleanedAbs(baseDir); err == nil {
+			baseDir = string(dir)
+		}
+	}
+	tracker := &originTracker{
+		fSys:      fSys,
+		baseDir:   baseDir,
+		configs:   map[string][]*originKustomization{},
+		resources: map[resid.ResId]Origin{},
+		patches:   map[string][]*kyaml.RNode{},
+	}
+	if err := tracker.walk(kustomizePath, nil, false); err != nil {
+		return nil, err
+	}
+	bundle, err := NewBundle(&originFs{FileSystem: fSys, tracker: tracker}, kustomizePath, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return bundle, tracker.annotateBundle(bundle)
+}
+
+// originKustomization is a kustomization visited during the walk of kustomization tree
+type originKustomization struct {
+	path          string
+	dir           string
+	kustomization types.Kustomization
+}
+
+// originTracker knows the origins of the documents built from kustomization tree
+type originTracker struct {
+	fSys    fs.FileSystem
+	baseDir string
+	// configs maps clean absolute paths of generator and transformer configs to kustomization chains
+	configs map[string][]*originKustomization
+	// resources maps the ids the resources have after the build to their origins
+	resources map[resid.ResId]Origin
+	// patches caches parsed patch files
+	patches map[string][]*kyaml.RNode
+}
+
+// walk records the origins of the sources of the kustomization in dir and its sub kustomizations,
+// the sources of generator and transformer kustomizations are plugin configs
+func (t *originTracker) walk(dir string, chain []*originKustomization, configs bool) error {
+	root, _, err := t.fSys.CleanedAbs(dir)
+	if err != nil {
+		return err
+	}
+	for _, k := range chain {
+		if k.dir == string(root) {
+			// cycle, kustomize reports it on build
+			return nil
+		}
+	}
+	node, err := t.readKustomization(string(root))
+	if err != nil {
+		return err
+	}
+	chain = append(chain[:len(chain):len(chain)], node)
+
+	k := node.kustomization
+	for _, source := range k.Resources {
+		if err = t.walkSource(chain, source, configs); err != nil {
+			return err
+		}
+	}
+	for _, source := range concatStrings(k.Generators, k.Transformers) {
+		if err = t.walkSource(chain, source, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkSource records the origins of the source of the last kustomization of the chain
+func (t *originTracker) walkSource(chain []*originKustomization, source string, config bool) error {
+	if strings.Contains(source, "://") {
+		// remote sources are not tracked
+		return nil
+	}
+	path := filepath.Join(chain[len(chain)-1].dir, source)
+	switch {
+	case t.fSys.IsDir(path):
+		return t.walk(path, chain, config)
+	case config:
+		t.configs[path] = chain
+		return nil
+	default:
+		return t.trackResources(path, chain)
+	}
+}
+
+func (t *originTracker) readKustomization(dir string) (*originKustomization, error) {
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		path := filepath.Join(dir, name)
+		if !t.fSys.Exists(path) {
+			continue
+		}
+		data, err := t.fSys.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		k := types.Kustomization{}
+		if err = k.Unmarshal(data); err != nil {
+			return nil, err
+		}
+		k.FixKustomizationPostUnmarshalling()
+		return &originKustomization{path: path, dir: dir, kustomization: k}, nil
+	}
+	return nil, ErrKustomizationNotFound{Dir: dir}
+}
+
+func concatStrings(lists ...[]string) []string {
+	var result []string
+	for _, list := range lists {
+		result = append(result, list...)
+	}
+	return result
+}
+
+func readOriginNodes(data []byte) ([]*kyaml.RNode, error) {
+	return (&kio.ByteReader{Reader: bytes.NewReader(data), OmitReaderAnnotations: true}).Read()
+}
+
+// trackResources records the origins of the documents of the resource file, the documents are
+// followed through the kustomization chain the way kustomize transforms them, so the patches are
+// matched against the names the documents have when the patches are applied
+func (t *originTracker) trackResources(path string, chain []*originKustomization) error {
+	data, err := t.fSys.ReadFile(path)
+	if err != nil {
+		// missing resources are reported by kustomize
+		return nil
+	}
+	nodes, err := readOriginNodes(data)
+	if err != nil {
+		// leave malformed documents to kustomize to report
+		return nil
+	}
+
+	for _, node := range nodes {
+		meta, metaErr := node.GetMeta()
+		if metaErr != nil {
+			continue
+		}
+		origin := Origin{File: t.relative(path), Kustomizations: t.chainPaths(chain)}
+		group, version := resid.ParseGroupVersion(meta.APIVersion)
+		id := resid.NewResIdWithNamespace(resid.NewGvk(group, version, meta.Kind), meta.Name, meta.Namespace)
+		source := id
+		// kustomizations transform the documents of their sources starting from the innermost one
+		for i := len(chain) - 1; i >= 0; i-- {
+			meta.Name, meta.Namespace = id.Name, id.Namespace
+			var patches []string
+			if patches, err = t.matchingPatches(chain[i], meta, source); err != nil {
+				return err
+			}
+			origin.Patches = append(origin.Patches, patches...)
+			id = transformID(chain[i].kustomization, id)
+			meta.Name, meta.Namespace = id.Name, id.Namespace
+			origin.Patches = append(origin.Patches, t.matchingJSONPatches(chain[i], meta, source)...)
+		}
+		t.resources[id] = origin
+	}
+	return nil
+}
+
+// chainPaths returns the paths of kustomization files of the chain
+func (t *originTracker) chainPaths(chain []*originKustomization) []string {
+	paths := make([]string, 0, len(chain))
+	for _, k := range chain {
+		paths = append(paths, t.relative(k.path))
+	}
+	return paths
+}
+
+// prefixSuffixSkipped are the kinds which names are not changed by namePrefix and nameSuffix
+var prefixSuffixSkipped = []resid.Gvk{
+	{Kind: "CustomResourceDefinition"},
+	{Group: "apiregistration.k8s.io", Kind: "APIService"},
+	{Kind: "Namespace"},
+}
+
+// transformID returns the id the document has after namespace, namePrefix and nameSuffix of the
+// kustomization are applied, these transformers run after the patches of the kustomization
+func transformID(k types.Kustomization, id resid.ResId) resid.ResId {
+	if k.Namespace != "" && !id.IsClusterScoped() {
+		id.Namespace = k.Namespace
+	}
+	for _, skipped := range prefixSuffixSkipped {
+		if id.IsSelected(&skipped) {
+			return id
+		}
+	}
+	id.Name = k.NamePrefix + id.Name + k.NameSuffix
+	return id
+}
+
+// annotateBundle adds origin annotations to the documents of the bundle built from the tracked sources
+func (t *originTracker) annotateBundle(bundle Bundle) error {
+	docs, err := bundle.GetAllDocuments()
+	if err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		id := resid.NewResIdWithNamespace(resid.NewGvk(doc.GetGroup(), doc.GetVersion(), doc.GetKind()),
+			doc.GetName(), doc.GetNamespace())
+		if origin, tracked := t.resources[id]; tracked {
+			doc.Annotate(origin.Annotations())
+		}
+	}
+	return nil
+}
+
+// annotateConfig adds origin annotations to every document of the plugin config file, so the plugin
+// can refer to its source in the errors
+func (t *originTracker) annotateConfig(path string, data []byte) ([]byte, error) {
+	chain, tracked := t.configs[filepath.Clean(path)]
+	if !tracked {
+		return data, nil
+	}
+	nodes, err := readOriginNodes(data)
+	if err != nil {
+		// leave malformed documents to kustomize to report
+		return data, nil
+	}
+
+	annotations := Origin{File: t.relative(path), Kustomizations: t.chainPaths(chain)}.Annotations()
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, node := range nodes {
+		// each filter returns the annotation value node, so they can't be chained in a single pipe
+		for _, key := range keys {
+			if err = node.PipeE(kyaml.SetAnnotation(key, annotations[key])); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err = (kio.ByteWriter{Writer: buf}).Write(nodes); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// matchingPatches returns the patches of the kustomization which target the document, source is
+// the id the document has in its source file
+func (t *originTracker) matchingPatches(k *originKustomization, meta kyaml.ResourceMeta,
+	source resid.ResId) ([]string, error) {
+	var result []string
+	for _, smp := range k.kustomization.PatchesStrategicMerge {
+		name, targets, err := t.patchTargets(k, string(smp), "")
+		if err != nil {
+			return nil, err
+		}
+		if patchTargetsMatch(targets, meta) {
+			result = append(result, name)
+		}
+	}
+	for _, patch := range k.kustomization.Patches {
+		if patch.Target != nil {
+			if targetMatches(patch.Target, meta, source) {
+				result = append(result, t.patchName(k, patch.Path))
+			}
+			continue
+		}
+		name, targets, err := t.patchTargets(k, patch.Path, patch.Patch)
+		if err != nil {
+			return nil, err
+		}
+		if patchTargetsMatch(targets, meta) {
+			result = append(result, name)
+		}
+	}
+	return result, nil
+}
+
+// matchingJSONPatches returns the patchesJson6902 of the kustomization which target the document,
+// kustomize applies them after namespace, namePrefix, nameSuffix and commonLabels of the kustomization,
+// so meta must have the transformed name and namespace
+func (t *originTracker) matchingJSONPatches(k *originKustomization, meta kyaml.ResourceMeta,
+	source resid.ResId) []string {
+	if len(k.kustomization.PatchesJson6902) == 0 {
+		return nil
+	}
+	labels := make(map[string]string, len(meta.Labels)+len(k.kustomization.CommonLabels))
+	for _, set := range []map[string]string{meta.Labels, k.kustomization.CommonLabels} {
+		for key, value := range set {
+			labels[key] = value
+		}
+	}
+	meta.Labels = labels
+
+	var result []string
+	for _, patch := range k.kustomization.PatchesJson6902 {
+		if patch.Target != nil && targetMatches(patch.Target, meta, source) {
+			result = append(result, t.patchName(k, patch.Path))
+		}
+	}
+	return result
+}
+
+// patchTargets returns the name and the documents of strategic merge patch, which is either
+// a path to patch file or inline patch
+func (t *originTracker) patchTargets(k *originKustomization, path, inline string) (string, []*kyaml.RNode, error) {
+	if inline == "" && strings.Contains(path, "\n") {
+		inline, path = path, ""
+	}
+	if inline != "" {
+		nodes, err := readOriginNodes([]byte(inline))
+		if err != nil {
+			return "", nil, err
+		}
+		return t.patchName(k, ""), nodes, nil
+	}
+
+	path = filepath.Join(k.dir, path)
+	nodes, cached := t.patches[path]
+	if !cached {
+		data, err := t.fSys.ReadFile(path)
+		if err != nil {
+			return "", nil, err
+		}
+		if nodes, err = readOriginNodes(data); err != nil {
+			return "", nil, err
+		}
+		t.patches[path] = nodes
+	}
+	return t.relative(path), nodes, nil
+}
+
+func (t *originTracker) patchName(k *originKustomization, path string) string {
+	if path == "" {
+		return t.relative(k.path) + "#inline"
+	}
+	return t.relative(filepath.Join(k.dir, path))
+}
+
+// relative returns the path relative to base directory
+func (t *originTracker) relative(path string) string {
+	if t.baseDir == "" {
+		return path
+	}
+	rel, err := filepath.Rel(t.baseDir, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// patchTargetsMatch checks if any of strategic merge patch documents targets the document
+func patchTargetsMatch(targets []*kyaml.RNode, meta kyaml.ResourceMeta) bool {
+	for _, target := range targets {
+		targetMeta, err := target.GetMeta()
+		if err != nil {
+			continue
+		}
+		if targetMeta.Kind == meta.Kind && targetMeta.Name == meta.Name &&
+			(targetMeta.Namespace == "" || targetMeta.Namespace == meta.Namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// targetMatches checks if the document is selected by the target of the patch the same way kustomize does,
+// the name and the namespace may match either the current ones or the ones of the source id
+func targetMatches(target *types.Selector, meta kyaml.ResourceMeta, source resid.ResId) bool {
+	group, version := "", meta.APIVersion
+	if parts := strings.SplitN(meta.APIVersion, "/", 2); len(parts) == 2 {
+		group, version = parts[0], parts[1]
+	}
+	if (target.Group != "" && target.Group != group) ||
+		(target.Version != "" && target.Version != version) ||
+		(target.Kind != "" && target.Kind != meta.Kind) {
+		return false
+	}
+	if !(regexMatches(target.Name, meta.Name) || regexMatches(target.Name, source.Name)) ||
+		!(regexMatches(target.Namespace, meta.Namespace) || regexMatches(target.Namespace, source.Namespace)) {
+		return false
+	}
+	return selectorMatches(target.LabelSelector, meta.Labels) &&
+		selectorMatches(target.AnnotationSelector, meta.Annotations)
+}
+
+func regexMatches(expr, value string) bool {
+	if expr == "" {
+		return true
+	}
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return false
+	}
+	return re.MatchString(value)
+}
+
+func selectorMatches(expr string, values map[string]string) bool {
+	if expr == "" {
+		return true
+	}
+	selector, err := labels.Parse(expr)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(values))
+}
+
+// originFs annotates the documents of tracked plugin configs with their origin when kustomize reads them,
+// the resources are read unchanged and annotated after the build
+type originFs struct {
+	fs.FileSystem
+	tracker *originTracker
+}
+
+// ReadFile reads the file and annotates its documents if the file is a tracked plugin config
+func (o *originFs) ReadFile(path string) ([]byte, error) {
+	data, err := o.FileSystem.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return o.tracker.annotateConfig(path, data)
+}
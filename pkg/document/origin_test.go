@@ -0,0 +1,136 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package document_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"opendev.org/airship/airshipctl/pkg/document"
+	"opendev.org/airship/airshipctl/pkg/fs"
+)
+
+func TestNewBundleWithOrigin(t *testing.T) {
+	baseDir, err := filepath.Abs("testdata/origin")
+	require.NoError(t, err)
+	bundle, err := document.NewBundleWithOrigin(fs.NewDocumentFs(), "testdata/origin/overlay", baseDir)
+	require.NoError(t, err)
+
+	overlayChain := []string{"overlay/kustomization.yaml"}
+	baseChain := []string{"overlay/kustomization.yaml", "base/kustomization.yaml"}
+	tests := []struct {
+		kind     string
+		name     string
+		expected document.Origin
+	}{
+		{
+			kind: "Deployment",
+			name: "app",
+			expected: document.Origin{
+				File:           "base/deployment.yaml",
+				Kustomizations: baseChain,
+				Patches:        []string{"overlay/replicas.yaml"},
+			},
+		},
+		{
+			kind: "ConfigMap",
+			name: "settings",
+			expected: document.Origin{
+				File:           "base/configmaps.yaml",
+				Kustomizations: baseChain,
+				Patches:        []string{"overlay/kustomization.yaml#inline"},
+			},
+		},
+		{
+			kind:     "ConfigMap",
+			name:     "other",
+			expected: document.Origin{File: "base/configmaps.yaml", Kustomizations: baseChain},
+		},
+		{
+			kind:     "Service",
+			name:     "svc",
+			expected: document.Origin{File: "overlay/service.yaml", Kustomizations: overlayChain},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.kind+"/"+tt.name, func(t *testing.T) {
+			doc, err := bundle.SelectOne(document.NewSelector().ByKind(tt.kind).ByName(tt.name))
+			require.NoError(t, err)
+			origin, ok := doc.GetOrigin()
+			require.True(t, ok)
+			assert.Equal(t, tt.expected, origin)
+		})
+	}
+
+	t.Run("patches are applied", func(t *testing.T) {
+		doc, err := bundle.SelectOne(document.NewSelector().ByKind("Deployment").ByName("app"))
+		require.NoError(t, err)
+		replicas, err := doc.GetInt64("spec.replicas")
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), replicas)
+	})
+}
+
+func TestNewBundleWithOriginTransformedNames(t *testing.T) {
+	baseDir, err := filepath.Abs("testdata/origin")
+	require.NoError(t, err)
+	bundle, err := document.NewBundleWithOrigin(fs.NewDocumentFs(), "testdata/origin/site", baseDir)
+	require.NoError(t, err)
+
+	doc, err := bundle.SelectOne(document.NewSelector().ByKind("Deployment").ByName("dev-app").ByNamespace("dev"))
+	require.NoError(t, err)
+	origin, ok := doc.GetOrigin()
+	require.True(t, ok)
+	// the patch of the site targets the name the deployment gets from the prefixed kustomization
+	assert.Equal(t, document.Origin{
+		File:           "base/deployment.yaml",
+		Kustomizations: []string{"site/kustomization.yaml", "prefixed/kustomization.yaml", "base/kustomization.yaml"},
+		Patches:        []string{"site/replicas.yaml"},
+	}, origin)
+	replicas, err := doc.GetInt64("spec.replicas")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), replicas)
+
+	// json patches of the prefixed kustomization target either the prefixed or the source name
+	jsonPatches := map[string]string{"dev-settings": "prefixed/settings.yaml", "dev-other": "prefixed/other.yaml"}
+	for name, patch := range jsonPatches {
+		doc, err = bundle.SelectOne(document.NewSelector().ByKind("ConfigMap").ByName(name))
+		require.NoError(t, err)
+		origin, ok = doc.GetOrigin()
+		require.True(t, ok)
+		assert.Equal(t, "base/configmaps.yaml", origin.File)
+		assert.Equal(t, []string{patch}, origin.Patches)
+		_, err = doc.GetString("data.env")
+		assert.NoError(t, err)
+	}
+}
+
+func TestOriginString(t *testing.T) {
+	origin := document.Origin{
+		File:           "base/deployment.yaml",
+		Kustomizations: []string{"overlay/kustomization.yaml", "base/kustomization.yaml"},
+		Patches:        []string{"overlay/replicas.yaml"},
+	}
+	assert.Equal(t, "base/deployment.yaml (via overlay/kustomization.yaml -> base/kustomization.yaml; "+
+		"patched by overlay/replicas.yaml)", origin.String())
+
+	_, ok := document.OriginFromAnnotations(map[string]string{"other": "value"})
+	assert.False(t, ok)
+}
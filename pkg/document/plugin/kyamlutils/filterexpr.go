@@ -0,0 +1,423 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package kyamlutils
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// filterPlaceholder is the name of the field the extended filter expressions are replaced with
+// before the query is parsed by jsonpath, the index of the expression follows the name
+const filterPlaceholder = "__airshipctl_filter_"
+
+const (
+	opAnd    = "&&"
+	opOr     = "||"
+	opNot    = "!"
+	opMatch  = "=~"
+	opExists = "exists"
+)
+
+type tokenKind int
+
+const (
+	tokOperand tokenKind = iota
+	tokCompare
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// filterExpr is a node of filter expression, jsonpath supports only a single comparison or
+// existence check in a filter, the expressions extend them with regex matching (=~),
+// boolean operators (&&, ||, !) and parentheses
+type filterExpr struct {
+	op string
+	// args are the operands of &&, || and !
+	args []*filterExpr
+	// left and right are the operands of comparison, right is nil for existence check
+	left, right *jsonpath.ListNode
+	// re is the regular expression of =~
+	re *regexp.Regexp
+}
+
+// extractFilters replaces the filters of the query which jsonpath can't parse with
+// existence checks of placeholder fields and returns the parsed filter expressions
+func extractFilters(query string) (string, []*filterExpr, error) {
+	b := &strings.Builder{}
+	var exprs []*filterExpr
+	for i := 0; i < len(query); {
+		if !strings.HasPrefix(query[i:], "[?(") {
+			b.WriteByte(query[i])
+			i++
+			continue
+		}
+		end, err := filterEnd(query, i+len("[?("))
+		if err != nil {
+			return "", nil, err
+		}
+		body := query[i+len("[?(") : end]
+		tokens, err := tokenize(body)
+		if err != nil {
+			return "", nil, err
+		}
+		if isExtended(tokens) {
+			expr, err := parseFilterExpr(tokens)
+			if err != nil {
+				return "", nil, err
+			}
+			fmt.Fprintf(b, "[?(@.%s%d)]", filterPlaceholder, len(exprs))
+			exprs = append(exprs, expr)
+		} else {
+			b.WriteString(query[i : end+len(")]")])
+		}
+		i = end + len(")]")
+	}
+	return b.String(), exprs, nil
+}
+
+// filterEnd returns the position of the parenthesis closing the filter which starts at pos
+func filterEnd(query string, pos int) (int, error) {
+	depth := 1
+	for i := pos; i < len(query); i++ {
+		switch c := query[i]; c {
+		case '\'', '"':
+			end, err := quoteEnd(query, i)
+			if err != nil {
+				return 0, err
+			}
+			i = end
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				if !strings.HasPrefix(query[i:], ")]") {
+					return 0, ErrBadQueryFormat{Msg: "unclosed array expect ]"}
+				}
+				return i, nil
+			}
+		}
+	}
+	return 0, ErrBadQueryFormat{Msg: "unterminated filter"}
+}
+
+// quoteEnd returns the position of the quote closing the string which starts at pos
+func quoteEnd(s string, pos int) (int, error) {
+	for i := pos + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case s[pos]:
+			return i, nil
+		}
+	}
+	return 0, ErrBadQueryFormat{Msg: fmt.Sprintf("unterminated string in %s", s)}
+}
+
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		var tok token
+		var err error
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+			continue
+		case c == '(':
+			tok = token{kind: tokLParen, text: "("}
+		case c == ')':
+			tok = token{kind: tokRParen, text: ")"}
+		case c == '\'' || c == '"':
+			var end int
+			if end, err = quoteEnd(expr, i); err == nil {
+				tok = token{kind: tokOperand, text: expr[i : end+1]}
+			}
+		case strings.ContainsRune("=!<>&|", rune(c)):
+			tok, err = operatorToken(expr[i:])
+		default:
+			tok = token{kind: tokOperand, text: operandText(expr[i:])}
+		}
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, tok)
+		i += len(tok.text)
+	}
+	return tokens, nil
+}
+
+func operatorToken(s string) (token, error) {
+	for _, op := range []string{opAnd, opOr, opMatch, "==", "!=", "<=", ">="} {
+		if strings.HasPrefix(s, op) {
+			kind := tokCompare
+			switch op {
+			case opAnd:
+				kind = tokAnd
+			case opOr:
+				kind = tokOr
+			}
+			return token{kind: kind, text: op}, nil
+		}
+	}
+	switch s[0] {
+	case '!':
+		return token{kind: tokNot, text: opNot}, nil
+	case '<', '>':
+		return token{kind: tokCompare, text: s[:1]}, nil
+	}
+	return token{}, ErrLookup{Msg: fmt.Sprintf("unrecognized filter operator %s", s[:1])}
+}
+
+// operandText returns the path or the literal s starts with, brackets of the path may contain
+// any characters, e.g. @.metadata.labels['app.kubernetes.io/name']
+func operandText(s string) string {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\'' || c == '"':
+			if end, err := quoteEnd(s, i); err == nil {
+				i = end
+			}
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case depth == 0 && (unicode.IsSpace(rune(c)) || strings.ContainsRune("=!<>&|()", rune(c))):
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// isExtended checks if the filter uses the syntax jsonpath doesn't support
+func isExtended(tokens []token) bool {
+	for _, tok := range tokens {
+		if (tok.kind != tokOperand && tok.kind != tokCompare) || tok.text == opMatch {
+			return true
+		}
+	}
+	return false
+}
+
+// filterParser is recursive descent parser of filter expressions
+type filterParser struct {
+	tokens []token
+	pos    int
+}
+
+func parseFilterExpr(tokens []token) (*filterExpr, error) {
+	p := &filterParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, ErrBadQueryFormat{Msg: fmt.Sprintf("unexpected %s in filter expression", p.tokens[p.pos].text)}
+	}
+	return expr, nil
+}
+
+func (p *filterParser) peek(kind tokenKind) bool {
+	return p.pos < len(p.tokens) && p.tokens[p.pos].kind == kind
+}
+
+func (p *filterParser) parseOr() (*filterExpr, error) {
+	return p.parseBinary(tokOr, opOr, p.parseAnd)
+}
+
+func (p *filterParser) parseAnd() (*filterExpr, error) {
+	return p.parseBinary(tokAnd, opAnd, p.parseUnary)
+}
+
+func (p *filterParser) parseBinary(kind tokenKind, op string,
+	parseArg func() (*filterExpr, error)) (*filterExpr, error) {
+	arg, err := parseArg()
+	if err != nil {
+		return nil, err
+	}
+	expr := &filterExpr{op: op, args: []*filterExpr{arg}}
+	for p.peek(kind) {
+		p.pos++
+		if arg, err = parseArg(); err != nil {
+			return nil, err
+		}
+		expr.args = append(expr.args, arg)
+	}
+	if len(expr.args) == 1 {
+		return arg, nil
+	}
+	return expr, nil
+}
+
+func (p *filterParser) parseUnary() (*filterExpr, error) {
+	switch {
+	case p.peek(tokNot):
+		p.pos++
+		arg, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &filterExpr{op: opNot, args: []*filterExpr{arg}}, nil
+	case p.peek(tokLParen):
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peek(tokRParen) {
+			return nil, ErrBadQueryFormat{Msg: "unclosed parenthesis in filter expression"}
+		}
+		p.pos++
+		return expr, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (*filterExpr, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if !p.peek(tokCompare) {
+		return &filterExpr{op: opExists, left: left}, nil
+	}
+	op := p.tokens[p.pos].text
+	p.pos++
+	rightText := ""
+	if p.peek(tokOperand) {
+		rightText = p.tokens[p.pos].text
+	}
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	expr := &filterExpr{op: op, left: left, right: right}
+	if op == opMatch {
+		text, ok := right.Nodes[0].(*jsonpath.TextNode)
+		if len(right.Nodes) != 1 || !ok {
+			return nil, ErrBadQueryFormat{Msg: fmt.Sprintf("regular expression must be a string, got %s", rightText)}
+		}
+		if expr.re, err = regexp.Compile(text.Text); err != nil {
+			return nil, ErrBadQueryFormat{Msg: fmt.Sprintf("invalid regular expression %s: %v", rightText, err)}
+		}
+	}
+	return expr, nil
+}
+
+func (p *filterParser) parseOperand() (*jsonpath.ListNode, error) {
+	if !p.peek(tokOperand) {
+		if p.pos < len(p.tokens) {
+			return nil, ErrBadQueryFormat{Msg: fmt.Sprintf("unexpected %s in filter expression", p.tokens[p.pos].text)}
+		}
+		return nil, ErrBadQueryFormat{Msg: "unexpected end of filter expression"}
+	}
+	text := p.tokens[p.pos].text
+	p.pos++
+	jp, err := jsonpath.Parse("operand", "{"+text+"}")
+	if err != nil {
+		return nil, err
+	}
+	list, ok := jp.Root.Nodes[0].(*jsonpath.ListNode)
+	if !ok || len(list.Nodes) == 0 {
+		return nil, ErrBadQueryFormat{Msg: fmt.Sprintf("invalid operand %s in filter expression", text)}
+	}
+	return list, nil
+}
+
+// extendedFilter returns the filter expression the filter node is placeholder of
+func (l JSONPathFilter) extendedFilter(field *jsonpath.FilterNode) *filterExpr {
+	if field.Operator != opExists || len(field.Left.Nodes) != 1 {
+		return nil
+	}
+	placeholder, ok := field.Left.Nodes[0].(*jsonpath.FieldNode)
+	if !ok || !strings.HasPrefix(placeholder.Value, filterPlaceholder) {
+		return nil
+	}
+	i, err := strconv.Atoi(strings.TrimPrefix(placeholder.Value, filterPlaceholder))
+	if err != nil || i >= len(l.filters) {
+		return nil
+	}
+	return l.filters[i]
+}
+
+// evalFilter checks if the array item passes the filter expression
+func (l JSONPathFilter) evalFilter(expr *filterExpr, item *yaml.RNode) (bool, error) {
+	switch expr.op {
+	case opAnd, opOr:
+		for _, arg := range expr.args {
+			pass, err := l.evalFilter(arg, item)
+			if err != nil {
+				return false, err
+			}
+			if pass == (expr.op == opOr) {
+				return pass, nil
+			}
+		}
+		return expr.op == opAnd, nil
+	case opNot:
+		pass, err := l.evalFilter(expr.args[0], item)
+		return !pass, err
+	case opExists:
+		nodes, err := l.walk([]*yaml.RNode{item}, expr.left)
+		return len(nodes) > 0, err
+	}
+
+	left, err := l.operand(item, expr.left)
+	if left == nil || err != nil {
+		return false, err
+	}
+	if expr.re != nil {
+		if left.YNode().Kind != yaml.ScalarNode {
+			return false, ErrNotScalar{Node: left.YNode()}
+		}
+		return expr.re.MatchString(left.YNode().Value), nil
+	}
+	right, err := l.operand(item, expr.right)
+	if right == nil || err != nil {
+		return false, err
+	}
+	return l.compare(expr.op, left, right)
+}
+
+// operand returns the value of the operand of comparison, nil if it doesn't exist
+func (l JSONPathFilter) operand(item *yaml.RNode, operand *jsonpath.ListNode) (*yaml.RNode, error) {
+	nodes, err := l.walk([]*yaml.RNode{item}, operand)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) > 1 {
+		return nil, ErrBadQueryFormat{Msg: "can only compare one element at a time"}
+	}
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+	return nodes[0], nil
+}
@@ -33,6 +33,12 @@ type JSONPathFilter struct {
 	Mutator func([]*yaml.RNode) error
 	// Create empty struct if path element does not exist
 	Create bool
+	// Pointers makes the filter return the sequence of RFC 6901 JSON pointers
+	// to the matched nodes instead of the nodes themselves
+	Pointers bool `yaml:"pointers,omitempty"`
+
+	// filters are the filter expressions jsonpath can't parse, see extractFilters
+	filters []*filterExpr
 }
 
 type boundaries struct {
@@ -45,6 +51,10 @@ func (l JSONPathFilter) Filter(rn *yaml.RNode) (*yaml.RNode, error) {
 	if err != nil {
 		return nil, err
 	}
+	query, l.filters, err = extractFilters(query)
+	if err != nil {
+		return nil, err
+	}
 	jp, err := jsonpath.Parse("parser", query)
 	if err != nil {
 		return nil, err
@@ -70,6 +80,10 @@ func (l JSONPathFilter) Filter(rn *yaml.RNode) (*yaml.RNode, error) {
 		}
 	}
 
+	if l.Pointers {
+		return pointersRNode(rn, rns)
+	}
+
 	// Return first element if filtered list contins only one RNode
 	// otherwise create a SequenceNode
 	if len(rns) == 1 {
@@ -84,8 +98,15 @@ func (l JSONPathFilter) Filter(rn *yaml.RNode) (*yaml.RNode, error) {
 
 func (l JSONPathFilter) walk(nodes []*yaml.RNode, fieldList *jsonpath.ListNode) (res []*yaml.RNode, err error) {
 	res = nodes
-	for _, field := range fieldList.Nodes {
-		res, err = l.getByField(res, field)
+	for i := 0; i < len(fieldList.Nodes); i++ {
+		field := fieldList.Nodes[i]
+		// Recursive descent e.g. ..name applies the next field to all the descendants
+		if _, ok := field.(*jsonpath.RecursiveNode); ok && i+1 < len(fieldList.Nodes) {
+			i++
+			res, err = l.doRecursive(res, fieldList.Nodes[i])
+		} else {
+			res, err = l.getByField(res, field)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -140,6 +161,9 @@ func (l JSONPathFilter) getByField(rns []*yaml.RNode, field jsonpath.Node) ([]*y
 	// Return all elements e.g. spec.containers[*]
 	case *jsonpath.WildcardNode:
 		return l.doWildcard(rns)
+	// Trailing recursive descent returns all the nodes and their descendants
+	case *jsonpath.RecursiveNode:
+		return descendants(rns), nil
 	}
 	return nil, ErrBadQueryFormat{Msg: fmt.Sprintf("unsupported jsonpath expression %s", l.Path)}
 }
@@ -238,6 +262,13 @@ func (l JSONPathFilter) doFilter(rns []*yaml.RNode, field *jsonpath.FilterNode)
 	// NOTE this cond block was adapted from k8s.io/client-go/util/jsonpath
 	// see evalFilter from from jsonpath.go
 	result := []*yaml.RNode{}
+	// Operands of filter expression must not create missing fields
+	probe := l
+	probe.Create = false
+	expr := l.extendedFilter(field)
+	if expr == nil {
+		expr = &filterExpr{op: field.Operator, left: field.Left, right: field.Right}
+	}
 	for _, rn := range rns {
 		// Elements() will return an error if rn is not SequenceNode
 		// therefore no need to check it explicitly
@@ -245,38 +276,8 @@ func (l JSONPathFilter) doFilter(rns []*yaml.RNode, field *jsonpath.FilterNode)
 		if err != nil {
 			return nil, err
 		}
-		var left, right []*yaml.RNode
 		for _, item := range items {
-			pass := false
-			// Evaluate left part of the filter expression against each
-			// array item
-			left, err = l.walk([]*yaml.RNode{item}, field.Left)
-			if err != nil {
-				return nil, err
-			}
-
-			if len(left) == 0 {
-				continue
-			}
-			if len(left) > 1 {
-				return nil, ErrBadQueryFormat{Msg: "can only compare one element at a time"}
-			}
-
-			// Evaluate right part of the filter expression against each
-			// array item
-			right, err = l.walk([]*yaml.RNode{item}, field.Right)
-			if err != nil {
-				return nil, err
-			}
-
-			if len(right) == 0 {
-				continue
-			}
-			if len(right) > 1 {
-				return nil, ErrBadQueryFormat{Msg: "can only compare one element at a time"}
-			}
-
-			pass, err = l.compare(field.Operator, left[0], right[0])
+			pass, err := probe.evalFilter(expr, item)
 			if err != nil {
 				return nil, err
 			}
@@ -339,6 +340,69 @@ func (l JSONPathFilter) doWildcard(rns []*yaml.RNode) ([]*yaml.RNode, error) {
 	return result, nil
 }
 
+// doRecursive applies the field to the nodes and all their descendants of the matching kind
+func (l JSONPathFilter) doRecursive(rns []*yaml.RNode, field jsonpath.Node) ([]*yaml.RNode, error) {
+	kind := yaml.Kind(0)
+	switch field.(type) {
+	case *jsonpath.FieldNode:
+		kind = yaml.MappingNode
+	case *jsonpath.ArrayNode, *jsonpath.FilterNode:
+		kind = yaml.SequenceNode
+	}
+	candidates := []*yaml.RNode{}
+	for _, rn := range descendants(rns) {
+		if kind == 0 || rn.YNode().Kind == kind {
+			candidates = append(candidates, rn)
+		}
+	}
+	if _, ok := field.(*jsonpath.FilterNode); ok {
+		candidates = mappingElements(candidates)
+	}
+	// Missing fields can't be created in all the descendants
+	probe := l
+	probe.Create = false
+	return probe.getByField(candidates, field)
+}
+
+// mappingElements returns the sequences reduced to their mapping elements, so filters
+// applied to all the descendants skip the sequences of scalars and of sequences
+func mappingElements(rns []*yaml.RNode) []*yaml.RNode {
+	result := make([]*yaml.RNode, 0, len(rns))
+	for _, rn := range rns {
+		seq := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range rn.YNode().Content {
+			if item.Kind == yaml.MappingNode {
+				seq.Content = append(seq.Content, item)
+			}
+		}
+		result = append(result, yaml.NewRNode(seq))
+	}
+	return result
+}
+
+// descendants returns the nodes followed by all their descendants
+func descendants(rns []*yaml.RNode) []*yaml.RNode {
+	result := []*yaml.RNode{}
+	var visit func(node *yaml.Node)
+	visit = func(node *yaml.Node) {
+		result = append(result, yaml.NewRNode(node))
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 1; i < len(node.Content); i += 2 {
+				visit(node.Content[i])
+			}
+		case yaml.SequenceNode:
+			for _, item := range node.Content {
+				visit(item)
+			}
+		}
+	}
+	for _, rn := range rns {
+		visit(rn.YNode())
+	}
+	return result
+}
+
 func (l JSONPathFilter) updateMapRNodes(rns []*yaml.RNode, name string, node *yaml.RNode) error {
 	for _, rn := range rns {
 		_, err := rn.Pipe(yaml.SetField(name, node))
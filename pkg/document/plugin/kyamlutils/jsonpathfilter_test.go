@@ -341,6 +341,53 @@ value:
   someKey1: valO21
   someKey2: valO22`[1:],
 		},
+		{
+			name:     "Filter exists",
+			query:    "{.listOfComplexObjects[?(@.value1)].name}",
+			expected: "o1",
+		},
+		{
+			name:     "Filter not exists",
+			query:    "{.listOfComplexObjects[?(!@.value1)].name}",
+			expected: "o2",
+		},
+		{
+			name:  "Filter regex",
+			query: "{.listOfObjects[?(@.name =~ '^obj[12]$')].value}",
+			expected: `
+- 10
+- 20`[1:],
+		},
+		{
+			name:     "Filter and",
+			query:    "{.listOfObjects[?(@.name =~ '^obj' && @.value > 15 && @.value < 25)].name}",
+			expected: "obj2",
+		},
+		{
+			name:     "Filter or with parentheses",
+			query:    "{.listOfObjects[?((@.name == 'obj1' || @.name == 'obj3') && !(@.value == 30))].name}",
+			expected: "obj1",
+		},
+		{
+			name:        "Filter regex not a string",
+			query:       "{.listOfObjects[?(@.name =~ @.value)]}",
+			expectedErr: "regular expression must be a string, got @.value",
+		},
+		{
+			name:        "Filter invalid regex",
+			query:       "{.listOfObjects[?(@.name =~ '[a-')]}",
+			expectedErr: "invalid regular expression '[a-': error parsing regexp: missing closing ]: `[a-`",
+		},
+		{
+			name:        "Filter unclosed parenthesis",
+			query:       "{.listOfObjects[?((@.name == 'obj1' || @.value == 10)]}",
+			expectedErr: "unterminated filter",
+		},
+		{
+			name:        "Filter missing operand",
+			query:       "{.listOfObjects[?(@.name == 'obj1' &&)]}",
+			expectedErr: "unexpected end of filter expression",
+		},
 		// Wildcard cases
 		{
 			name:  "Get map by wildcard",
@@ -359,6 +406,27 @@ value:
 - string2
 - string3`[1:],
 		},
+		// Recursive descent cases
+		{
+			name:  "Recursive descent field",
+			query: "{..someKey1}",
+			expected: `
+- valO11
+- valO11
+- valO21`[1:],
+		},
+		{
+			name:  "Recursive descent filter",
+			query: "{..[?(@.val == 'val1')].name}",
+			expected: `
+- o1
+- o1`[1:],
+		},
+		{
+			name:     "Recursive descent array element",
+			query:    "{.spec..array[1]}",
+			expected: "i2",
+		},
 		// v1 queries cases
 		{
 			name:  "Array element v1",
@@ -475,3 +543,109 @@ metadata:
 		})
 	}
 }
+
+func TestFilterPointers(t *testing.T) {
+	obj, err := yaml.Parse(`apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+  annotations:
+    example.com/a~b: value
+spec:
+  containers:
+  - name: app
+    image: quay.io/app:v1
+  - name: sidecar
+    image: docker.io/proxy:v2
+  initContainers:
+  - name: init
+    image: quay.io/init:v1`)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name        string
+		query       string
+		expectedErr string
+		expected    []string
+	}{
+		{
+			name:     "Field",
+			query:    "{.metadata.annotations.example\\.com/a~b}",
+			expected: []string{"/metadata/annotations/example.com~1a~0b"},
+		},
+		{
+			name:     "Filter",
+			query:    "{.spec.containers[?(@.image =~ '^quay.io/')].image}",
+			expected: []string{"/spec/containers/0/image"},
+		},
+		{
+			name:  "Recursive descent",
+			query: "{..[?(@.image =~ '^quay.io/')].image}",
+			expected: []string{
+				"/spec/containers/0/image",
+				"/spec/initContainers/0/image",
+			},
+		},
+		{
+			name:        "Literal",
+			query:       "{10}",
+			expectedErr: "matched node 10 is not part of the document",
+		},
+	}
+	for _, tc := range testCases {
+		tt := tc
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := obj.Pipe(kyamlutils.JSONPathFilter{Path: tt.query, Pointers: true})
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			pointers := []string{}
+			for _, pointer := range node.YNode().Content {
+				pointers = append(pointers, pointer.Value)
+			}
+			assert.Equal(t, tt.expected, pointers)
+		})
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	obj, err := yaml.Parse(`spec:
+  containers:
+  - name: app
+    image: quay.io/app:v1
+  - name: sidecar
+    image: docker.io/proxy:v2
+  - name: logger
+    image: docker.io/logger:v1`)
+	require.NoError(t, err)
+
+	operations, err := kyamlutils.JSONPatch(obj, "{.spec.containers[?(@.image =~ '^quay.io/')].image}",
+		"replace", "registry.local/app:v1")
+	require.NoError(t, err)
+	assert.Equal(t, []kyamlutils.JSONPatchOperation{
+		{Op: "replace", Path: "/spec/containers/0/image", Value: "registry.local/app:v1"},
+	}, operations)
+
+	operations, err = kyamlutils.JSONPatch(obj, "{.spec.containers[?(@.name != 'app')]}", "remove", "ignored")
+	require.NoError(t, err)
+	assert.Equal(t, []kyamlutils.JSONPatchOperation{
+		{Op: "remove", Path: "/spec/containers/2"},
+		{Op: "remove", Path: "/spec/containers/1"},
+	}, operations)
+
+	operations, err = kyamlutils.JSONPatch(obj, "{..[?(@.image =~ '^docker.io/')]}", "remove", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []kyamlutils.JSONPatchOperation{
+		{Op: "remove", Path: "/spec/containers/2"},
+		{Op: "remove", Path: "/spec/containers/1"},
+	}, operations)
+
+	operations, err = kyamlutils.JSONPatch(obj, "{.spec.volumes}", "add", nil)
+	require.NoError(t, err)
+	assert.Empty(t, operations)
+
+	_, err = kyamlutils.JSONPatch(obj, "{.spec}", "move", nil)
+	assert.EqualError(t, err, "unsupported JSON Patch operation move")
+}
@@ -0,0 +1,110 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package kyamlutils
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// JSONPatchOperation is RFC 6902 JSON Patch operation
+type JSONPatchOperation struct {
+	Op    string      `json:"op" yaml:"op"`
+	Path  string      `json:"path" yaml:"path"`
+	Value interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// JSONPatch returns JSON Patch operations which apply op with the value to every node of rn
+// matched by JSON path, op is one of add, replace, test or remove, the value of remove is ignored.
+// Only the existing nodes are matched, so add never creates a field: it replaces the value of
+// the matched field or inserts the value before the matched item of a sequence. The remove
+// operations are returned in reverse document order, so removing an item of a sequence doesn't
+// shift the indices of the items removed after it
+func JSONPatch(rn *yaml.RNode, path, op string, value interface{}) ([]JSONPatchOperation, error) {
+	switch op {
+	case "add", "replace", "test":
+	case "remove":
+		value = nil
+	default:
+		return nil, ErrBadQueryFormat{Msg: fmt.Sprintf("unsupported JSON Patch operation %s", op)}
+	}
+	node, err := rn.Pipe(JSONPathFilter{Path: path, Pointers: true})
+	if err != nil || node == nil {
+		return nil, err
+	}
+	operations := []JSONPatchOperation{}
+	for _, pointer := range node.YNode().Content {
+		operations = append(operations, JSONPatchOperation{Op: op, Path: pointer.Value, Value: value})
+	}
+	if op == "remove" {
+		order := map[string]int{}
+		collectPointers(rn.YNode(), "", map[*yaml.Node]string{}, order)
+		sort.SliceStable(operations, func(i, j int) bool {
+			return order[operations[i].Path] > order[operations[j].Path]
+		})
+	}
+	return operations, nil
+}
+
+// pointersRNode returns the sequence of JSON pointers to the nodes relative to root
+func pointersRNode(root *yaml.RNode, rns []*yaml.RNode) (*yaml.RNode, error) {
+	pointers := map[*yaml.Node]string{}
+	collectPointers(root.YNode(), "", pointers, map[string]int{})
+	seq := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, rn := range rns {
+		pointer, found := pointers[rn.YNode()]
+		if !found {
+			return nil, ErrLookup{Msg: fmt.Sprintf("matched node %s is not part of the document",
+				strings.TrimSpace(rn.MustString()))}
+		}
+		seq.Content = append(seq.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: pointer})
+	}
+	return yaml.NewRNode(seq), nil
+}
+
+// collectPointers maps the node and all its descendants to their JSON pointers, and the pointers
+// to their position in the document
+func collectPointers(node *yaml.Node, pointer string, pointers map[*yaml.Node]string, order map[string]int) {
+	if _, found := pointers[node]; found {
+		return
+	}
+	pointers[node] = pointer
+	if _, found := order[pointer]; !found {
+		order[pointer] = len(order)
+	}
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, item := range node.Content {
+			collectPointers(item, pointer, pointers, order)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			collectPointers(node.Content[i+1], pointer+"/"+escapePointerToken(node.Content[i].Value), pointers, order)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			collectPointers(item, pointer+"/"+strconv.Itoa(i), pointers, order)
+		}
+	}
+}
+
+// escapePointerToken escapes ~ and / in the reference token as RFC 6901 requires
+func escapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
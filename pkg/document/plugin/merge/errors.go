@@ -0,0 +1,93 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package merge
+
+import (
+	"fmt"
+	"strings"
+
+	airshipv1 "opendev.org/airship/airshipctl/pkg/api/v1alpha1"
+)
+
+// ErrBadConfiguration returned in case of plugin misconfiguration
+type ErrBadConfiguration struct {
+	Msg string
+}
+
+func (e ErrBadConfiguration) Error() string {
+	return e.Msg
+}
+
+// ErrTargetNotFound returned if the base document of the merge does not exist
+type ErrTargetNotFound struct {
+	ObjRef airshipv1.Target
+}
+
+func (e ErrTargetNotFound) Error() string {
+	return fmt.Sprintf("failed to find merge target identified by %s",
+		printFields(e.ObjRef.Gvk, e.ObjRef.Name, e.ObjRef.Namespace))
+}
+
+// ErrMultipleTargets returned if more than one document matches the merge target
+type ErrMultipleTargets struct {
+	ObjRef airshipv1.Target
+}
+
+func (e ErrMultipleTargets) Error() string {
+	return fmt.Sprintf("found more than one merge target identified by %s",
+		printFields(e.ObjRef.Gvk, e.ObjRef.Name, e.ObjRef.Namespace))
+}
+
+// ErrSourceNotFound returned if no override document matches the merge source
+type ErrSourceNotFound struct {
+	ObjRef airshipv1.Selector
+}
+
+func (e ErrSourceNotFound) Error() string {
+	fields := printFields(e.ObjRef.Gvk, e.ObjRef.Name, e.ObjRef.Namespace)
+	if e.ObjRef.LabelSelector != "" {
+		fields = strings.TrimSpace(fields + " labelSelector: " + e.ObjRef.LabelSelector)
+	}
+	return fmt.Sprintf("failed to find any merge sources identified by %s", fields)
+}
+
+// ErrKindMismatch returned if the override document is not of the kind of the base document
+type ErrKindMismatch struct {
+	Target     string
+	TargetKind string
+	Source     string
+	SourceKind string
+}
+
+func (e ErrKindMismatch) Error() string {
+	return fmt.Sprintf("can't merge %s %q onto %s %q, documents must be of the same kind",
+		e.SourceKind, e.Source, e.TargetKind, e.Target)
+}
+
+func printFields(gvk airshipv1.Gvk, name, namespace string) string {
+	var res []string
+	for _, field := range [][2]string{
+		{"Group", gvk.Group},
+		{"Version", gvk.Version},
+		{"Kind", gvk.Kind},
+		{"Name", name},
+		{"Namespace", namespace},
+	} {
+		if field[1] != "" {
+			res = append(res, fmt.Sprintf("%s: %s", field[0], field[1]))
+		}
+	}
+	return strings.Join(res, " ")
+}
@@ -0,0 +1,307 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package merge
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+
+	airshipv1 "opendev.org/airship/airshipctl/pkg/api/v1alpha1"
+	"opendev.org/airship/airshipctl/pkg/document/plugin/kyamlutils"
+)
+
+// headerFields are the top level fields of override documents which are not merged
+var headerFields = map[string]bool{
+	yaml.APIVersionField: true,
+	yaml.KindField:       true,
+	yaml.MetadataField:   true,
+}
+
+var _ kio.Filter = &plugin{}
+
+type plugin struct {
+	*airshipv1.MergeTransformer
+}
+
+// Process is the KRM function entrypoint, it runs the plugin configured
+// by the function config against the items of resource list
+func Process(rl *framework.ResourceList) error {
+	cfg, err := rl.FunctionConfig.Map()
+	if err != nil {
+		return err
+	}
+	fltr, err := New(cfg)
+	if err != nil {
+		return err
+	}
+	rl.Items, err = fltr.Filter(rl.Items)
+	return err
+}
+
+// New creates new instance of the plugin
+func New(obj map[string]interface{}) (kio.Filter, error) {
+	cfg := &airshipv1.MergeTransformer{}
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj, cfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range cfg.Merges {
+		if err = validateMerge(m); err != nil {
+			return nil, err
+		}
+	}
+	return &plugin{MergeTransformer: cfg}, nil
+}
+
+func validateMerge(m airshipv1.Merge) error {
+	if m.Target.Name == "" {
+		return ErrBadConfiguration{Msg: "target name must be specified in one merge"}
+	}
+	if len(m.Sources) == 0 {
+		return ErrBadConfiguration{Msg: fmt.Sprintf("no sources are specified for merge target %q", m.Target.Name)}
+	}
+	for _, lk := range m.ListMergeKeys {
+		if lk.Path == "" || lk.Key == "" {
+			return ErrBadConfiguration{Msg: "both path and key must be specified for every list merge key"}
+		}
+	}
+	return nil
+}
+
+func (p *plugin) Filter(items []*yaml.RNode) ([]*yaml.RNode, error) {
+	for _, m := range p.Merges {
+		target, err := selectTarget(items, m.Target)
+		if err != nil {
+			return nil, err
+		}
+		sources, err := selectSources(items, m.Sources, target)
+		if err != nil {
+			return nil, err
+		}
+		mrg := merger{listMergeKeys: m.ListMergeKeys}
+		for _, source := range sources {
+			mrg.mergeMap(target.YNode(), source.YNode(), "")
+		}
+		if m.RemoveSources {
+			items = removeDocuments(items, sources)
+		}
+	}
+	return items, nil
+}
+
+func selectTarget(items []*yaml.RNode, ref airshipv1.Target) (*yaml.RNode, error) {
+	targets, err := kyamlutils.DocumentSelector{}.
+		ByAPIVersion(ref.APIVersion).
+		ByGVK(ref.Group, ref.Version, ref.Kind).
+		ByName(ref.Name).
+		ByNamespace(ref.Namespace).
+		Filter(items)
+	if err != nil {
+		return nil, err
+	}
+	switch len(targets) {
+	case 0:
+		return nil, ErrTargetNotFound{ObjRef: ref}
+	case 1:
+		return targets[0], nil
+	default:
+		return nil, ErrMultipleTargets{ObjRef: ref}
+	}
+}
+
+// selectSources returns the override documents in the order they are merged,
+// the documents matching several selectors are merged once
+func selectSources(items []*yaml.RNode, selectors []airshipv1.Selector, target *yaml.RNode) ([]*yaml.RNode, error) {
+	selected := map[*yaml.Node]bool{target.YNode(): true}
+	result := []*yaml.RNode{}
+	for _, ref := range selectors {
+		kind := ref.Kind
+		if kind == "" {
+			kind = target.GetKind()
+		}
+		sources, err := kyamlutils.DocumentSelector{}.
+			ByGVK(ref.Group, ref.Version, kind).
+			ByName(ref.Name).
+			ByNamespace(ref.Namespace).
+			ByLabel(ref.LabelSelector).
+			Filter(items)
+		if err != nil {
+			return nil, err
+		}
+		if len(sources) == 0 {
+			return nil, ErrSourceNotFound{ObjRef: ref}
+		}
+		for _, source := range sources {
+			if source.GetKind() != target.GetKind() {
+				return nil, ErrKindMismatch{
+					Target:     target.GetName(),
+					TargetKind: target.GetKind(),
+					Source:     source.GetName(),
+					SourceKind: source.GetKind(),
+				}
+			}
+			if !selected[source.YNode()] {
+				selected[source.YNode()] = true
+				result = append(result, source)
+			}
+		}
+	}
+	return result, nil
+}
+
+func removeDocuments(items []*yaml.RNode, docs []*yaml.RNode) []*yaml.RNode {
+	removed := map[*yaml.Node]bool{}
+	for _, doc := range docs {
+		removed[doc.YNode()] = true
+	}
+	result := make([]*yaml.RNode, 0, len(items))
+	for _, item := range items {
+		if !removed[item.YNode()] {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// merger deep-merges override documents onto base document
+type merger struct {
+	listMergeKeys []airshipv1.ListMergeKey
+}
+
+// mergeNode merges src into dst, maps are merged key by key, lists having merge key
+// are merged element by element and any other value of src replaces dst
+func (m merger) mergeNode(dst, src *yaml.Node, path string) {
+	switch {
+	case dst.Kind == yaml.MappingNode && src.Kind == yaml.MappingNode:
+		m.mergeMap(dst, src, path)
+	case dst.Kind == yaml.SequenceNode && src.Kind == yaml.SequenceNode && m.listMergeKey(path) != "":
+		m.mergeList(dst, src, path)
+	default:
+		*dst = *copyNode(src)
+	}
+}
+
+// mergeMap merges the fields of src into dst, the fields set to null in src are removed from dst,
+// the header fields of the documents are not merged
+func (m merger) mergeMap(dst, src *yaml.Node, path string) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		if path == "" && headerFields[key.Value] {
+			continue
+		}
+		j := fieldIndex(dst, key.Value)
+		switch {
+		case value.Tag == yaml.NodeTagNull:
+			if j >= 0 {
+				dst.Content = append(dst.Content[:j], dst.Content[j+2:]...)
+			}
+		case j >= 0:
+			m.mergeNode(dst.Content[j+1], value, joinPath(path, key.Value))
+		default:
+			dst.Content = append(dst.Content, copyNode(key), copyNode(value))
+		}
+	}
+}
+
+// mergeList merges the elements of src into the elements of dst with the same key,
+// the elements of src which don't have a match are appended to dst
+func (m merger) mergeList(dst, src *yaml.Node, path string) {
+	key := m.listMergeKey(path)
+	for _, item := range src.Content {
+		if i := elementIndex(dst, item, key); i >= 0 {
+			m.mergeNode(dst.Content[i], item, path)
+			continue
+		}
+		dst.Content = append(dst.Content, copyNode(item))
+	}
+}
+
+// listMergeKey returns the key of the list field at path, or empty string
+// if the list must be replaced
+func (m merger) listMergeKey(path string) string {
+	for _, lk := range m.listMergeKeys {
+		if pathMatches(lk.Path, path) {
+			return lk.Key
+		}
+	}
+	return ""
+}
+
+func pathMatches(pattern, path string) bool {
+	patternFields := strings.Split(pattern, ".")
+	pathFields := strings.Split(path, ".")
+	if len(patternFields) != len(pathFields) {
+		return false
+	}
+	for i, field := range patternFields {
+		if field != "*" && field != pathFields[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func joinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+// fieldIndex returns the index of the key of the map field, or -1 if there is no such field
+func fieldIndex(node *yaml.Node, name string) int {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// elementIndex returns the index of the element of list with the same key as item,
+// or -1 if there is no such element
+func elementIndex(list, item *yaml.Node, key string) int {
+	itemKey := keyValue(item, key)
+	if itemKey == nil {
+		return -1
+	}
+	for i, element := range list.Content {
+		if elementKey := keyValue(element, key); elementKey != nil && elementKey.Value == itemKey.Value {
+			return i
+		}
+	}
+	return -1
+}
+
+// keyValue returns the scalar value of the key field of the list element
+func keyValue(element *yaml.Node, key string) *yaml.Node {
+	if element.Kind != yaml.MappingNode {
+		return nil
+	}
+	i := fieldIndex(element, key)
+	if i < 0 || element.Content[i+1].Kind != yaml.ScalarNode {
+		return nil
+	}
+	return element.Content[i+1]
+}
+
+func copyNode(node *yaml.Node) *yaml.Node {
+	return yaml.NewRNode(node).Copy().YNode()
+}
@@ -0,0 +1,445 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package merge_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/yaml"
+
+	"opendev.org/airship/airshipctl/pkg/document/plugin/merge"
+)
+
+const versionsCatalogues = `
+apiVersion: airshipit.org/v1alpha1
+kind: VersionsCatalogue
+metadata:
+  name: versions-airshipctl
+spec:
+  images:
+    cluster-api:
+      manager:
+        image: quay.io/cluster-api/manager:v0.3.7
+      rbac_proxy:
+        image: gcr.io/kubebuilder/kube-rbac-proxy:v0.4.1
+  kubernetes: v1.19.14
+---
+apiVersion: airshipit.org/v1alpha1
+kind: VersionsCatalogue
+metadata:
+  name: versions-site
+  labels:
+    airshipit.org/override: site
+spec:
+  images:
+    cluster-api:
+      manager:
+        image: registry.local/cluster-api/manager:v0.3.7
+  kubernetes: v1.20.2
+`
+
+const networkCatalogues = `
+apiVersion: airshipit.org/v1alpha1
+kind: NetworkCatalogue
+metadata:
+  name: networking
+spec:
+  commonHostNetworking:
+    links:
+    - id: oam-ipv4
+      name: enp0s3
+      mtu: "1500"
+    - id: pxe-ipv4
+      name: enp0s4
+      mtu: "1500"
+    networks:
+    - id: oam-ipv4
+      link: oam-ipv4
+      routes:
+      - network: 0.0.0.0
+        gateway: 10.23.25.1
+    - id: pxe-ipv4
+      link: pxe-ipv4
+  ntp:
+    enabled: true
+    servers:
+    - 0.pool.ntp.org
+    - 1.pool.ntp.org
+---
+apiVersion: airshipit.org/v1alpha1
+kind: NetworkCatalogue
+metadata:
+  name: networking-site
+spec:
+  commonHostNetworking:
+    links:
+    - id: oam-ipv4
+      mtu: "9100"
+    - id: storage-ipv4
+      name: enp0s5
+    networks:
+    - id: oam-ipv4
+      routes:
+      - network: 10.0.0.0
+        gateway: 10.23.25.2
+  ntp:
+    enabled: null
+    servers:
+    - ntp.site.local
+`
+
+var testCases = []struct {
+	name        string
+	cfg         string
+	in          string
+	expectedOut string
+	expectedErr string
+}{
+	{
+		name: "merge maps of maps",
+		cfg: `
+apiVersion: airshipit.org/v1alpha1
+kind: MergeTransformer
+metadata:
+  name: versions
+merges:
+- target:
+    kind: VersionsCatalogue
+    name: versions-airshipctl
+  sources:
+  - name: versions-site
+`,
+		in: versionsCatalogues,
+		expectedOut: `apiVersion: airshipit.org/v1alpha1
+kind: VersionsCatalogue
+metadata:
+  name: versions-airshipctl
+spec:
+  images:
+    cluster-api:
+      manager:
+        image: registry.local/cluster-api/manager:v0.3.7
+      rbac_proxy:
+        image: gcr.io/kubebuilder/kube-rbac-proxy:v0.4.1
+  kubernetes: v1.20.2
+---
+apiVersion: airshipit.org/v1alpha1
+kind: VersionsCatalogue
+metadata:
+  name: versions-site
+  labels:
+    airshipit.org/override: site
+spec:
+  images:
+    cluster-api:
+      manager:
+        image: registry.local/cluster-api/manager:v0.3.7
+  kubernetes: v1.20.2
+`,
+	},
+	{
+		name: "select sources by label and remove them",
+		cfg: `
+apiVersion: airshipit.org/v1alpha1
+kind: MergeTransformer
+metadata:
+  name: versions
+merges:
+- target:
+    name: versions-airshipctl
+  sources:
+  - labelSelector: airshipit.org/override=site
+  removeSources: true
+`,
+		in: versionsCatalogues,
+		expectedOut: `apiVersion: airshipit.org/v1alpha1
+kind: VersionsCatalogue
+metadata:
+  name: versions-airshipctl
+spec:
+  images:
+    cluster-api:
+      manager:
+        image: registry.local/cluster-api/manager:v0.3.7
+      rbac_proxy:
+        image: gcr.io/kubebuilder/kube-rbac-proxy:v0.4.1
+  kubernetes: v1.20.2
+`,
+	},
+	{
+		name: "merge lists by keys",
+		cfg: `
+apiVersion: airshipit.org/v1alpha1
+kind: MergeTransformer
+metadata:
+  name: networking
+merges:
+- target:
+    name: networking
+  sources:
+  - name: networking-site
+  listMergeKeys:
+  - path: spec.commonHostNetworking.links
+    key: id
+  - path: spec.*.networks
+    key: id
+  removeSources: true
+`,
+		in: networkCatalogues,
+		expectedOut: `apiVersion: airshipit.org/v1alpha1
+kind: NetworkCatalogue
+metadata:
+  name: networking
+spec:
+  commonHostNetworking:
+    links:
+    - id: oam-ipv4
+      name: enp0s3
+      mtu: "9100"
+    - id: pxe-ipv4
+      name: enp0s4
+      mtu: "1500"
+    - id: storage-ipv4
+      name: enp0s5
+    networks:
+    - id: oam-ipv4
+      link: oam-ipv4
+      routes:
+      - network: 10.0.0.0
+        gateway: 10.23.25.2
+    - id: pxe-ipv4
+      link: pxe-ipv4
+  ntp:
+    servers:
+    - ntp.site.local
+`,
+	},
+	{
+		name: "merge several sources in order",
+		cfg: `
+apiVersion: airshipit.org/v1alpha1
+kind: MergeTransformer
+metadata:
+  name: versions
+merges:
+- target:
+    name: base
+  sources:
+  - name: second
+  - name: first
+  - kind: ConfigMap
+`,
+		in: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: base
+data:
+  a: base
+  b: base
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: first
+data:
+  b: first
+  c: first
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: second
+data:
+  c: second
+`,
+		expectedOut: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: base
+data:
+  a: base
+  b: first
+  c: first
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: first
+data:
+  b: first
+  c: first
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: second
+data:
+  c: second
+`,
+	},
+	{
+		name: "target not found",
+		cfg: `
+apiVersion: airshipit.org/v1alpha1
+kind: MergeTransformer
+metadata:
+  name: versions
+merges:
+- target:
+    kind: VersionsCatalogue
+    name: versions-treasuremap
+  sources:
+  - name: versions-site
+`,
+		in:          versionsCatalogues,
+		expectedErr: "failed to find merge target identified by Kind: VersionsCatalogue Name: versions-treasuremap",
+	},
+	{
+		name: "multiple targets",
+		cfg: `
+apiVersion: airshipit.org/v1alpha1
+kind: MergeTransformer
+metadata:
+  name: versions
+merges:
+- target:
+    name: versions-airshipctl
+  sources:
+  - name: versions-site
+`,
+		in: versionsCatalogues + `---
+apiVersion: airshipit.org/v1alpha1
+kind: NetworkCatalogue
+metadata:
+  name: versions-airshipctl
+`,
+		expectedErr: "found more than one merge target identified by Name: versions-airshipctl",
+	},
+	{
+		name: "source not found",
+		cfg: `
+apiVersion: airshipit.org/v1alpha1
+kind: MergeTransformer
+metadata:
+  name: versions
+merges:
+- target:
+    name: versions-airshipctl
+  sources:
+  - labelSelector: airshipit.org/override=region
+`,
+		in:          versionsCatalogues,
+		expectedErr: "failed to find any merge sources identified by labelSelector: airshipit.org/override=region",
+	},
+	{
+		name: "source of other kind",
+		cfg: `
+apiVersion: airshipit.org/v1alpha1
+kind: MergeTransformer
+metadata:
+  name: versions
+merges:
+- target:
+    name: versions-airshipctl
+  sources:
+  - kind: NetworkCatalogue
+`,
+		in: versionsCatalogues + "---" + networkCatalogues,
+		expectedErr: `can't merge NetworkCatalogue "networking" onto VersionsCatalogue "versions-airshipctl", ` +
+			"documents must be of the same kind",
+	},
+}
+
+func TestExec(t *testing.T) {
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := make(map[string]interface{})
+			err := yaml.Unmarshal([]byte(tc.cfg), &cfg)
+			require.NoError(t, err)
+			plugin, err := merge.New(cfg)
+			require.NoError(t, err)
+
+			buf := &bytes.Buffer{}
+			p := kio.Pipeline{
+				Inputs:  []kio.Reader{&kio.ByteReader{Reader: bytes.NewBufferString(tc.in)}},
+				Filters: []kio.Filter{plugin},
+				Outputs: []kio.Writer{kio.ByteWriter{Writer: buf}},
+			}
+			err = p.Execute()
+
+			errString := ""
+			if err != nil {
+				errString = err.Error()
+			}
+			assert.Equal(t, tc.expectedErr, errString)
+			assert.Equal(t, tc.expectedOut, buf.String())
+		})
+	}
+}
+
+func TestNewBadConfiguration(t *testing.T) {
+	testCases := []struct {
+		name        string
+		merges      string
+		expectedErr string
+	}{
+		{
+			name: "no target name",
+			merges: `
+- target:
+    kind: VersionsCatalogue
+  sources:
+  - name: versions-site`,
+			expectedErr: "target name must be specified in one merge",
+		},
+		{
+			name: "no sources",
+			merges: `
+- target:
+    name: versions-airshipctl`,
+			expectedErr: `no sources are specified for merge target "versions-airshipctl"`,
+		},
+		{
+			name: "list merge key without key",
+			merges: `
+- target:
+    name: networking
+  sources:
+  - name: networking-site
+  listMergeKeys:
+  - path: spec.commonHostNetworking.links`,
+			expectedErr: "both path and key must be specified for every list merge key",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := make(map[string]interface{})
+			err := yaml.Unmarshal([]byte("apiVersion: airshipit.org/v1alpha1\nkind: MergeTransformer\n"+
+				"metadata:\n  name: test\nmerges:"+tc.merges), &cfg)
+			require.NoError(t, err)
+			_, err = merge.New(cfg)
+			assert.EqualError(t, err, tc.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package plugin
+
+import (
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+
+	"opendev.org/airship/airshipctl/pkg/bootstrap/cloudinit"
+	"opendev.org/airship/airshipctl/pkg/document/plugin/merge"
+	"opendev.org/airship/airshipctl/pkg/document/plugin/replacement"
+	"opendev.org/airship/airshipctl/pkg/document/plugin/templater"
+)
+
+// ImageRepositories are the repositories airshipctl KRM function images are published to
+var ImageRepositories = []string{"localhost", "quay.io/airshipit"}
+
+var (
+	mu sync.RWMutex
+	// registry is filled before init functions run, the binary may run a function from init
+	registry = builtinFunctions()
+)
+
+func builtinFunctions() map[string]framework.ResourceListProcessor {
+	functions := map[string]framework.ResourceListProcessor{}
+	for name, processor := range map[string]framework.ResourceListProcessor{
+		"templater":               framework.ResourceListProcessorFunc(templater.Process),
+		"replacement-transformer": framework.ResourceListProcessorFunc(replacement.Process),
+		"merge-transformer":       framework.ResourceListProcessorFunc(merge.Process),
+		"cloud-init":              cloudinit.Function{OnHost: true},
+	} {
+		for _, repo := range ImageRepositories {
+			functions[repo+"/"+name] = processor
+		}
+	}
+	return functions
+}
+
+// Register adds in-process implementation of the KRM function distributed as image,
+// image is the name of the image without tag or digest, e.g. localhost/templater
+func Register(image string, processor framework.ResourceListProcessor) {
+	mu.Lock()
+	defer mu.Unlock()
+	registry[image] = processor
+}
+
+// Lookup returns in-process implementation of the KRM function distributed as image,
+// tag and digest of the image are ignored
+func Lookup(image string) (framework.ResourceListProcessor, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	processor, found := registry[imageName(image)]
+	return processor, found
+}
+
+// imageName strips tag and digest from the image reference
+func imageName(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
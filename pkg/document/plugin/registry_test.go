@@ -0,0 +1,53 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package plugin_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+
+	"opendev.org/airship/airshipctl/pkg/document/plugin"
+)
+
+func TestLookup(t *testing.T) {
+	plugin.Register("example.com:5000/org/custom", framework.ResourceListProcessorFunc(
+		func(*framework.ResourceList) error { return nil }))
+
+	tests := []struct {
+		image string
+		found bool
+	}{
+		{image: "localhost/templater", found: true},
+		{image: "localhost/templater:latest", found: true},
+		{image: "quay.io/airshipit/replacement-transformer:v2", found: true},
+		{image: "quay.io/airshipit/merge-transformer:latest", found: true},
+		{image: "quay.io/airshipit/cloud-init@sha256:0123456789abcdef", found: true},
+		{image: "example.com:5000/org/custom", found: true},
+		{image: "example.com:5000/org/custom:v1", found: true},
+		{image: "example.com/templater", found: false},
+		{image: "localhost/clusterctl:latest", found: false},
+		{image: "", found: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.image, func(t *testing.T) {
+			_, found := plugin.Lookup(tt.image)
+			assert.Equal(t, tt.found, found)
+		})
+	}
+}
@@ -0,0 +1,193 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package replacement
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+
+	airshipv1 "opendev.org/airship/airshipctl/pkg/api/v1alpha1"
+	"opendev.org/airship/airshipctl/pkg/document/plugin/kyamlutils"
+	"opendev.org/airship/airshipctl/pkg/log"
+)
+
+const (
+	// Kind is the kind of replacement transformer configuration
+	Kind = "ReplacementTransformer"
+	// TraceEnv is the name of env variable, if it's set to "true" every replacement transformer
+	// logs the source values, the matched targets and the changed fields of its replacements
+	TraceEnv = "TRACE_REPLACEMENTS"
+	// TraceAnnotation enables the trace for a single replacement transformer if it's set to "true"
+	// in the metadata of its configuration
+	TraceAnnotation = "airshipit.org/trace-replacements"
+	// TraceMaskAnnotation is the comma separated list of kinds which values are masked in the trace
+	// of the replacement transformer, DefaultTraceMaskKinds are masked if it's not set
+	TraceMaskAnnotation = "airshipit.org/trace-replacements-mask"
+	// DefaultTraceMaskKinds are the kinds which values are masked in the trace by default
+	DefaultTraceMaskKinds = "Secret,VariableCatalogue"
+
+	maskedValue       = "<masked>"
+	missingValue      = "<none>"
+	sopsMetadataField = "sops"
+)
+
+// tracer logs what a replacement does, nil tracer logs nothing
+type tracer struct {
+	prefix string
+	// maskKinds are the kinds of documents which values are masked
+	maskKinds map[string]bool
+	// mask hides the value of the source, it is set if the source document is masked
+	mask bool
+}
+
+func newTracer(enabled bool, index int, maskKinds map[string]bool) *tracer {
+	if !enabled {
+		return nil
+	}
+	return &tracer{prefix: fmt.Sprintf("replacements[%d]: ", index), maskKinds: maskKinds}
+}
+
+// traceMaskKinds returns the set of kinds from the comma separated list or DefaultTraceMaskKinds
+func traceMaskKinds(list string) map[string]bool {
+	if list == "" {
+		list = DefaultTraceMaskKinds
+	}
+	kinds := map[string]bool{}
+	for _, kind := range strings.Split(list, ",") {
+		if kind = strings.TrimSpace(kind); kind != "" {
+			kinds[kind] = true
+		}
+	}
+	return kinds
+}
+
+// source logs the source of replacement and its value, the value is masked if the document
+// it is taken from is masked
+func (t *tracer) source(items []*yaml.RNode, source *airshipv1.ReplSource, value *yaml.RNode) {
+	if t == nil {
+		return
+	}
+	if source.ObjRef != nil {
+		// the source is already resolved, so the errors are reported by getValue
+		docs, _ := selectSources(items, source.ObjRef)
+		for _, doc := range docs {
+			t.mask = t.mask || t.masked(doc)
+		}
+	}
+	t.printf("source %s = %s", describeObjRef(source.ObjRef, source.FieldRef), t.value(value, false))
+}
+
+// targets logs the documents matched by the target of replacement
+func (t *tracer) targets(target *airshipv1.ReplTarget, docs []*yaml.RNode) {
+	if t == nil {
+		return
+	}
+	matched := fmt.Sprintf("target %s matched %d document(s)", describeSelector(target.ObjRef), len(docs))
+	for i, doc := range docs {
+		if i == 0 {
+			matched += ":"
+		}
+		matched += " " + describeDocument(doc)
+	}
+	t.printf("%s", matched)
+}
+
+// fieldValue returns the value of the target field to be logged
+func (t *tracer) fieldValue(doc *yaml.RNode, fieldRef string) string {
+	if t == nil {
+		return ""
+	}
+	if groups := substringPatternRegex.FindStringSubmatch(fieldRef); len(groups) == 3 {
+		fieldRef = groups[1]
+	}
+	node, err := doc.Pipe(kyamlutils.JSONPathFilter{Path: fieldRef})
+	if err != nil || node == nil {
+		return missingValue
+	}
+	return t.value(node, t.masked(doc))
+}
+
+// masked checks if the values of the document are hidden: the document is of one of the masked kinds
+// or it is left encrypted with sops
+func (t *tracer) masked(doc *yaml.RNode) bool {
+	return t.maskKinds[doc.GetKind()] || doc.Field(sopsMetadataField) != nil
+}
+
+// field logs the change of the target field, before is the value returned by fieldValue prior to the change
+func (t *tracer) field(doc *yaml.RNode, fieldRef, before string) {
+	if t == nil {
+		return
+	}
+	t.printf("%s %s: %s -> %s", describeDocument(doc), fieldRef, before, t.fieldValue(doc, fieldRef))
+}
+
+func (t *tracer) value(node *yaml.RNode, maskedField bool) string {
+	if t.mask || maskedField {
+		return maskedValue
+	}
+	var value interface{}
+	if err := node.YNode().Decode(&value); err != nil {
+		return node.MustString()
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return node.MustString()
+	}
+	return string(data)
+}
+
+func (t *tracer) printf(format string, args ...interface{}) {
+	log.Printf(t.prefix+format, args...)
+}
+
+func describeObjRef(objRef *airshipv1.Target, fieldRef string) string {
+	if objRef == nil {
+		return "value"
+	}
+	if fieldRef == "" {
+		fieldRef = "metadata.name"
+	}
+	return describeID(objRef.Kind, objRef.Namespace, objRef.Name) + " " + fieldRef
+}
+
+func describeSelector(selector *airshipv1.Selector) string {
+	s := describeID(selector.Kind, selector.Namespace, selector.Name)
+	if selector.LabelSelector != "" {
+		s += " with labels " + selector.LabelSelector
+	}
+	return s
+}
+
+func describeDocument(doc *yaml.RNode) string {
+	meta, _ := doc.GetMeta()
+	return describeID(meta.Kind, meta.Namespace, meta.Name)
+}
+
+// describeID returns kind and namespaced name, omitted parts are replaced with *
+func describeID(kind, namespace, name string) string {
+	if kind == "" {
+		kind = "*"
+	}
+	if name == "" {
+		name = "*"
+	}
+	if namespace != "" {
+		name = namespace + "/" + name
+	}
+	return kind + " " + name
+}
@@ -17,10 +17,12 @@ package replacement
 import (
 	"encoding/base64"
 	"fmt"
+	"os"
 	"regexp"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 
@@ -42,6 +44,25 @@ var _ kio.Filter = &plugin{}
 
 type plugin struct {
 	*airshipv1.ReplacementTransformer
+	// trace enables logging of the sources, targets and changed fields, see TraceEnv
+	trace bool
+	// traceMaskKinds are the kinds which values are masked in the trace, see TraceMaskAnnotation
+	traceMaskKinds map[string]bool
+}
+
+// Process is the KRM function entrypoint, it runs the plugin configured
+// by the function config against the items of resource list
+func Process(rl *framework.ResourceList) error {
+	cfg, err := rl.FunctionConfig.Map()
+	if err != nil {
+		return err
+	}
+	fltr, err := New(cfg)
+	if err != nil {
+		return err
+	}
+	rl.Items, err = fltr.Filter(rl.Items)
+	return err
 }
 
 // New creates new instance of the plugin
@@ -51,7 +72,11 @@ func New(obj map[string]interface{}) (kio.Filter, error) {
 	if err != nil {
 		return nil, err
 	}
-	p := &plugin{ReplacementTransformer: cfg}
+	p := &plugin{
+		ReplacementTransformer: cfg,
+		trace:                  os.Getenv(TraceEnv) == "true" || cfg.Annotations[TraceAnnotation] == "true",
+		traceMaskKinds:         traceMaskKinds(cfg.Annotations[TraceMaskAnnotation]),
+	}
 	for _, r := range p.Replacements {
 		if r.Source == nil {
 			return nil, ErrBadConfiguration{Msg: "`from` must be specified in one replacement"}
@@ -73,19 +98,21 @@ func New(obj map[string]interface{}) (kio.Filter, error) {
 }
 
 func (p *plugin) Filter(items []*yaml.RNode) ([]*yaml.RNode, error) {
-	for _, r := range p.Replacements {
+	for i, r := range p.Replacements {
 		val, err := getValue(items, r.Source)
 		if err != nil {
 			return nil, err
 		}
+		tr := newTracer(p.trace, i, p.traceMaskKinds)
+		tr.source(items, r.Source, val)
 		if r.Target != nil {
-			if err := replace(items, r.Target, val); err != nil {
+			if err := replace(items, r.Target, val, tr); err != nil {
 				return nil, err
 			}
 		}
 		// range handles nil case as empty list
 		for _, t := range r.Targets {
-			if err := replace(items, t, val); err != nil {
+			if err := replace(items, t, val, tr); err != nil {
 				return nil, err
 			}
 		}
@@ -98,12 +125,7 @@ func getValue(items []*yaml.RNode, source *airshipv1.ReplSource) (*yaml.RNode, e
 		return yaml.NewScalarRNode(*source.Value), nil
 	}
 
-	sources, err := kyamlutils.DocumentSelector{}.
-		ByAPIVersion(source.ObjRef.APIVersion).
-		ByGVK(source.ObjRef.Group, source.ObjRef.Version, source.ObjRef.Kind).
-		ByName(source.ObjRef.Name).
-		ByNamespace(source.ObjRef.Namespace).
-		Filter(items)
+	sources, err := selectSources(items, source.ObjRef)
 	if err != nil {
 		return nil, err
 	}
@@ -132,6 +154,16 @@ func getValue(items []*yaml.RNode, source *airshipv1.ReplSource) (*yaml.RNode, e
 	return sourceNode, nil
 }
 
+// selectSources returns the documents matching the source object reference
+func selectSources(items []*yaml.RNode, objRef *airshipv1.Target) ([]*yaml.RNode, error) {
+	return kyamlutils.DocumentSelector{}.
+		ByAPIVersion(objRef.APIVersion).
+		ByGVK(objRef.Group, objRef.Version, objRef.Kind).
+		ByName(objRef.Name).
+		ByNamespace(objRef.Namespace).
+		Filter(items)
+}
+
 func mutateField(rnSource *yaml.RNode) func([]*yaml.RNode) error {
 	return func(rns []*yaml.RNode) error {
 		for _, rn := range rns {
@@ -141,7 +173,7 @@ func mutateField(rnSource *yaml.RNode) func([]*yaml.RNode) error {
 	}
 }
 
-func replace(items []*yaml.RNode, target *airshipv1.ReplTarget, value *yaml.RNode) error {
+func replace(items []*yaml.RNode, target *airshipv1.ReplTarget, value *yaml.RNode, tr *tracer) error {
 	targets, err := kyamlutils.DocumentSelector{}.
 		ByGVK(target.ObjRef.Group, target.ObjRef.Version, target.ObjRef.Kind).
 		ByName(target.ObjRef.Name).
@@ -151,36 +183,39 @@ func replace(items []*yaml.RNode, target *airshipv1.ReplTarget, value *yaml.RNod
 	if err != nil {
 		return err
 	}
+	tr.targets(target, targets)
 	if len(targets) == 0 {
 		return ErrTargetNotFound{ObjRef: target.ObjRef}
 	}
 	for _, tgt := range targets {
 		for _, fieldRef := range target.FieldRefs {
-			val := value
-			// Encoding value before replacement if target is `kind: Secret`
-			// and has fieldRef `data`
-			if target.ObjRef.Gvk.Kind == secret && strings.Split(fieldRef, ".")[0] == secretData {
-				val = encodeValue(val)
-			}
-			// fieldref can contain substring pattern for regexp - we need to get it
-			groups := substringPatternRegex.FindStringSubmatch(fieldRef)
-			// if there is no substring pattern
-			if len(groups) != 3 {
-				filter := kyamlutils.JSONPathFilter{Path: fieldRef, Mutator: mutateField(val), Create: true}
-				if _, err := tgt.Pipe(filter); err != nil {
-					return err
-				}
-				continue
-			}
-
-			if err := substituteSubstring(tgt, groups[1], groups[2], val); err != nil {
+			before := tr.fieldValue(tgt, fieldRef)
+			if err := replaceField(tgt, target, fieldRef, value); err != nil {
 				return err
 			}
+			tr.field(tgt, fieldRef, before)
 		}
 	}
 	return nil
 }
 
+func replaceField(tgt *yaml.RNode, target *airshipv1.ReplTarget, fieldRef string, value *yaml.RNode) error {
+	// Encoding value before replacement if target is `kind: Secret`
+	// and has fieldRef `data`
+	if target.ObjRef.Gvk.Kind == secret && strings.Split(fieldRef, ".")[0] == secretData {
+		value = encodeValue(value)
+	}
+	// fieldref can contain substring pattern for regexp - we need to get it
+	groups := substringPatternRegex.FindStringSubmatch(fieldRef)
+	// if there is no substring pattern
+	if len(groups) != 3 {
+		filter := kyamlutils.JSONPathFilter{Path: fieldRef, Mutator: mutateField(value), Create: true}
+		_, err := tgt.Pipe(filter)
+		return err
+	}
+	return substituteSubstring(tgt, groups[1], groups[2], value)
+}
+
 func substituteSubstring(tgt *yaml.RNode, fieldRef, substringPattern string, value *yaml.RNode) error {
 	if err := yaml.ErrorIfInvalid(value, yaml.ScalarNode); err != nil {
 		return err
@@ -17,6 +17,8 @@ package replacement_test
 import (
 	"bytes"
 	"fmt"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -26,6 +28,7 @@ import (
 	"sigs.k8s.io/yaml"
 
 	"opendev.org/airship/airshipctl/pkg/document/plugin/replacement"
+	"opendev.org/airship/airshipctl/pkg/log"
 )
 
 var testCases = []struct {
@@ -1445,3 +1448,165 @@ func TestExec(t *testing.T) {
 		})
 	}
 }
+
+func TestTrace(t *testing.T) {
+	in := `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+  namespace: default
+data:
+  password: c2VjcmV0
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: default
+data:
+  version: v1
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default
+spec:
+  replicas: 1
+---
+apiVersion: airshipit.org/v1alpha1
+kind: VariableCatalogue
+metadata:
+  name: catalogue
+  namespace: default
+values:
+  image: nginx
+`
+	replacements := `
+replacements:
+- source:
+    objref:
+      kind: Secret
+      name: creds
+    fieldref: data.password
+  target:
+    objref:
+      kind: ConfigMap
+    fieldrefs:
+    - data.password
+- source:
+    value: "3"
+  targets:
+  - objref:
+      kind: Deployment
+      name: app
+    fieldrefs:
+    - spec.replicas
+    - spec.paused
+  - objref:
+      kind: ConfigMap
+      name: settings
+    fieldrefs:
+    - data.version%v1%
+- source:
+    objref:
+      kind: VariableCatalogue
+      name: catalogue
+    fieldref: values.image
+  target:
+    objref:
+      kind: Deployment
+      name: app
+    fieldrefs:
+    - spec.image
+`
+	expectedTrace := []string{
+		"replacements[0]: source Secret creds data.password = <masked>",
+		"replacements[0]: target ConfigMap * matched 1 document(s): ConfigMap default/settings",
+		"replacements[0]: ConfigMap default/settings data.password: <none> -> <masked>",
+		"replacements[1]: source value = 3",
+		"replacements[1]: target Deployment app matched 1 document(s): Deployment default/app",
+		"replacements[1]: Deployment default/app spec.replicas: 1 -> 3",
+		"replacements[1]: Deployment default/app spec.paused: <none> -> 3",
+		"replacements[1]: target ConfigMap settings matched 1 document(s): ConfigMap default/settings",
+		`replacements[1]: ConfigMap default/settings data.version%v1%: "v1" -> "3"`,
+		"replacements[2]: source VariableCatalogue catalogue values.image = <masked>",
+		"replacements[2]: target Deployment app matched 1 document(s): Deployment default/app",
+		"replacements[2]: Deployment default/app spec.image: <none> -> <masked>",
+	}
+	maskedConfigMapTrace := []string{
+		`replacements[0]: source Secret creds data.password = "secret"`,
+		"replacements[0]: target ConfigMap * matched 1 document(s): ConfigMap default/settings",
+		"replacements[0]: ConfigMap default/settings data.password: <none> -> <masked>",
+		"replacements[1]: source value = 3",
+		"replacements[1]: target Deployment app matched 1 document(s): Deployment default/app",
+		"replacements[1]: Deployment default/app spec.replicas: 1 -> 3",
+		"replacements[1]: Deployment default/app spec.paused: <none> -> 3",
+		"replacements[1]: target ConfigMap settings matched 1 document(s): ConfigMap default/settings",
+		"replacements[1]: ConfigMap default/settings data.version%v1%: <masked> -> <masked>",
+		`replacements[2]: source VariableCatalogue catalogue values.image = "nginx"`,
+		"replacements[2]: target Deployment app matched 1 document(s): Deployment default/app",
+		`replacements[2]: Deployment default/app spec.image: <none> -> "nginx"`,
+	}
+
+	tests := []struct {
+		name          string
+		annotations   string
+		env           string
+		expectedTrace []string
+	}{
+		{
+			name: "trace disabled",
+		},
+		{
+			name:          "trace enabled by annotation",
+			annotations:   "  annotations:\n    " + replacement.TraceAnnotation + ": \"true\"\n",
+			expectedTrace: expectedTrace,
+		},
+		{
+			name:          "trace enabled by env",
+			env:           "true",
+			expectedTrace: expectedTrace,
+		},
+		{
+			name: "masked kinds set by annotation",
+			annotations: "  annotations:\n    " + replacement.TraceAnnotation + ": \"true\"\n    " +
+				replacement.TraceMaskAnnotation + ": ConfigMap\n",
+			expectedTrace: maskedConfigMapTrace,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, os.Setenv(replacement.TraceEnv, tt.env))
+			defer os.Unsetenv(replacement.TraceEnv)
+			logs := &bytes.Buffer{}
+			log.Init(false, logs)
+			defer log.Init(false, os.Stderr)
+
+			cfg := make(map[string]interface{})
+			err := yaml.Unmarshal([]byte("apiVersion: airshipit.org/v1alpha1\nkind: ReplacementTransformer\n"+
+				"metadata:\n  name: trace\n"+tt.annotations+replacements), &cfg)
+			require.NoError(t, err)
+			plugin, err := replacement.New(cfg)
+			require.NoError(t, err)
+
+			p := kio.Pipeline{
+				Inputs:  []kio.Reader{&kio.ByteReader{Reader: bytes.NewBufferString(in)}},
+				Filters: []kio.Filter{plugin},
+				Outputs: []kio.Writer{kio.ByteWriter{Writer: &bytes.Buffer{}}},
+			}
+			require.NoError(t, p.Execute())
+
+			var trace []string
+			for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+				if i := strings.Index(line, "replacements["); i >= 0 {
+					trace = append(trace, line[i:])
+				}
+			}
+			assert.Equal(t, tt.expectedTrace, trace)
+		})
+	}
+}
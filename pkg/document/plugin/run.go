@@ -0,0 +1,90 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package plugin
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"opendev.org/airship/airshipctl/pkg/document"
+)
+
+// Run executes in-process implementation of the KRM function against the resource list
+// read from reader and writes resulting resource list to writer
+func Run(processor framework.ResourceListProcessor, reader io.Reader, writer io.Writer) error {
+	rw := &kio.ByteReadWriter{
+		Reader:                reader,
+		Writer:                writer,
+		KeepReaderAnnotations: true,
+	}
+	items, err := rw.Read()
+	if err != nil {
+		return err
+	}
+	return process(processor, rw, items)
+}
+
+// init runs the KRM function instead of the binary if kustomize started it to run the function,
+// see document.NativeFunctions, any binary linking the registry can serve the functions this way
+func init() {
+	if filepath.Base(os.Args[0]) != document.FunctionExecutable {
+		return
+	}
+	if err := RunFunction(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// RunFunction executes in-process implementation of the KRM function which image is set
+// by document.FunctionImageAnnotation of the function config of the resource list
+func RunFunction(reader io.Reader, writer io.Writer) error {
+	rw := &kio.ByteReadWriter{
+		Reader:                reader,
+		Writer:                writer,
+		KeepReaderAnnotations: true,
+	}
+	items, err := rw.Read()
+	if err != nil {
+		return err
+	}
+	var image string
+	if rw.FunctionConfig != nil {
+		image = rw.FunctionConfig.GetAnnotations()[document.FunctionImageAnnotation]
+	}
+	processor, found := Lookup(image)
+	if !found {
+		return ErrFunctionNotFound{Image: image}
+	}
+	return process(processor, rw, items)
+}
+
+func process(processor framework.ResourceListProcessor, rw *kio.ByteReadWriter, items []*kyaml.RNode) error {
+	rl := &framework.ResourceList{
+		Items:          items,
+		FunctionConfig: rw.FunctionConfig,
+	}
+	if err := processor.Process(rl); err != nil {
+		return err
+	}
+	return rw.Write(rl.Items)
+}
@@ -0,0 +1,233 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package templater
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"opendev.org/airship/airshipctl/pkg/document"
+)
+
+const snippetContextLines = 2
+
+var (
+	// templateErrorLocation matches the location text/template starts its errors with
+	templateErrorLocation = regexp.MustCompile(`^template: ([^:]+):(\d+)(?::(\d+))?: `)
+	// yamlErrorLine matches the line yaml parser reports its errors at
+	yamlErrorLine = regexp.MustCompile(`^yaml: line (\d+):`)
+)
+
+// ErrTemplate is returned if the template or one of the modules fails to parse or execute
+type ErrTemplate struct {
+	// Templater is the name of the templater
+	Templater string
+	// Origin is the source file of the templater, empty if unknown
+	Origin string
+	// Module is the name of the module the error is located in, empty for the template itself
+	Module string
+	// Line and Column locate the error in the template or the module, zero if unknown
+	Line   int
+	Column int
+	// Snippet quotes the lines around the error
+	Snippet string
+	Err     error
+}
+
+// ErrRenderedYAML is returned if the output of the template is not valid YAML
+type ErrRenderedYAML struct {
+	// Templater is the name of the templater
+	Templater string
+	// Origin is the source file of the templater, empty if unknown
+	Origin string
+	// Line of the output the error is located in, zero if unknown
+	Line int
+	// Snippet quotes the lines of the output around the error
+	Snippet string
+	Err     error
+}
+
+// ErrValuesNotFound is returned if the document or the field referenced by valuesFrom doesn't exist
+type ErrValuesNotFound struct {
+	Kind      string
+	Name      string
+	Namespace string
+	// Field is the path of the missing field, empty if the document itself is missing
+	Field string
+}
+
+// ErrValuesAmbiguousReference is returned if valuesFrom references more than one document
+type ErrValuesAmbiguousReference struct {
+	Kind      string
+	Name      string
+	Namespace string
+	Found     int
+}
+
+// ErrValuesNotMap is returned if the values to be merged are not a map
+type ErrValuesNotMap struct {
+	// Name and Field identify the valuesFrom document and its field, empty for the inline values
+	Name  string
+	Field string
+	Value interface{}
+}
+
+func (e ErrValuesNotFound) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("field %s of valuesFrom document %s not found", e.Field, e.Name)
+	}
+	return fmt.Sprintf("valuesFrom document identified by kind %q name %q namespace %q not found",
+		e.Kind, e.Name, e.Namespace)
+}
+
+func (e ErrValuesAmbiguousReference) Error() string {
+	return fmt.Sprintf("valuesFrom must reference exactly one document, found %d documents "+
+		"identified by kind %q name %q namespace %q", e.Found, e.Kind, e.Name, e.Namespace)
+}
+
+func (e ErrValuesNotMap) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("values must be a map to be merged with valuesFrom, got %T", e.Value)
+	}
+	return fmt.Sprintf("field %s of valuesFrom document %s must be a map, got %T", e.Field, e.Name, e.Value)
+}
+
+func (e ErrTemplate) Error() string {
+	msg := templaterPrefix(e.Templater, e.Origin)
+	if e.Line > 0 {
+		where := "template"
+		if e.Module != "" {
+			where = "module " + e.Module
+		}
+		msg += fmt.Sprintf("%s line %d", where, e.Line)
+		if e.Column > 0 {
+			msg += fmt.Sprintf(", column %d", e.Column)
+		}
+		msg += ": " + templateErrorLocation.ReplaceAllString(e.Err.Error(), "")
+	} else {
+		msg += e.Err.Error()
+	}
+	if e.Snippet != "" {
+		msg += "\n" + e.Snippet
+	}
+	return msg
+}
+
+func (e ErrTemplate) Unwrap() error {
+	return e.Err
+}
+
+func (e ErrRenderedYAML) Error() string {
+	msg := templaterPrefix(e.Templater, e.Origin) + "rendered output is not valid YAML: " + e.Err.Error()
+	if e.Snippet != "" {
+		msg += "\n" + e.Snippet
+	}
+	return msg
+}
+
+func (e ErrRenderedYAML) Unwrap() error {
+	return e.Err
+}
+
+func templaterPrefix(name, origin string) string {
+	if origin == "" {
+		return fmt.Sprintf("templater %s: ", name)
+	}
+	return fmt.Sprintf("templater %s defined in %s: ", name, origin)
+}
+
+// templateError returns ErrTemplate locating err in the template or in one of the modules,
+// sources maps the names of the templates to their text
+func (t *plugin) templateError(err error, sources map[string]string) error {
+	e := ErrTemplate{Templater: t.Name, Origin: t.origin(), Err: err}
+	groups := templateErrorLocation.FindStringSubmatch(err.Error())
+	if groups == nil {
+		return e
+	}
+	text, found := sources[groups[1]]
+	if !found {
+		return e
+	}
+	if groups[1] != t.Name {
+		e.Module = groups[1]
+	}
+	e.Line, _ = strconv.Atoi(groups[2])
+	if groups[3] != "" {
+		// text/template reports zero-based byte offset in the line
+		column, _ := strconv.Atoi(groups[3])
+		e.Column = column + 1
+	}
+	e.Snippet = snippet(text, e.Line, e.Column)
+	return e
+}
+
+// renderedYAMLError returns ErrRenderedYAML quoting the lines of out the yaml parser failed at
+func (t *plugin) renderedYAMLError(out []byte, err error) error {
+	e := ErrRenderedYAML{Templater: t.Name, Origin: t.origin(), Err: err}
+	decoder := yaml.NewDecoder(bytes.NewReader(out))
+	for {
+		decodeErr := decoder.Decode(&yaml.Node{})
+		if decodeErr == nil {
+			continue
+		}
+		if groups := yamlErrorLine.FindStringSubmatch(decodeErr.Error()); groups != nil {
+			e.Line, _ = strconv.Atoi(groups[1])
+			e.Snippet = snippet(string(out), e.Line, 0)
+		}
+		return e
+	}
+}
+
+// origin returns the source file of the templater config, empty if it is unknown
+func (t *plugin) origin() string {
+	origin, ok := document.OriginFromAnnotations(t.Annotations)
+	if !ok {
+		return ""
+	}
+	return origin.String()
+}
+
+// snippet quotes the lines of text around line, the line itself is marked with > and
+// the column, if known, is pointed to by ^ on the next line
+func snippet(text string, line, column int) string {
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	first, last := line-snippetContextLines, line+snippetContextLines
+	if first < 1 {
+		first = 1
+	}
+	if last > len(lines) {
+		last = len(lines)
+	}
+	b := &strings.Builder{}
+	for i := first; i <= last; i++ {
+		marker := "  "
+		if i == line {
+			marker = "> "
+		}
+		fmt.Fprintf(b, "%s%4d | %s\n", marker, i, lines[i-1])
+		if i == line && column > 0 {
+			fmt.Fprintf(b, "%s%s^\n", strings.Repeat(" ", len("> 1234 | ")), strings.Repeat(" ", column-1))
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,234 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package extlib
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// certificateRequest is PEM encoded certificate signing request and its private key
+type certificateRequest struct {
+	CSR string
+	Key string
+}
+
+// decodeCert returns the attributes of PEM encoded certificate, so templates can inspect
+// certificates taken from the documents
+func decodeCert(certPEM string) (map[string]interface{}, error) {
+	cert, err := parseCertificatePEM(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	ipAddresses := make([]string, 0, len(cert.IPAddresses))
+	for _, ip := range cert.IPAddresses {
+		ipAddresses = append(ipAddresses, ip.String())
+	}
+	return map[string]interface{}{
+		"subject":            cert.Subject.String(),
+		"commonName":         cert.Subject.CommonName,
+		"issuer":             cert.Issuer.String(),
+		"serialNumber":       cert.SerialNumber.String(),
+		"notBefore":          cert.NotBefore.UTC().Format(time.RFC3339),
+		"notAfter":           cert.NotAfter.UTC().Format(time.RFC3339),
+		"dnsNames":           cert.DNSNames,
+		"ipAddresses":        ipAddresses,
+		"emailAddresses":     cert.EmailAddresses,
+		"isCA":               cert.IsCA,
+		"keyAlgorithm":       cert.PublicKeyAlgorithm.String(),
+		"signatureAlgorithm": cert.SignatureAlgorithm.String(),
+	}, nil
+}
+
+// certExpiresWithin checks if PEM encoded certificate expires within the number of days,
+// e.g. to regenerate the certificate only if it's about to expire
+func (g *generator) certExpiresWithin(certPEM string, days int) (bool, error) {
+	cert, err := parseCertificatePEM(certPEM)
+	if err != nil {
+		return false, err
+	}
+	now, err := g.now()
+	if err != nil {
+		return false, err
+	}
+	return now.Add(time.Hour * 24 * time.Duration(days)).After(cert.NotAfter), nil
+}
+
+// genPrivateKeyEx returns PEM encoded private key of the type: rsa, ecdsa or ed25519
+func (g *generator) genPrivateKeyEx(keyType string) (string, error) {
+	priv, err := g.privateKey(keyType)
+	if err != nil {
+		return "", err
+	}
+	block := pemBlockForKey(priv)
+	if block == nil {
+		return "", fmt.Errorf("error pem-encoding %s key", keyType)
+	}
+	return string(pem.EncodeToMemory(block)), nil
+}
+
+func (g *generator) privateKey(keyType string) (crypto.PrivateKey, error) {
+	switch keyType {
+	case "rsa":
+		return rsa.GenerateKey(g.random, defaultKeyBits)
+	case "ecdsa":
+		return ecdsa.GenerateKey(elliptic.P256(), g.random)
+	case "ed25519":
+		_, priv, err := ed25519.GenerateKey(g.random)
+		return priv, err
+	default:
+		return nil, fmt.Errorf("unsupported key type %s, must be one of rsa, ecdsa, ed25519", keyType)
+	}
+}
+
+// generateCertificateRequest returns certificate signing request signed by new RSA key
+func (g *generator) generateCertificateRequest(
+	subj string,
+	ips []interface{},
+	alternateDNS []interface{},
+) (certificateRequest, error) {
+	priv, err := rsa.GenerateKey(g.random, defaultKeyBits)
+	if err != nil {
+		return certificateRequest{}, fmt.Errorf("error generating rsa key: %s", err)
+	}
+	return g.generateCertificateRequestInternal(subj, ips, alternateDNS, priv)
+}
+
+// generateCertificateRequestWithPEMKey returns certificate signing request signed by PEM encoded key
+func (g *generator) generateCertificateRequestWithPEMKey(
+	subj string,
+	ips []interface{},
+	alternateDNS []interface{},
+	privPEM string,
+) (certificateRequest, error) {
+	priv, err := parsePrivateKeyPEM(privPEM)
+	if err != nil {
+		return certificateRequest{}, fmt.Errorf("parsing private key: %s", err)
+	}
+	return g.generateCertificateRequestInternal(subj, ips, alternateDNS, priv)
+}
+
+func (g *generator) generateCertificateRequestInternal(
+	subj string,
+	ips []interface{},
+	alternateDNS []interface{},
+	priv crypto.PrivateKey,
+) (certificateRequest, error) {
+	req := certificateRequest{}
+
+	ipAddresses, err := getNetIPs(ips)
+	if err != nil {
+		return req, err
+	}
+	dnsNames, err := getAlternateDNSStrs(alternateDNS)
+	if err != nil {
+		return req, err
+	}
+	name, err := nameFromString(subj)
+	if err != nil {
+		return req, err
+	}
+	der, err := x509.CreateCertificateRequest(g.random, &x509.CertificateRequest{
+		Subject:     *name,
+		IPAddresses: ipAddresses,
+		DNSNames:    dnsNames,
+	}, priv)
+	if err != nil {
+		return req, fmt.Errorf("error creating certificate request: %s", err)
+	}
+	block := pemBlockForKey(priv)
+	if block == nil {
+		return req, fmt.Errorf("error pem-encoding key of type %T", priv)
+	}
+
+	req.CSR = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}))
+	req.Key = string(pem.EncodeToMemory(block))
+	return req, nil
+}
+
+// signCertificateRequest returns PEM encoded certificate issued by ca for PEM encoded
+// certificate signing request, the subject and the alternative names are taken from the request
+func (g *generator) signCertificateRequest(csrPEM string, daysValid int, ca Certificate) (string, error) {
+	block, _ := pem.Decode([]byte(csrPEM))
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return "", errors.New("unable to decode certificate request")
+	}
+	req, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("error parsing certificate request: %s", err)
+	}
+	if err = req.CheckSignature(); err != nil {
+		return "", fmt.Errorf("invalid signature of certificate request: %s", err)
+	}
+
+	signerCert, signerKey, err := parseCA(ca)
+	if err != nil {
+		return "", err
+	}
+	template, err := g.certTemplate(req.Subject, req.IPAddresses, req.DNSNames, daysValid)
+	if err != nil {
+		return "", err
+	}
+	template.EmailAddresses = req.EmailAddresses
+	template.KeyUsage = keyUsageFor(req.PublicKey, template.KeyUsage)
+
+	der, err := x509.CreateCertificate(g.random, template, signerCert, req.PublicKey, signerKey)
+	if err != nil {
+		return "", fmt.Errorf("error creating certificate: %s", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})), nil
+}
+
+// parseCA returns the certificate and the private key of ca
+func parseCA(ca Certificate) (*x509.Certificate, crypto.PrivateKey, error) {
+	cert, err := parseCertificatePEM(ca.Cert)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := parsePrivateKeyPEM(ca.Key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing private key: %s", err)
+	}
+	return cert, key, nil
+}
+
+func parseCertificatePEM(certPEM string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(certPEM))
+	if block == nil {
+		return nil, errors.New("unable to decode certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing certificate: %s", err)
+	}
+	return cert, nil
+}
+
+// keyUsageFor removes key encipherment from the key usage of non-RSA keys,
+// only RSA keys can be used to encipher keys
+func keyUsageFor(pub crypto.PublicKey, usage x509.KeyUsage) x509.KeyUsage {
+	if _, ok := pub.(*rsa.PublicKey); ok {
+		return usage
+	}
+	return usage &^ x509.KeyUsageKeyEncipherment
+}
@@ -0,0 +1,147 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package extlib
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fixedNow() (time.Time, error) {
+	return time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC), nil
+}
+
+func TestDecodeCert(t *testing.T) {
+	g := newSeededGenerator("decode", "test", fixedNow)
+	ca, err := g.generateCertificateAuthorityEx("/CN=Kubernetes API/O=airship", 3650)
+	require.NoError(t, err)
+	cert, err := g.generateSignedCertificateEx("/CN=admin/O=system:masters",
+		[]interface{}{"10.0.0.1"}, []interface{}{"admin.example.com"}, 30, ca)
+	require.NoError(t, err)
+
+	decoded, err := decodeCert(cert.Cert)
+	require.NoError(t, err)
+	assert.Equal(t, "CN=admin,O=system:masters", decoded["subject"])
+	assert.Equal(t, "admin", decoded["commonName"])
+	assert.Equal(t, "CN=Kubernetes API,O=airship", decoded["issuer"])
+	assert.Equal(t, "2021-05-01T00:00:00Z", decoded["notBefore"])
+	assert.Equal(t, "2021-05-31T00:00:00Z", decoded["notAfter"])
+	assert.Equal(t, []string{"admin.example.com"}, decoded["dnsNames"])
+	assert.Equal(t, []string{"10.0.0.1"}, decoded["ipAddresses"])
+	assert.Equal(t, false, decoded["isCA"])
+	assert.Equal(t, "RSA", decoded["keyAlgorithm"])
+	assert.Equal(t, "SHA256-RSA", decoded["signatureAlgorithm"])
+	assert.NotEmpty(t, decoded["serialNumber"])
+
+	decoded, err = decodeCert(ca.Cert)
+	require.NoError(t, err)
+	assert.Equal(t, true, decoded["isCA"])
+
+	_, err = decodeCert("not a certificate")
+	assert.EqualError(t, err, "unable to decode certificate")
+}
+
+func TestCertExpiresWithin(t *testing.T) {
+	g := newSeededGenerator("expiry", "test", fixedNow)
+	ca, err := g.generateCertificateAuthorityEx("ca", 30)
+	require.NoError(t, err)
+
+	expires, err := g.certExpiresWithin(ca.Cert, 29)
+	require.NoError(t, err)
+	assert.False(t, expires)
+
+	expires, err = g.certExpiresWithin(ca.Cert, 31)
+	require.NoError(t, err)
+	assert.True(t, expires)
+
+	_, err = g.certExpiresWithin("", 31)
+	assert.EqualError(t, err, "unable to decode certificate")
+}
+
+func TestGenPrivateKeyEx(t *testing.T) {
+	for _, keyType := range []string{"rsa", "ecdsa", "ed25519"} {
+		keyPEM, err := defaultGenerator.genPrivateKeyEx(keyType)
+		require.NoError(t, err)
+		key, err := parsePrivateKeyPEM(keyPEM)
+		require.NoError(t, err)
+		switch keyType {
+		case "rsa":
+			assert.IsType(t, &rsa.PrivateKey{}, key)
+		case "ecdsa":
+			assert.IsType(t, &ecdsa.PrivateKey{}, key)
+		case "ed25519":
+			assert.IsType(t, ed25519.PrivateKey{}, key)
+		}
+	}
+
+	_, err := defaultGenerator.genPrivateKeyEx("dsa")
+	assert.EqualError(t, err, "unsupported key type dsa, must be one of rsa, ecdsa, ed25519")
+}
+
+func TestSignCSR(t *testing.T) {
+	for _, caKeyType := range []string{"rsa", "ecdsa", "ed25519"} {
+		caKeyType := caKeyType
+		t.Run(caKeyType, func(t *testing.T) {
+			caKey, err := defaultGenerator.genPrivateKeyEx(caKeyType)
+			require.NoError(t, err)
+			ca, err := defaultGenerator.generateCertificateAuthorityWithPEMKeyEx("ca", 365, caKey)
+			require.NoError(t, err)
+			key, err := defaultGenerator.genPrivateKeyEx("ecdsa")
+			require.NoError(t, err)
+			req, err := defaultGenerator.generateCertificateRequestWithPEMKey("/CN=node01/O=system:nodes",
+				[]interface{}{"10.23.25.101"}, []interface{}{"node01"}, key)
+			require.NoError(t, err)
+			assert.Equal(t, key, req.Key)
+
+			certPEM, err := defaultGenerator.signCertificateRequest(req.CSR, 365, ca)
+			require.NoError(t, err)
+
+			cert, err := parseCertificatePEM(certPEM)
+			require.NoError(t, err)
+			caCert, err := parseCertificatePEM(ca.Cert)
+			require.NoError(t, err)
+			require.NoError(t, cert.CheckSignatureFrom(caCert))
+			assert.Equal(t, "node01", cert.Subject.CommonName)
+			assert.Equal(t, []string{"system:nodes"}, cert.Subject.Organization)
+			assert.Equal(t, []string{"node01"}, cert.DNSNames)
+			assert.Equal(t, "10.23.25.101", cert.IPAddresses[0].String())
+			assert.Zero(t, cert.KeyUsage&x509.KeyUsageKeyEncipherment)
+			assert.False(t, cert.IsCA)
+		})
+	}
+}
+
+func TestSignCSRErrors(t *testing.T) {
+	ca, err := defaultGenerator.generateCertificateAuthorityEx("ca", 365)
+	require.NoError(t, err)
+	req, err := defaultGenerator.generateCertificateRequest("node01", nil, nil)
+	require.NoError(t, err)
+
+	_, err = defaultGenerator.signCertificateRequest(ca.Cert, 365, ca)
+	assert.EqualError(t, err, "unable to decode certificate request")
+
+	_, err = defaultGenerator.signCertificateRequest(req.CSR, 365, Certificate{Cert: ca.Cert})
+	assert.EqualError(t, err, "error parsing private key: no PEM data in input")
+
+	_, err = defaultGenerator.generateCertificateRequestWithPEMKey("node01", nil, nil, ca.Cert)
+	assert.EqualError(t, err, "parsing private key: no private key data in PEM block of type CERTIFICATE")
+}
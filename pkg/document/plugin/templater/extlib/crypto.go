@@ -16,7 +16,6 @@ package extlib
 
 import (
 	"bytes"
-	"errors"
 	"fmt"
 	"strings"
 	"unicode/utf8"
@@ -28,11 +27,15 @@ import (
 	"crypto/x509/pkix"
 	"encoding/pem"
 	"math/big"
+	"net"
 	"time"
 
 	"golang.org/x/crypto/ssh"
 )
 
+// defaultKeyBits is the size of RSA keys generated by template functions
+const defaultKeyBits = 2048
+
 func toUint32(i int) uint32 { return uint32(i) }
 
 type dnParser struct {
@@ -43,7 +46,8 @@ type dnParser struct {
 	dn    []string
 }
 
-type sshKey struct {
+// SSHKey is SSH key pair, the private key is PEM encoded and the public key is in authorized_keys format
+type SSHKey struct {
 	Private string
 	Public  string
 }
@@ -213,24 +217,49 @@ func nameFromDn(dn []string) (*pkix.Name, error) {
 	return &name, nil
 }
 
-func generateCertificateAuthorityEx(
+// GenerateCertificateAuthority generates self-signed CA certificate for the subject with new RSA key
+// of keyBits size, it's the same certificate genCAEx template function generates
+func GenerateCertificateAuthority(subject pkix.Name, daysValid, keyBits int) (Certificate, error) {
+	return defaultGenerator.certificateAuthority(subject, daysValid, keyBits)
+}
+
+// GenerateSignedCertificate generates certificate for the subject, IP addresses and DNS names signed
+// by ca with new RSA key of keyBits size, it's the same certificate genSignedCertEx template function generates
+func GenerateSignedCertificate(subject pkix.Name, ipAddresses []net.IP, dnsNames []string,
+	daysValid, keyBits int, ca Certificate) (Certificate, error) {
+	return defaultGenerator.signedCertificate(subject, ipAddresses, dnsNames, daysValid, keyBits, ca)
+}
+
+// GenerateSSHKeyPair generates SSH key pair with RSA key of keyBits size, see genSSHKeyPair template function
+func GenerateSSHKeyPair(keyBits int) (SSHKey, error) {
+	return defaultGenerator.genSSHKeyPair(keyBits)
+}
+
+func (g *generator) generateCertificateAuthorityEx(
 	subj string,
 	daysValid int,
-) (certificate, error) {
-	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+) (Certificate, error) {
+	name, err := nameFromString(subj)
 	if err != nil {
-		return certificate{}, fmt.Errorf("error generating rsa key: %s", err)
+		return Certificate{}, err
 	}
+	return g.certificateAuthority(*name, daysValid, defaultKeyBits)
+}
 
-	return generateCertificateAuthorityWithKeyInternalEx(subj, daysValid, priv)
+func (g *generator) certificateAuthority(subject pkix.Name, daysValid, keyBits int) (Certificate, error) {
+	priv, err := rsa.GenerateKey(g.random, keyBits)
+	if err != nil {
+		return Certificate{}, fmt.Errorf("error generating rsa key: %s", err)
+	}
+	return g.generateCertificateAuthorityWithKeyInternalEx(subject, daysValid, priv)
 }
 
 // genSSHKeyPair make a pair of public and private keys for SSH access.
 // Public key is encoded in the format for inclusion in an OpenSSH authorized_keys file.
 // Private Key generated is PEM encoded
-func genSSHKeyPair(encryptionBit int) (sshKey, error) {
-	key := sshKey{}
-	privateKey, err := rsa.GenerateKey(rand.Reader, encryptionBit)
+func (g *generator) genSSHKeyPair(encryptionBit int) (SSHKey, error) {
+	key := SSHKey{}
+	privateKey, err := rsa.GenerateKey(g.random, encryptionBit)
 	if err != nil {
 		return key, err
 	}
@@ -253,26 +282,30 @@ func genSSHKeyPair(encryptionBit int) (sshKey, error) {
 	return key, nil
 }
 
-func generateCertificateAuthorityWithPEMKeyEx(
+func (g *generator) generateCertificateAuthorityWithPEMKeyEx(
 	subj string,
 	daysValid int,
 	privPEM string,
-) (certificate, error) {
+) (Certificate, error) {
 	priv, err := parsePrivateKeyPEM(privPEM)
 	if err != nil {
-		return certificate{}, fmt.Errorf("parsing private key: %s", err)
+		return Certificate{}, fmt.Errorf("parsing private key: %s", err)
 	}
-	return generateCertificateAuthorityWithKeyInternalEx(subj, daysValid, priv)
+	name, err := nameFromString(subj)
+	if err != nil {
+		return Certificate{}, err
+	}
+	return g.generateCertificateAuthorityWithKeyInternalEx(*name, daysValid, priv)
 }
 
-func generateCertificateAuthorityWithKeyInternalEx(
-	subj string,
+func (g *generator) generateCertificateAuthorityWithKeyInternalEx(
+	subject pkix.Name,
 	daysValid int,
 	priv crypto.PrivateKey,
-) (certificate, error) {
-	ca := certificate{}
+) (Certificate, error) {
+	ca := Certificate{}
 
-	template, err := getBaseCertTemplateEx(subj, nil, nil, daysValid)
+	template, err := g.certTemplate(subject, nil, nil, daysValid)
 	if err != nil {
 		return ca, err
 	}
@@ -282,75 +315,81 @@ func generateCertificateAuthorityWithKeyInternalEx(
 		x509.KeyUsageCertSign
 	template.IsCA = true
 
-	ca.Cert, ca.Key, err = getCertAndKey(template, priv, template, priv)
+	ca.Cert, ca.Key, err = getCertAndKey(g.random, template, priv, template, priv)
 
 	return ca, err
 }
 
-func generateSignedCertificateEx(
+func (g *generator) generateSignedCertificateEx(
 	subj string,
 	ips []interface{},
 	alternateDNS []interface{},
 	daysValid int,
-	ca certificate,
-) (certificate, error) {
-	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	ca Certificate,
+) (Certificate, error) {
+	name, ipAddresses, dnsNames, err := parseCertSubject(subj, ips, alternateDNS)
+	if err != nil {
+		return Certificate{}, err
+	}
+	return g.signedCertificate(*name, ipAddresses, dnsNames, daysValid, defaultKeyBits, ca)
+}
+
+func (g *generator) signedCertificate(
+	subject pkix.Name,
+	ipAddresses []net.IP,
+	dnsNames []string,
+	daysValid int,
+	keyBits int,
+	ca Certificate,
+) (Certificate, error) {
+	priv, err := rsa.GenerateKey(g.random, keyBits)
 	if err != nil {
-		return certificate{}, fmt.Errorf("error generating rsa key: %s", err)
+		return Certificate{}, fmt.Errorf("error generating rsa key: %s", err)
 	}
-	return generateSignedCertificateWithKeyInternalEx(subj, ips, alternateDNS, daysValid, ca, priv)
+	return g.generateSignedCertificateWithKeyInternalEx(subject, ipAddresses, dnsNames, daysValid, ca, priv)
 }
 
-func generateSignedCertificateWithPEMKeyEx(
+func (g *generator) generateSignedCertificateWithPEMKeyEx(
 	subj string,
 	ips []interface{},
 	alternateDNS []interface{},
 	daysValid int,
-	ca certificate,
+	ca Certificate,
 	privPEM string,
-) (certificate, error) {
+) (Certificate, error) {
 	priv, err := parsePrivateKeyPEM(privPEM)
 	if err != nil {
-		return certificate{}, fmt.Errorf("parsing private key: %s", err)
+		return Certificate{}, fmt.Errorf("parsing private key: %s", err)
 	}
-	return generateSignedCertificateWithKeyInternalEx(subj, ips, alternateDNS, daysValid, ca, priv)
+	name, ipAddresses, dnsNames, err := parseCertSubject(subj, ips, alternateDNS)
+	if err != nil {
+		return Certificate{}, err
+	}
+	return g.generateSignedCertificateWithKeyInternalEx(*name, ipAddresses, dnsNames, daysValid, ca, priv)
 }
 
-func generateSignedCertificateWithKeyInternalEx(
-	subj string,
-	ips []interface{},
-	alternateDNS []interface{},
+func (g *generator) generateSignedCertificateWithKeyInternalEx(
+	subject pkix.Name,
+	ipAddresses []net.IP,
+	dnsNames []string,
 	daysValid int,
-	ca certificate,
+	ca Certificate,
 	priv crypto.PrivateKey,
-) (certificate, error) {
-	cert := certificate{}
+) (Certificate, error) {
+	cert := Certificate{}
 
-	decodedSignerCert, _ := pem.Decode([]byte(ca.Cert))
-	if decodedSignerCert == nil {
-		return cert, errors.New("unable to decode certificate")
-	}
-	signerCert, err := x509.ParseCertificate(decodedSignerCert.Bytes)
+	signerCert, signerKey, err := parseCA(ca)
 	if err != nil {
-		return cert, fmt.Errorf(
-			"error parsing certificate: decodedSignerCert.Bytes: %s",
-			err,
-		)
-	}
-	signerKey, err := parsePrivateKeyPEM(ca.Key)
-	if err != nil {
-		return cert, fmt.Errorf(
-			"error parsing private key: %s",
-			err,
-		)
+		return cert, err
 	}
 
-	template, err := getBaseCertTemplateEx(subj, ips, alternateDNS, daysValid)
+	template, err := g.certTemplate(subject, ipAddresses, dnsNames, daysValid)
 	if err != nil {
 		return cert, err
 	}
 
 	cert.Cert, cert.Key, err = getCertAndKey(
+		g.random,
 		template,
 		priv,
 		signerCert,
@@ -360,36 +399,49 @@ func generateSignedCertificateWithKeyInternalEx(
 	return cert, err
 }
 
-func getBaseCertTemplateEx(
+// parseCertSubject returns the subject, IP addresses and DNS names of certificate passed to template functions
+func parseCertSubject(
 	subj string,
 	ips []interface{},
 	alternateDNS []interface{},
-	daysValid int,
-) (*x509.Certificate, error) {
+) (*pkix.Name, []net.IP, []string, error) {
 	ipAddresses, err := getNetIPs(ips)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 	dnsNames, err := getAlternateDNSStrs(alternateDNS)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
+	}
+	name, err := nameFromString(subj)
+	if err != nil {
+		return nil, nil, nil, err
 	}
+	return name, ipAddresses, dnsNames, nil
+}
+
+func (g *generator) certTemplate(
+	name pkix.Name,
+	ipAddresses []net.IP,
+	dnsNames []string,
+	daysValid int,
+) (*x509.Certificate, error) {
 	serialNumberUpperBound := new(big.Int).Lsh(big.NewInt(1), 128)
-	serialNumber, err := rand.Int(rand.Reader, serialNumberUpperBound)
+	serialNumber, err := rand.Int(g.random, serialNumberUpperBound)
 	if err != nil {
 		return nil, err
 	}
-	name, err := nameFromString(subj)
+	now, err := g.now()
 	if err != nil {
 		return nil, err
 	}
 	return &x509.Certificate{
 		SerialNumber: serialNumber,
-		Subject:      *name,
+		Subject:      name,
 		IPAddresses:  ipAddresses,
 		DNSNames:     dnsNames,
-		NotBefore:    time.Now(),
-		NotAfter:     time.Now().Add(time.Hour * 24 * time.Duration(daysValid)),
+		NotBefore:    now,
+		NotAfter:     now.Add(time.Hour * 24 * time.Duration(daysValid)),
 		KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
 		ExtKeyUsage: []x509.ExtKeyUsage{
 			x509.ExtKeyUsageServerAuth,
@@ -170,7 +170,7 @@ func TestNameFromString(t *testing.T) {
 }
 
 func TestGenSSHKeyPair(t *testing.T) {
-	key, err := genSSHKeyPair(2048)
+	key, err := defaultGenerator.genSSHKeyPair(2048)
 	assert.Nil(t, err)
 	assert.NotNil(t, key.Private)
 	assert.NotNil(t, key.Public)
@@ -28,12 +28,18 @@ func GenericFuncMap() template.FuncMap {
 }
 
 var genericMap = map[string]interface{}{
-	"genCAEx":                generateCertificateAuthorityEx,
-	"genCAWithKeyEx":         generateCertificateAuthorityWithPEMKeyEx,
-	"genSignedCertEx":        generateSignedCertificateEx,
-	"genSignedCertWithKeyEx": generateSignedCertificateWithPEMKeyEx,
-	"genSSHKeyPair":          genSSHKeyPair,
-	"regexGen":               regexGen,
+	"genCAEx":                defaultGenerator.generateCertificateAuthorityEx,
+	"genCAWithKeyEx":         defaultGenerator.generateCertificateAuthorityWithPEMKeyEx,
+	"genSignedCertEx":        defaultGenerator.generateSignedCertificateEx,
+	"genSignedCertWithKeyEx": defaultGenerator.generateSignedCertificateWithPEMKeyEx,
+	"genSSHKeyPair":          defaultGenerator.genSSHKeyPair,
+	"genPrivateKeyEx":        defaultGenerator.genPrivateKeyEx,
+	"genCSR":                 defaultGenerator.generateCertificateRequest,
+	"genCSRWithKey":          defaultGenerator.generateCertificateRequestWithPEMKey,
+	"signCSR":                defaultGenerator.signCertificateRequest,
+	"decodeCert":             decodeCert,
+	"certExpiresWithin":      defaultGenerator.certExpiresWithin,
+	"regexGen":               defaultGenerator.regexGen,
 	"toYaml":                 toYaml,
 	"toUint32":               toUint32,
 	"YFilter":                yFilter,
@@ -47,4 +53,24 @@ var genericMap = map[string]interface{}{
 	"YMerge":                 yMerge,
 	"StrToY":                 strToY,
 	"YListAppend":            yListAppend,
+	"cidrHost":               cidrHost,
+	"cidrNetwork":            cidrNetwork,
+	"cidrNetmask":            cidrNetmask,
+	"cidrPrefix":             cidrPrefix,
+	"cidrContains":           cidrContains,
+	"cidrSubnet":             cidrSubnet,
+	"cidrSplit":              cidrSplit,
+	"ipAdd":                  ipAdd,
+	"ipRange":                ipRange,
+	"ipCIDR":                 ipCIDR,
+	"ipVersion":              ipVersion,
+	"ipNormalize":            ipNormalize,
+	"ipv6Expand":             ipv6Expand,
+	"eui64Address":           eui64Address,
+	"genMAC":                 genMAC,
+	"isIP":                   isIP,
+	"isIPv4":                 isIPv4,
+	"isIPv6":                 isIPv6,
+	"isCIDR":                 isCIDR,
+	"isMAC":                  isMAC,
 }
@@ -0,0 +1,87 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package extlib
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"io"
+	"math/big"
+	"time"
+
+	"golang.org/x/crypto/chacha20"
+	"golang.org/x/crypto/hkdf"
+)
+
+// defaultGenerator uses secure randomness and the current time
+var defaultGenerator = &generator{
+	random: rand.Reader,
+	now:    func() (time.Time, error) { return time.Now(), nil },
+}
+
+// generator is the source of randomness and time for the functions generating
+// random values, keys and certificates
+type generator struct {
+	// random is used to generate random values, keys and serial numbers of certificates
+	random io.Reader
+	// seeded is true if random is the key stream of strict mode
+	seeded bool
+	// now returns the time certificates are valid from
+	now func() (time.Time, error)
+}
+
+// newSeededGenerator returns generator whose random values are read from ChaCha20 key stream
+// keyed by HKDF of seed and info, so the generators of different info don't share the stream,
+// and the key stream is as hard to predict as the seed is
+func newSeededGenerator(seed, info string, now func() (time.Time, error)) *generator {
+	key := make([]byte, chacha20.KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, []byte(seed), nil, []byte(info)), key); err != nil {
+		// never happens, HKDF fails only if more than 255 hashes are read
+		panic(err)
+	}
+	// the key is used for a single stream, so the nonce doesn't have to be unique
+	cipher, err := chacha20.NewUnauthenticatedCipher(key, make([]byte, chacha20.NonceSize))
+	if err != nil {
+		// never happens, the sizes of the key and the nonce are fixed
+		panic(err)
+	}
+	return &generator{
+		random: keyStream{cipher},
+		seeded: true,
+		now:    now,
+	}
+}
+
+// intn returns uniformly distributed random integer in [0, n)
+func (g *generator) intn(n int) (int, error) {
+	i, err := rand.Int(g.random, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(i.Int64()), nil
+}
+
+// keyStream reads the key stream of the cipher
+type keyStream struct {
+	cipher *chacha20.Cipher
+}
+
+func (s keyStream) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	s.cipher.XORKeyStream(p, p)
+	return len(p), nil
+}
@@ -0,0 +1,373 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package extlib
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// maxExpansion limits the number of addresses or subnets a single call can return
+const maxExpansion = 65536
+
+// cidrHost returns n-th address of the network, negative n counts from the end of the network
+func cidrHost(cidr string, n int) (string, error) {
+	network, err := parseCIDR(cidr)
+	if err != nil {
+		return "", err
+	}
+	ones, bits := network.Mask.Size()
+	size := new(big.Int).Lsh(big.NewInt(1), uint(bits-ones))
+	offset := big.NewInt(int64(n))
+	if n < 0 {
+		offset.Add(offset, size)
+	}
+	if offset.Sign() < 0 || offset.Cmp(size) >= 0 {
+		return "", fmt.Errorf("host number %d is out of range of %s", n, cidr)
+	}
+	ip, err := intToIP(offset.Add(offset, ipToInt(network.IP)), len(network.IP))
+	if err != nil {
+		return "", err
+	}
+	return ip.String(), nil
+}
+
+// cidrNetwork returns the network address
+func cidrNetwork(cidr string) (string, error) {
+	network, err := parseCIDR(cidr)
+	if err != nil {
+		return "", err
+	}
+	return network.IP.String(), nil
+}
+
+// cidrNetmask returns the netmask of the network in the address format
+func cidrNetmask(cidr string) (string, error) {
+	network, err := parseCIDR(cidr)
+	if err != nil {
+		return "", err
+	}
+	return net.IP(network.Mask).String(), nil
+}
+
+// cidrPrefix returns the prefix length of the network
+func cidrPrefix(cidr string) (int, error) {
+	network, err := parseCIDR(cidr)
+	if err != nil {
+		return 0, err
+	}
+	ones, _ := network.Mask.Size()
+	return ones, nil
+}
+
+// cidrContains checks if the address belongs to the network
+func cidrContains(cidr, address string) (bool, error) {
+	network, err := parseCIDR(cidr)
+	if err != nil {
+		return false, err
+	}
+	ip, err := parseIP(address)
+	if err != nil {
+		return false, err
+	}
+	return network.Contains(ip), nil
+}
+
+// cidrSubnet returns netnum-th subnet of the network whose prefix is extended by newbits
+func cidrSubnet(cidr string, newbits, netnum int) (string, error) {
+	network, err := parseCIDR(cidr)
+	if err != nil {
+		return "", err
+	}
+	if err = checkNewBits(network, newbits); err != nil {
+		return "", err
+	}
+	if netnum < 0 || big.NewInt(int64(netnum)).BitLen() > newbits {
+		return "", fmt.Errorf("subnet number %d is out of range of %s extended by %d bits", netnum, cidr, newbits)
+	}
+	subnet, err := subnetOf(network, newbits, big.NewInt(int64(netnum)))
+	if err != nil {
+		return "", err
+	}
+	return subnet.String(), nil
+}
+
+// cidrSplit splits the network into all its subnets of the prefix length
+func cidrSplit(cidr string, prefix int) ([]string, error) {
+	network, err := parseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+	ones, _ := network.Mask.Size()
+	newbits := prefix - ones
+	if err = checkNewBits(network, newbits); err != nil {
+		return nil, err
+	}
+	if newbits >= 64 || 1<<uint(newbits) > maxExpansion {
+		return nil, fmt.Errorf("splitting %s into /%d subnets gives more than %d subnets", cidr, prefix, maxExpansion)
+	}
+
+	subnets := make([]string, 0, 1<<uint(newbits))
+	for i := int64(0); i < 1<<uint(newbits); i++ {
+		subnet, err := subnetOf(network, newbits, big.NewInt(i))
+		if err != nil {
+			return nil, err
+		}
+		subnets = append(subnets, subnet.String())
+	}
+	return subnets, nil
+}
+
+// ipAdd returns the address shifted by n
+func ipAdd(address string, n int) (string, error) {
+	ip, err := parseIP(address)
+	if err != nil {
+		return "", err
+	}
+	result, err := intToIP(new(big.Int).Add(ipToInt(ip), big.NewInt(int64(n))), len(ip))
+	if err != nil {
+		return "", fmt.Errorf("%s shifted by %d is out of the address space", address, n)
+	}
+	return result.String(), nil
+}
+
+// ipRange returns all addresses from start to end inclusive
+func ipRange(start, end string) ([]string, error) {
+	first, err := parseIP(start)
+	if err != nil {
+		return nil, err
+	}
+	last, err := parseIP(end)
+	if err != nil {
+		return nil, err
+	}
+	if len(first) != len(last) {
+		return nil, fmt.Errorf("range %s - %s mixes IPv4 and IPv6 addresses", start, end)
+	}
+	count := new(big.Int).Sub(ipToInt(last), ipToInt(first))
+	if count.Sign() < 0 {
+		return nil, fmt.Errorf("range %s - %s ends before it starts", start, end)
+	}
+	if count.Cmp(big.NewInt(maxExpansion)) >= 0 {
+		return nil, fmt.Errorf("range %s - %s has more than %d addresses", start, end, maxExpansion)
+	}
+
+	addresses := make([]string, 0, count.Int64()+1)
+	for i := int64(0); i <= count.Int64(); i++ {
+		ip, err := intToIP(new(big.Int).Add(ipToInt(first), big.NewInt(i)), len(first))
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, ip.String())
+	}
+	return addresses, nil
+}
+
+// ipCIDR returns the address in CIDR notation with the prefix length of the netmask,
+// e.g. to combine the address and the netmask of NetworkCatalogue
+func ipCIDR(address, netmask string) (string, error) {
+	ip, err := parseIP(address)
+	if err != nil {
+		return "", err
+	}
+	mask, err := parseIP(netmask)
+	if err != nil {
+		return "", err
+	}
+	if len(ip) != len(mask) {
+		return "", fmt.Errorf("netmask %s doesn't match address %s", netmask, address)
+	}
+	ones, bits := net.IPMask(mask).Size()
+	if bits == 0 {
+		return "", fmt.Errorf("netmask %s is not contiguous", netmask)
+	}
+	return fmt.Sprintf("%s/%d", ip, ones), nil
+}
+
+// ipVersion returns 4 or 6 depending on the address family
+func ipVersion(address string) (int, error) {
+	ip, err := parseIP(address)
+	if err != nil {
+		return 0, err
+	}
+	if len(ip) == net.IPv4len {
+		return 4, nil
+	}
+	return 6, nil
+}
+
+// ipNormalize returns the canonical form of the address, IPv6 addresses are compressed
+func ipNormalize(address string) (string, error) {
+	ip, err := parseIP(address)
+	if err != nil {
+		return "", err
+	}
+	return ip.String(), nil
+}
+
+// ipv6Expand returns IPv6 address with all 8 groups of 4 hex digits
+func ipv6Expand(address string) (string, error) {
+	ip, err := parseIP(address)
+	if err != nil {
+		return "", err
+	}
+	if len(ip) != net.IPv6len {
+		return "", fmt.Errorf("%s is not IPv6 address", address)
+	}
+	groups := make([]string, 0, net.IPv6len/2)
+	for i := 0; i < net.IPv6len; i += 2 {
+		groups = append(groups, fmt.Sprintf("%02x%02x", ip[i], ip[i+1]))
+	}
+	return strings.Join(groups, ":"), nil
+}
+
+// eui64Address returns IPv6 address of the /64 (or shorter) prefix with the interface identifier
+// derived from MAC address the same way SLAAC does it
+func eui64Address(cidr, mac string) (string, error) {
+	network, err := parseCIDR(cidr)
+	if err != nil {
+		return "", err
+	}
+	ones, bits := network.Mask.Size()
+	if bits != 8*net.IPv6len || ones > 64 {
+		return "", fmt.Errorf("%s is not IPv6 network of /64 or shorter prefix", cidr)
+	}
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return "", err
+	}
+	if len(hw) != 6 {
+		return "", fmt.Errorf("%s is not 48-bit MAC address", mac)
+	}
+
+	ip := make(net.IP, net.IPv6len)
+	copy(ip, network.IP)
+	copy(ip[8:], []byte{hw[0] ^ 0x02, hw[1], hw[2], 0xff, 0xfe, hw[3], hw[4], hw[5]})
+	return ip.String(), nil
+}
+
+// genMAC returns MAC address derived from seed, so the same seed always gives the same address.
+// The address starts with prefix octets (e.g. OUI "52:54:00") if prefix is not empty,
+// otherwise it's a locally administered unicast address
+func genMAC(prefix, seed string) (string, error) {
+	var mac []byte
+	if prefix != "" {
+		for _, octet := range strings.Split(prefix, ":") {
+			b, err := strconv.ParseUint(octet, 16, 8)
+			if err != nil || len(octet) != 2 {
+				return "", fmt.Errorf("invalid MAC prefix %q", prefix)
+			}
+			mac = append(mac, byte(b))
+		}
+		if len(mac) > 5 {
+			return "", fmt.Errorf("MAC prefix %q is too long", prefix)
+		}
+		if mac[0]&0x01 != 0 {
+			return "", fmt.Errorf("MAC prefix %q is multicast", prefix)
+		}
+	}
+
+	sum := sha256.Sum256([]byte(seed))
+	mac = append(mac, sum[:6-len(mac)]...)
+	if prefix == "" {
+		mac[0] = mac[0]&0xfe | 0x02
+	}
+	return net.HardwareAddr(mac).String(), nil
+}
+
+// isIP checks if the string is IPv4 or IPv6 address
+func isIP(s string) bool {
+	return net.ParseIP(s) != nil
+}
+
+// isIPv4 checks if the string is IPv4 address
+func isIPv4(s string) bool {
+	ip := net.ParseIP(s)
+	return ip != nil && ip.To4() != nil
+}
+
+// isIPv6 checks if the string is IPv6 address
+func isIPv6(s string) bool {
+	ip := net.ParseIP(s)
+	return ip != nil && ip.To4() == nil
+}
+
+// isCIDR checks if the string is network in CIDR notation
+func isCIDR(s string) bool {
+	_, _, err := net.ParseCIDR(s)
+	return err == nil
+}
+
+// isMAC checks if the string is 48-bit MAC address
+func isMAC(s string) bool {
+	hw, err := net.ParseMAC(s)
+	return err == nil && len(hw) == 6
+}
+
+func parseCIDR(cidr string) (*net.IPNet, error) {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+	return network, nil
+}
+
+// parseIP returns 4-byte representation of IPv4 addresses and 16-byte one of IPv6 addresses
+func parseIP(address string) (net.IP, error) {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address %q", address)
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return v4, nil
+	}
+	return ip, nil
+}
+
+func checkNewBits(network *net.IPNet, newbits int) error {
+	ones, bits := network.Mask.Size()
+	if newbits <= 0 || ones+newbits > bits {
+		return fmt.Errorf("can't extend prefix of %s by %d bits", network, newbits)
+	}
+	return nil
+}
+
+func subnetOf(network *net.IPNet, newbits int, netnum *big.Int) (*net.IPNet, error) {
+	ones, bits := network.Mask.Size()
+	offset := new(big.Int).Lsh(netnum, uint(bits-ones-newbits))
+	ip, err := intToIP(offset.Add(offset, ipToInt(network.IP)), len(network.IP))
+	if err != nil {
+		return nil, err
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(ones+newbits, bits)}, nil
+}
+
+func ipToInt(ip net.IP) *big.Int {
+	return new(big.Int).SetBytes(ip)
+}
+
+func intToIP(i *big.Int, size int) (net.IP, error) {
+	if i.Sign() < 0 || i.BitLen() > 8*size {
+		return nil, fmt.Errorf("address is out of the address space")
+	}
+	b := i.Bytes()
+	ip := make(net.IP, size)
+	copy(ip[size-len(b):], b)
+	return ip, nil
+}
@@ -0,0 +1,280 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package extlib
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCIDRHost(t *testing.T) {
+	testCases := []struct {
+		cidr        string
+		n           int
+		expectedOut string
+		expectedErr string
+	}{
+		{cidr: "10.23.25.0/24", n: 1, expectedOut: "10.23.25.1"},
+		{cidr: "10.23.25.17/24", n: 101, expectedOut: "10.23.25.101"},
+		{cidr: "10.23.25.0/24", n: -2, expectedOut: "10.23.25.254"},
+		{cidr: "fd00:10:23::/64", n: 257, expectedOut: "fd00:10:23::101"},
+		{cidr: "fd00:10:23::/64", n: -1, expectedOut: "fd00:10:23:0:ffff:ffff:ffff:ffff"},
+		{cidr: "10.23.25.0/24", n: 256, expectedErr: "host number 256 is out of range of 10.23.25.0/24"},
+		{cidr: "10.23.25.0/24", n: -257, expectedErr: "host number -257 is out of range of 10.23.25.0/24"},
+		{cidr: "10.23.25.0", n: 1, expectedErr: "invalid CIDR address: 10.23.25.0"},
+	}
+
+	for _, tc := range testCases {
+		out, err := cidrHost(tc.cidr, tc.n)
+		if tc.expectedErr != "" {
+			assert.EqualError(t, err, tc.expectedErr)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, tc.expectedOut, out)
+	}
+}
+
+func TestCIDRAttributes(t *testing.T) {
+	network, err := cidrNetwork("10.23.25.17/24")
+	require.NoError(t, err)
+	assert.Equal(t, "10.23.25.0", network)
+
+	netmask, err := cidrNetmask("10.23.25.17/22")
+	require.NoError(t, err)
+	assert.Equal(t, "255.255.252.0", netmask)
+
+	netmask, err = cidrNetmask("fd00:10:23::/48")
+	require.NoError(t, err)
+	assert.Equal(t, "ffff:ffff:ffff::", netmask)
+
+	prefix, err := cidrPrefix("fd00:10:23::/48")
+	require.NoError(t, err)
+	assert.Equal(t, 48, prefix)
+
+	contains, err := cidrContains("10.23.24.0/23", "10.23.25.200")
+	require.NoError(t, err)
+	assert.True(t, contains)
+
+	contains, err = cidrContains("10.23.24.0/23", "10.23.26.1")
+	require.NoError(t, err)
+	assert.False(t, contains)
+
+	_, err = cidrContains("10.23.24.0/23", "10.23.26")
+	assert.EqualError(t, err, `invalid IP address "10.23.26"`)
+}
+
+func TestCIDRSubnet(t *testing.T) {
+	testCases := []struct {
+		cidr        string
+		newbits     int
+		netnum      int
+		expectedOut string
+		expectedErr string
+	}{
+		{cidr: "10.96.0.0/12", newbits: 4, netnum: 0, expectedOut: "10.96.0.0/16"},
+		{cidr: "10.96.0.0/12", newbits: 4, netnum: 15, expectedOut: "10.111.0.0/16"},
+		{cidr: "fd00:10::/32", newbits: 32, netnum: 258, expectedOut: "fd00:10:0:102::/64"},
+		{
+			cidr:        "10.96.0.0/12",
+			newbits:     4,
+			netnum:      16,
+			expectedErr: "subnet number 16 is out of range of 10.96.0.0/12 extended by 4 bits",
+		},
+		{cidr: "10.96.0.0/12", newbits: 21, netnum: 0, expectedErr: "can't extend prefix of 10.96.0.0/12 by 21 bits"},
+		{cidr: "10.96.0.0/12", newbits: 0, netnum: 0, expectedErr: "can't extend prefix of 10.96.0.0/12 by 0 bits"},
+	}
+
+	for _, tc := range testCases {
+		out, err := cidrSubnet(tc.cidr, tc.newbits, tc.netnum)
+		if tc.expectedErr != "" {
+			assert.EqualError(t, err, tc.expectedErr)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, tc.expectedOut, out)
+	}
+}
+
+func TestCIDRSplit(t *testing.T) {
+	subnets, err := cidrSplit("10.23.24.0/22", 24)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.23.24.0/24", "10.23.25.0/24", "10.23.26.0/24", "10.23.27.0/24"}, subnets)
+
+	subnets, err = cidrSplit("fd00:10::/63", 64)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fd00:10::/64", "fd00:10:0:1::/64"}, subnets)
+
+	_, err = cidrSplit("10.0.0.0/8", 32)
+	assert.EqualError(t, err, "splitting 10.0.0.0/8 into /32 subnets gives more than 65536 subnets")
+
+	_, err = cidrSplit("10.0.0.0/8", 8)
+	assert.EqualError(t, err, "can't extend prefix of 10.0.0.0/8 by 0 bits")
+}
+
+func TestIPAdd(t *testing.T) {
+	testCases := []struct {
+		address     string
+		n           int
+		expectedOut string
+		expectedErr string
+	}{
+		{address: "10.23.24.255", n: 1, expectedOut: "10.23.25.0"},
+		{address: "10.23.25.0", n: -1, expectedOut: "10.23.24.255"},
+		{address: "fd00::ffff", n: 2, expectedOut: "fd00::1:1"},
+		{address: "255.255.255.255", n: 1, expectedErr: "255.255.255.255 shifted by 1 is out of the address space"},
+		{address: "::", n: -1, expectedErr: ":: shifted by -1 is out of the address space"},
+	}
+
+	for _, tc := range testCases {
+		out, err := ipAdd(tc.address, tc.n)
+		if tc.expectedErr != "" {
+			assert.EqualError(t, err, tc.expectedErr)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, tc.expectedOut, out)
+	}
+}
+
+func TestIPRange(t *testing.T) {
+	testCases := []struct {
+		start       string
+		end         string
+		expectedOut []string
+		expectedErr string
+	}{
+		{
+			start:       "10.23.24.254",
+			end:         "10.23.25.1",
+			expectedOut: []string{"10.23.24.254", "10.23.24.255", "10.23.25.0", "10.23.25.1"},
+		},
+		{start: "fd00::9", end: "fd00::b", expectedOut: []string{"fd00::9", "fd00::a", "fd00::b"}},
+		{start: "10.23.24.1", end: "10.23.24.1", expectedOut: []string{"10.23.24.1"}},
+		{start: "10.23.24.2", end: "10.23.24.1", expectedErr: "range 10.23.24.2 - 10.23.24.1 ends before it starts"},
+		{start: "10.23.24.1", end: "fd00::1", expectedErr: "range 10.23.24.1 - fd00::1 mixes IPv4 and IPv6 addresses"},
+		{start: "10.0.0.0", end: "10.1.0.0", expectedErr: "range 10.0.0.0 - 10.1.0.0 has more than 65536 addresses"},
+	}
+
+	for _, tc := range testCases {
+		out, err := ipRange(tc.start, tc.end)
+		if tc.expectedErr != "" {
+			assert.EqualError(t, err, tc.expectedErr)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, tc.expectedOut, out)
+	}
+}
+
+func TestIPCIDR(t *testing.T) {
+	testCases := []struct {
+		address     string
+		netmask     string
+		expectedOut string
+		expectedErr string
+	}{
+		{address: "10.23.25.101", netmask: "255.255.255.0", expectedOut: "10.23.25.101/24"},
+		{address: "fd00::101", netmask: "ffff:ffff:ffff:ffff::", expectedOut: "fd00::101/64"},
+		{address: "10.23.25.101", netmask: "255.0.255.0", expectedErr: "netmask 255.0.255.0 is not contiguous"},
+		{address: "10.23.25.101", netmask: "ffff::", expectedErr: "netmask ffff:: doesn't match address 10.23.25.101"},
+	}
+
+	for _, tc := range testCases {
+		out, err := ipCIDR(tc.address, tc.netmask)
+		if tc.expectedErr != "" {
+			assert.EqualError(t, err, tc.expectedErr)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, tc.expectedOut, out)
+	}
+}
+
+func TestIPv6(t *testing.T) {
+	version, err := ipVersion("10.23.25.101")
+	require.NoError(t, err)
+	assert.Equal(t, 4, version)
+
+	version, err = ipVersion("fd00::101")
+	require.NoError(t, err)
+	assert.Equal(t, 6, version)
+
+	normalized, err := ipNormalize("FD00:0000:0000:0000:0000:0000:0000:0101")
+	require.NoError(t, err)
+	assert.Equal(t, "fd00::101", normalized)
+
+	expanded, err := ipv6Expand("fd00::101")
+	require.NoError(t, err)
+	assert.Equal(t, "fd00:0000:0000:0000:0000:0000:0000:0101", expanded)
+
+	_, err = ipv6Expand("10.23.25.101")
+	assert.EqualError(t, err, "10.23.25.101 is not IPv6 address")
+
+	address, err := eui64Address("fd00:10:23:25::/64", "52:54:00:b6:ed:31")
+	require.NoError(t, err)
+	assert.Equal(t, "fd00:10:23:25:5054:ff:feb6:ed31", address)
+
+	_, err = eui64Address("fd00:10:23:25::/96", "52:54:00:b6:ed:31")
+	assert.EqualError(t, err, "fd00:10:23:25::/96 is not IPv6 network of /64 or shorter prefix")
+
+	_, err = eui64Address("10.23.25.0/24", "52:54:00:b6:ed:31")
+	assert.EqualError(t, err, "10.23.25.0/24 is not IPv6 network of /64 or shorter prefix")
+}
+
+func TestGenMAC(t *testing.T) {
+	mac, err := genMAC("", "node01")
+	require.NoError(t, err)
+	assert.True(t, isMAC(mac))
+	again, err := genMAC("", "node01")
+	require.NoError(t, err)
+	assert.Equal(t, mac, again)
+	other, err := genMAC("", "node02")
+	require.NoError(t, err)
+	assert.NotEqual(t, mac, other)
+	hw, err := net.ParseMAC(mac)
+	require.NoError(t, err)
+	assert.Equal(t, byte(0x02), hw[0]&0x03, "locally administered unicast address expected")
+
+	mac, err = genMAC("52:54:00", "node01")
+	require.NoError(t, err)
+	assert.Regexp(t, "^52:54:00(:[0-9a-f]{2}){3}$", mac)
+
+	_, err = genMAC("01:00:5e", "node01")
+	assert.EqualError(t, err, `MAC prefix "01:00:5e" is multicast`)
+
+	_, err = genMAC("52:54:0", "node01")
+	assert.EqualError(t, err, `invalid MAC prefix "52:54:0"`)
+
+	_, err = genMAC("52:54:00:00:00:00", "node01")
+	assert.EqualError(t, err, `MAC prefix "52:54:00:00:00:00" is too long`)
+}
+
+func TestValidation(t *testing.T) {
+	assert.True(t, isIP("10.23.25.101"))
+	assert.True(t, isIP("fd00::101"))
+	assert.False(t, isIP("10.23.25"))
+	assert.True(t, isIPv4("10.23.25.101"))
+	assert.False(t, isIPv4("fd00::101"))
+	assert.True(t, isIPv6("fd00::101"))
+	assert.False(t, isIPv6("10.23.25.101"))
+	assert.True(t, isCIDR("10.23.25.0/24"))
+	assert.False(t, isCIDR("10.23.25.0"))
+	assert.True(t, isMAC("52:54:00:b6:ed:31"))
+	assert.False(t, isMAC("52:54:00:b6:ed"))
+}
@@ -24,8 +24,15 @@ import (
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 
 	kfilters "sigs.k8s.io/kustomize/kyaml/kio/filters"
+
+	"opendev.org/airship/airshipctl/pkg/document/plugin/kyamlutils"
 )
 
+// yFilters are the filters YFilter builds in addition to the ones known to kyaml
+var yFilters = map[string]func() yaml.Filter{
+	"JSONPathFilter": func() yaml.Filter { return &kyamlutils.JSONPathFilter{} },
+}
+
 func kFilter(cfg string) kio.Filter {
 	k := kfilters.KFilter{}
 
@@ -92,18 +99,29 @@ func kPipe(input []*yaml.RNode, kfilters []interface{}) []*yaml.RNode {
 }
 
 func yFilter(cfg string) yaml.Filter {
-	y := yaml.YFilter{}
-
-	err := y.UnmarshalYAML(func(x interface{}) error {
+	unmarshal := func(x interface{}) error {
 		err := yaml.Unmarshal([]byte(cfg), x)
 		if err != nil {
 			log.Printf("can't unmarshal YFilter cfg yaml %s: %v", cfg, err)
 			return err
 		}
 		return nil
-	})
+	}
 
-	if err != nil {
+	meta := &yaml.ResourceMeta{}
+	if err := unmarshal(meta); err != nil {
+		return nil
+	}
+	if newFilter, found := yFilters[meta.Kind]; found {
+		filter := newFilter()
+		if err := unmarshal(filter); err != nil {
+			return nil
+		}
+		return filter
+	}
+
+	y := yaml.YFilter{}
+	if err := y.UnmarshalYAML(unmarshal); err != nil {
 		log.Printf("can't unmarshalYAML YFilter cfg %s: %v", cfg, err)
 		return nil
 	}
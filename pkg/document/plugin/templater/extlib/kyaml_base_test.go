@@ -27,6 +27,8 @@ import (
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 
 	kfilters "sigs.k8s.io/kustomize/kyaml/kio/filters"
+
+	"opendev.org/airship/airshipctl/pkg/document/plugin/kyamlutils"
 )
 
 func TestKFilter(t *testing.T) {
@@ -210,12 +212,28 @@ path: "data"
 `,
 			expectedOut: nil,
 		},
+		{
+			in: `
+kind: JSONPathFilter
+path: "{.spec.containers[?(@.image =~ '^quay.io/')].image}"
+pointers: true
+`,
+			expectedOut: &kyamlutils.JSONPathFilter{
+				Kind:     "JSONPathFilter",
+				Path:     "{.spec.containers[?(@.image =~ '^quay.io/')].image}",
+				Pointers: true,
+			},
+		},
 	}
 
 	for _, tc := range testCases {
 		out := yFilter(tc.in)
 		assert.Equal(t, tc.expectedOut, out)
 	}
+
+	// the filters of kyaml are left untouched
+	_, found := yaml.Filters["JSONPathFilter"]
+	assert.False(t, found)
 }
 
 func TestYPipe(t *testing.T) {
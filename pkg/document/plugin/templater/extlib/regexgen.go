@@ -15,17 +15,26 @@
 package extlib
 
 import (
+	"math"
+
 	"github.com/lucasjones/reggen"
 )
 
 // Generate Regex
-func regexGen(regex string, limit int) string {
+func (g *generator) regexGen(regex string, limit int) string {
 	if limit <= 0 {
 		panic("Limit cannot be less than or equal to 0")
 	}
-	str, err := reggen.Generate(regex, limit)
+	gen, err := reggen.NewGenerator(regex)
 	if err != nil {
 		panic(err)
 	}
-	return str
+	if g.seeded {
+		seed, err := g.intn(math.MaxInt64)
+		if err != nil {
+			panic(err)
+		}
+		gen.SetSeed(int64(seed))
+	}
+	return gen.Generate(limit)
 }
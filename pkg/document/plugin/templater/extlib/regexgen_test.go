@@ -43,7 +43,7 @@ func TestRegexPanicOnPattern(t *testing.T) {
 		}
 	}()
 
-	regexGen("[a-z", 1)
+	defaultGenerator.regexGen("[a-z", 1)
 }
 
 func TestRegexPanicOnLimit(t *testing.T) {
@@ -53,5 +53,5 @@ func TestRegexPanicOnLimit(t *testing.T) {
 		}
 	}()
 
-	regexGen("[a-z]{0,4}", 0)
+	defaultGenerator.regexGen("[a-z]{0,4}", 0)
 }
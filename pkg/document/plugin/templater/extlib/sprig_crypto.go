@@ -18,12 +18,12 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 
 	"crypto"
 	"crypto/dsa"
 	"crypto/ecdsa"
-	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/asn1"
@@ -32,9 +32,10 @@ import (
 	"net"
 )
 
+// Certificate is PEM encoded certificate and its private key,
 // that pieces of code were copied from
 // https://github.com/Masterminds/sprig/blob/868e7517d046cb7540e10345b09c0d70da584c8e/crypto.go#L405
-type certificate struct {
+type Certificate struct {
 	Cert string
 	Key  string
 }
@@ -47,6 +48,7 @@ type DSAKeyFormat struct {
 }
 
 func getCertAndKey(
+	random io.Reader,
 	template *x509.Certificate,
 	signeeKey crypto.PrivateKey,
 	parent *x509.Certificate,
@@ -56,8 +58,9 @@ func getCertAndKey(
 	if err != nil {
 		return "", "", fmt.Errorf("error retrieving public key from signee key: %s", err)
 	}
+	template.KeyUsage = keyUsageFor(signeePubKey, template.KeyUsage)
 	derBytes, err := x509.CreateCertificate(
-		rand.Reader,
+		random,
 		template,
 		parent,
 		signeePubKey,
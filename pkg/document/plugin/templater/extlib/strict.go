@@ -0,0 +1,174 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package extlib
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"text/template"
+	"time"
+)
+
+const (
+	alphaChars   = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	numericChars = "0123456789"
+)
+
+// StrictFuncMap returns the functions overriding the ones of GenericFuncMap and sprig in strict mode.
+// The functions accessing the environment or the network and the functions whose results can't be
+// reproduced fail, random values are read from ChaCha20 key stream keyed by seed and info, info should
+// identify the caller, so that the callers sharing the seed get different values. Keys and certificates
+// are generated from the same stream, so seed must be kept secret, and all the functions consuming the
+// stream fail if seed is empty. Crypto packages don't promise to consume the stream the same way every
+// time, so keys and certificates may differ between the runs. now is used as the current time, the
+// functions depending on it fail if it's nil
+func StrictFuncMap(seed, info string, now *time.Time) template.FuncMap {
+	getNow := func() (time.Time, error) {
+		if now == nil {
+			return time.Time{}, fmt.Errorf("current time is not available in strict mode")
+		}
+		return *now, nil
+	}
+	g := newSeededGenerator(seed, info, getNow)
+
+	funcMap := template.FuncMap{
+		"genCAEx":                g.generateCertificateAuthorityEx,
+		"genCAWithKeyEx":         g.generateCertificateAuthorityWithPEMKeyEx,
+		"genSignedCertEx":        g.generateSignedCertificateEx,
+		"genSignedCertWithKeyEx": g.generateSignedCertificateWithPEMKeyEx,
+		"genSSHKeyPair":          g.genSSHKeyPair,
+		"genPrivateKeyEx":        g.genPrivateKeyEx,
+		"genCSR":                 g.generateCertificateRequest,
+		"genCSRWithKey":          g.generateCertificateRequestWithPEMKey,
+		"signCSR":                g.signCertificateRequest,
+		"certExpiresWithin":      g.certExpiresWithin,
+		"regexGen":               g.regexGen,
+		"randAlphaNum":           g.randString(alphaChars + numericChars),
+		"randAlpha":              g.randString(alphaChars),
+		"randNumeric":            g.randString(numericChars),
+		"randAscii":              g.randASCII,
+		"randBytes":              g.randBytes,
+		"randInt":                g.randInt,
+		"shuffle":                g.shuffle,
+		"uuidv4":                 g.uuidv4,
+		"now":                    getNow,
+	}
+	for name, reason := range map[string]string{
+		"env":                      "it reads the environment",
+		"expandenv":                "it reads the environment",
+		"getHostByName":            "it accesses the network",
+		"ago":                      "it depends on the current time",
+		"encryptAES":               "its result is random",
+		"bcrypt":                   "its result is random",
+		"htpasswd":                 "its result is random",
+		"genPrivateKey":            "its result is random, use genPrivateKeyEx instead",
+		"genCA":                    "its result is random, use genCAEx instead",
+		"genCAWithKey":             "its result is random, use genCAWithKeyEx instead",
+		"genSelfSignedCert":        "its result is random, use genCAEx instead",
+		"genSelfSignedCertWithKey": "its result is random, use genCAWithKeyEx instead",
+		"genSignedCert":            "its result is random, use genSignedCertEx instead",
+		"genSignedCertWithKey":     "its result is random, use genSignedCertWithKeyEx instead",
+	} {
+		funcMap[name] = disallowed(name, reason)
+	}
+	if seed == "" {
+		for _, name := range []string{
+			"regexGen", "randAlphaNum", "randAlpha", "randNumeric", "randAscii", "randBytes", "randInt",
+			"shuffle", "uuidv4",
+		} {
+			funcMap[name] = disallowed(name, "its result is random and no secret seed is set")
+		}
+		for _, name := range []string{
+			"genCAEx", "genCAWithKeyEx", "genSignedCertEx", "genSignedCertWithKeyEx",
+			"genSSHKeyPair", "genPrivateKeyEx", "genCSR", "genCSRWithKey", "signCSR",
+		} {
+			funcMap[name] = disallowed(name, "it generates keys or certificates and no secret seed is set")
+		}
+	}
+	return funcMap
+}
+
+// disallowed returns template function which fails with the reason why it's not allowed
+func disallowed(name, reason string) func(...interface{}) (interface{}, error) {
+	return func(...interface{}) (interface{}, error) {
+		return nil, fmt.Errorf("function %s is not allowed in strict mode: %s", name, reason)
+	}
+}
+
+func (g *generator) randString(chars string) func(int) (string, error) {
+	return func(count int) (string, error) {
+		b := make([]byte, count)
+		for i := range b {
+			n, err := g.intn(len(chars))
+			if err != nil {
+				return "", err
+			}
+			b[i] = chars[n]
+		}
+		return string(b), nil
+	}
+}
+
+// randASCII returns random string of printable ASCII characters
+func (g *generator) randASCII(count int) (string, error) {
+	b := make([]byte, count)
+	for i := range b {
+		n, err := g.intn('~' - ' ' + 1)
+		if err != nil {
+			return "", err
+		}
+		b[i] = byte(' ' + n)
+	}
+	return string(b), nil
+}
+
+// randBytes returns base64 encoded random bytes
+func (g *generator) randBytes(count int) (string, error) {
+	b := make([]byte, count)
+	if _, err := io.ReadFull(g.random, b); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// randInt returns random integer in [min, max)
+func (g *generator) randInt(min, max int) (int, error) {
+	n, err := g.intn(max - min)
+	return min + n, err
+}
+
+func (g *generator) shuffle(s string) (string, error) {
+	r := []rune(s)
+	for i := len(r) - 1; i > 0; i-- {
+		j, err := g.intn(i + 1)
+		if err != nil {
+			return "", err
+		}
+		r[i], r[j] = r[j], r[i]
+	}
+	return string(r), nil
+}
+
+// uuidv4 returns random UUID of version 4
+func (g *generator) uuidv4() (string, error) {
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(g.random, b); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
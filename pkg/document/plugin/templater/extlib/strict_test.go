@@ -0,0 +1,127 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package extlib
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runStrict(tpl, seed string, now *time.Time) (string, error) {
+	return runStrictInfo(tpl, seed, "info", now)
+}
+
+func runStrictInfo(tpl, seed, info string, now *time.Time) (string, error) {
+	funcMap := GenericFuncMap()
+	for name, fn := range StrictFuncMap(seed, info, now) {
+		funcMap[name] = fn
+	}
+	t, err := template.New("test").Funcs(funcMap).Parse(tpl)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err = t.Execute(&b, nil); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func TestStrictFuncMapIsReproducible(t *testing.T) {
+	now := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	tpl := `{{ randAlphaNum 16 }} {{ randAlpha 8 }} {{ randNumeric 8 }} {{ randAscii 8 }} {{ randBytes 8 }}
+{{ randInt 1 100 }} {{ shuffle "abcdef" }} {{ uuidv4 }} {{ regexGen "[a-z]{5,10}" 10 }} {{ now }}
+`
+	first, err := runStrict(tpl, "seed", &now)
+	require.NoError(t, err)
+	second, err := runStrict(tpl, "seed", &now)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	other, err := runStrict(tpl, "other seed", &now)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, other)
+
+	otherInfo, err := runStrictInfo(tpl, "seed", "other info", &now)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, otherInfo)
+}
+
+func TestStrictFuncMapKeys(t *testing.T) {
+	now := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	tpl := `{{- $ca := genCAEx "Kubernetes API" 365 }}
+{{- $cert := genSignedCertEx "/CN=admin/O=system:masters" nil nil 365 $ca }}
+{{- $ssh := genSSHKeyPair 1024 }}
+{{- $edCA := genPrivateKeyEx "ed25519" | genCAWithKeyEx "ed25519 CA" 365 }}
+{{- $csr := genPrivateKeyEx "ecdsa" | genCSRWithKey "node01" nil nil }}
+{{ $cert.Cert }}{{ $ssh.Public }}{{ signCSR $csr.CSR 365 $edCA }}{{ certExpiresWithin $edCA.Cert 30 }}
+`
+	out, err := runStrict(tpl, "seed", &now)
+	require.NoError(t, err)
+	assert.Contains(t, out, "-----BEGIN CERTIFICATE-----")
+	assert.Contains(t, out, "ssh-rsa ")
+	assert.Contains(t, out, "false")
+}
+
+func TestStrictFuncMapDisallowed(t *testing.T) {
+	tests := []struct {
+		name        string
+		tpl         string
+		seed        string
+		expectedErr string
+	}{
+		{
+			name: "env",
+			tpl:  `{{ env "HOME" }}`,
+			seed: "seed",
+			expectedErr: `template: test:1:3: executing "test" at <env "HOME">: error calling env: ` +
+				`function env is not allowed in strict mode: it reads the environment`,
+		},
+		{
+			name: "certificate without time",
+			tpl:  `{{ genCAEx "Kubernetes API" 365 }}`,
+			seed: "seed",
+			expectedErr: `template: test:1:3: executing "test" at <genCAEx "Kubernetes API" 365>: ` +
+				`error calling genCAEx: current time is not available in strict mode`,
+		},
+		{
+			name: "random without seed",
+			tpl:  `{{ randAlphaNum 8 }}`,
+			expectedErr: `template: test:1:3: executing "test" at <randAlphaNum 8>: ` +
+				`error calling randAlphaNum: function randAlphaNum is not allowed in strict mode: ` +
+				`its result is random and no secret seed is set`,
+		},
+		{
+			name: "key without seed",
+			tpl:  `{{ genPrivateKeyEx "ed25519" }}`,
+			expectedErr: `template: test:1:3: executing "test" at <genPrivateKeyEx "ed25519">: ` +
+				`error calling genPrivateKeyEx: function genPrivateKeyEx is not allowed in strict mode: ` +
+				`it generates keys or certificates and no secret seed is set`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runStrict(tt.tpl, tt.seed, nil)
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package templater
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/template"
+	"time"
+
+	extlib "opendev.org/airship/airshipctl/pkg/document/plugin/templater/extlib"
+)
+
+const (
+	// StrictEnv is the name of env variable, if it's set to "true" all templaters work in strict mode
+	StrictEnv = "TEMPLATER_STRICT"
+	// SeedEnv is the name of env variable with the secret seed of random values used in strict mode,
+	// the random functions fail in strict mode if it's not set
+	SeedEnv = "TEMPLATER_SEED"
+	// SourceDateEpochEnv is the name of env variable with the current time in seconds since the epoch
+	// used in strict mode, see https://reproducible-builds.org/specs/source-date-epoch/
+	SourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+)
+
+// strictFuncMap returns the functions overriding the default ones in strict mode, nil is
+// returned if strict mode is disabled
+func (t *plugin) strictFuncMap() (template.FuncMap, error) {
+	if !t.Strict && os.Getenv(StrictEnv) != "true" {
+		return nil, nil
+	}
+
+	var now *time.Time
+	if epoch := os.Getenv(SourceDateEpochEnv); epoch != "" {
+		seconds, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", SourceDateEpochEnv, epoch, err)
+		}
+		sourceDate := time.Unix(seconds, 0).UTC()
+		now = &sourceDate
+	}
+	// the templaters sharing the seed get different random values
+	name := t.Name
+	if t.Namespace != "" {
+		name = t.Namespace + "/" + name
+	}
+	info := fmt.Sprintf("%s, Kind=%s %s", t.APIVersion, t.Kind, name)
+	return extlib.StrictFuncMap(os.Getenv(SeedEnv), info, now), nil
+}
@@ -19,11 +19,11 @@ import (
 	"os"
 
 	"bytes"
-	"encoding/json"
 	"fmt"
 	"text/template"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/kio/filters"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
@@ -48,6 +48,21 @@ func debug(x func()) {
 	}
 }
 
+// Process is the KRM function entrypoint, it runs the plugin configured
+// by the function config against the items of resource list
+func Process(rl *framework.ResourceList) error {
+	cfg, err := rl.FunctionConfig.Map()
+	if err != nil {
+		return err
+	}
+	fltr, err := New(cfg)
+	if err != nil {
+		return err
+	}
+	rl.Items, err = fltr.Filter(rl.Items)
+	return err
+}
+
 // New creates new instance of the plugin
 func New(obj map[string]interface{}) (kio.Filter, error) {
 	cfg := &airshipv1.Templater{}
@@ -71,8 +86,10 @@ func funcMapAppend(fma, fmb template.FuncMap) template.FuncMap {
 	return fma
 }
 
-func (t *plugin) loadModules(tmpl *template.Template, items []*yaml.RNode) ([]*yaml.RNode, error) {
-	err := kio.Pipeline{
+// loadModules parses the templates of Templater modules found in items, each into the template
+// named after the module, the texts of the modules are added to sources
+func (t *plugin) loadModules(tmpl *template.Template, items []*yaml.RNode, sources map[string]string) error {
+	return kio.Pipeline{
 		Inputs: []kio.Reader{&kio.PackageBuffer{Nodes: items}},
 		Filters: []kio.Filter{
 			filters.GrepFilter{Path: []string{"apiVersion"}, Value: "^airshipit.org/v1alpha1$"},
@@ -83,22 +100,21 @@ func (t *plugin) loadModules(tmpl *template.Template, items []*yaml.RNode) ([]*y
 					if err != nil {
 						return nil, err
 					}
-					s := yaml.GetValue(templateNode)
-					debug(func() { log.Printf("Adding module:\n%s", s) })
-					_, err = tmpl.Parse(s)
+					meta, err := node.GetMeta()
 					if err != nil {
 						return nil, err
 					}
+					s := yaml.GetValue(templateNode)
+					debug(func() { log.Printf("Adding module %s:\n%s", meta.Name, s) })
+					sources[meta.Name] = s
+					if _, err = tmpl.New(meta.Name).Parse(s); err != nil {
+						return nil, t.templateError(err, sources)
+					}
 				}
 				return o, nil
 			}),
 		},
 	}.Execute()
-	if err != nil {
-		return nil, err
-	}
-
-	return items, nil
 }
 
 func (t *plugin) Filter(items []*yaml.RNode) ([]*yaml.RNode, error) {
@@ -131,38 +147,45 @@ func (t *plugin) Filter(items []*yaml.RNode) ([]*yaml.RNode, error) {
 		return localOut.String(), nil
 	}
 	funcMap = funcMapAppend(funcMap, itemsFuncMap)
+	strictFuncMap, err := t.strictFuncMap()
+	if err != nil {
+		return nil, err
+	}
+	// strict mode functions override the default ones
+	for name, fn := range strictFuncMap {
+		funcMap[name] = fn
+	}
 	tmpl = tmpl.Funcs(funcMap)
 
-	items, err := t.loadModules(tmpl, items)
-	if err != nil {
+	sources := map[string]string{}
+	if err = t.loadModules(tmpl, items, sources); err != nil {
 		return nil, err
 	}
 
+	sources[t.Name] = t.Template
 	tmpl, err = tmpl.Parse(t.Template)
 	if err != nil {
-		return nil, err
+		return nil, t.templateError(err, sources)
 	}
 
-	var values interface{}
-
-	if t.Values != nil {
-		if err = json.Unmarshal(t.Values.Raw, &values); err != nil {
-			return nil, err
-		}
+	values, err := t.values(items)
+	if err != nil {
+		return nil, err
 	}
 
 	if err = tmpl.Execute(out, values); err != nil {
-		return nil, err
+		return nil, t.templateError(err, sources)
 	}
 	debug(func() { log.Printf("Templater out is:\n%s", out.String()) })
 
+	rendered := out.Bytes()
 	p := kio.Pipeline{
-		Inputs:  []kio.Reader{&kio.ByteReader{Reader: out}},
+		Inputs:  []kio.Reader{&kio.ByteReader{Reader: bytes.NewReader(rendered)}},
 		Outputs: []kio.Writer{&kio.PackageBuffer{}},
 	}
 	err = p.Execute()
 	if err != nil {
-		return nil, err
+		return nil, t.renderedYAMLError(rendered, err)
 	}
 
 	res, ok := p.Outputs[0].(*kio.PackageBuffer)
@@ -36,6 +36,7 @@ func TestTemplater(t *testing.T) {
 	testCases := []struct {
 		in          string
 		cfg         string
+		seed        string
 		expectedOut string
 		expectedErr string
 	}{
@@ -134,7 +135,8 @@ values:
 template: |
   {{ toYaml ignorethisbadinput -}}
 `,
-			expectedErr: `template: notImportantHere:1: function "ignorethisbadinput" not defined`,
+			expectedErr: "templater notImportantHere: template line 1: function \"ignorethisbadinput\" not defined\n" +
+				">    1 | {{ toYaml ignorethisbadinput -}}",
 		},
 		{
 			cfg: `
@@ -144,7 +146,24 @@ metadata:
   name: notImportantHere
 template: |
   {{ end }`,
-			expectedErr: "template: notImportantHere:1: unexpected \"}\" in end",
+			expectedErr: "templater notImportantHere: template line 1: unexpected \"}\" in end\n" +
+				">    1 | {{ end }",
+		},
+		{
+			cfg: `
+apiVersion: airshipit.org/v1alpha1
+kind: Templater
+metadata:
+  name: notImportantHere
+  annotations:
+    airshipit.org/origin-file: site/hosts/templater.yaml
+    airshipit.org/origin-kustomizations: site/kustomization.yaml,site/hosts/kustomization.yaml
+template: |
+  {{ end }`,
+			expectedErr: "templater notImportantHere defined in site/hosts/templater.yaml " +
+				"(via site/kustomization.yaml -> site/hosts/kustomization.yaml): " +
+				"template line 1: unexpected \"}\" in end\n" +
+				">    1 | {{ end }",
 		},
 		{
 			cfg: `
@@ -189,9 +208,11 @@ values:
 template: |
   password: {{ regexGen .regex (.limit|int) }}
 `,
-			expectedErr: "template: notImportantHere:1:13: executing \"notImportantHere\" at " +
+			expectedErr: "templater notImportantHere: template line 1, column 14: executing \"notImportantHere\" at " +
 				"<regexGen .regex (.limit | int)>: error calling regexGen: " +
-				"Limit cannot be less than or equal to 0",
+				"Limit cannot be less than or equal to 0\n" +
+				">    1 | password: {{ regexGen .regex (.limit|int) }}\n" +
+				"                      ^",
 		},
 		{
 			cfg: `
@@ -206,9 +227,11 @@ values:
 template: |
   password: {{ regexGen .regex (.limit|int) }}
 `,
-			expectedErr: "template: notImportantHere:1:13: executing \"notImportantHere\" " +
+			expectedErr: "templater notImportantHere: template line 1, column 14: executing \"notImportantHere\" " +
 				"at <regexGen .regex (.limit | int)>: error calling " +
-				"regexGen: error parsing regexp: missing closing ]: `[a-z`",
+				"regexGen: error parsing regexp: missing closing ]: `[a-z`\n" +
+				">    1 | password: {{ regexGen .regex (.limit|int) }}\n" +
+				"                      ^",
 		},
 		// transformer tests
 		{
@@ -465,9 +488,264 @@ metadata:
   name: cfg1
 `,
 		},
+		{
+			in: `
+apiVersion: airshipit.org/v1alpha1
+kind: VersionsCatalogue
+metadata:
+  name: versions
+spec:
+  images:
+    app: app:v1
+    db: db:v1
+---
+apiVersion: airshipit.org/v1alpha1
+kind: NetworkCatalogue
+metadata:
+  name: networking
+spec:
+  images:
+    db: db:v2
+  dns: 8.8.8.8
+`,
+			cfg: `
+apiVersion: airshipit.org/v1alpha1
+kind: Templater
+metadata:
+  name: notImportantHere
+valuesFrom:
+- objref:
+    kind: VersionsCatalogue
+    name: versions
+  fieldref: spec
+- objref:
+    kind: NetworkCatalogue
+    name: networking
+  fieldref: spec
+values:
+  dns: 1.1.1.1
+template: |
+  {{- $_ := setItems list }}
+  apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: settings
+  data:
+    app: {{ .images.app }}
+    db: {{ .images.db }}
+    dns: {{ .dns }}
+`,
+			expectedOut: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  app: app:v1
+  db: db:v2
+  dns: 1.1.1.1
+`,
+		},
+		{
+			in: `
+apiVersion: airshipit.org/v1alpha1
+kind: VersionsCatalogue
+metadata:
+  name: versions
+spec:
+  images: []
+`,
+			cfg: `
+apiVersion: airshipit.org/v1alpha1
+kind: Templater
+metadata:
+  name: notImportantHere
+valuesFrom:
+- objref:
+    kind: VersionsCatalogue
+    name: other
+template: ""
+`,
+			expectedErr: "valuesFrom document identified by kind \"VersionsCatalogue\" name \"other\" " +
+				"namespace \"\" not found",
+		},
+		{
+			in: `
+apiVersion: airshipit.org/v1alpha1
+kind: VersionsCatalogue
+metadata:
+  name: versions
+spec:
+  images: []
+`,
+			cfg: `
+apiVersion: airshipit.org/v1alpha1
+kind: Templater
+metadata:
+  name: notImportantHere
+valuesFrom:
+- objref:
+    kind: VersionsCatalogue
+    name: versions
+  fieldref: spec.images
+template: ""
+`,
+			expectedErr: "field spec.images of valuesFrom document versions must be a map, got []interface {}",
+		},
+		{
+			in: `
+apiVersion: airshipit.org/v1alpha1
+kind: VersionsCatalogue
+metadata:
+  name: versions
+  namespace: one
+spec: {}
+---
+apiVersion: airshipit.org/v1alpha1
+kind: VersionsCatalogue
+metadata:
+  name: versions
+  namespace: two
+spec: {}
+`,
+			cfg: `
+apiVersion: airshipit.org/v1alpha1
+kind: Templater
+metadata:
+  name: notImportantHere
+valuesFrom:
+- objref:
+    kind: VersionsCatalogue
+    name: versions
+template: ""
+`,
+			expectedErr: "valuesFrom must reference exactly one document, found 2 documents " +
+				"identified by kind \"VersionsCatalogue\" name \"versions\" namespace \"\"",
+		},
+		{
+			in: `
+apiVersion: airshipit.org/v1alpha1
+kind: VersionsCatalogue
+metadata:
+  name: versions
+spec: {}
+`,
+			cfg: `
+apiVersion: airshipit.org/v1alpha1
+kind: Templater
+metadata:
+  name: notImportantHere
+valuesFrom:
+- objref:
+    kind: VersionsCatalogue
+    name: versions
+  fieldref: spec.images
+template: ""
+`,
+			expectedErr: "field spec.images of valuesFrom document versions not found",
+		},
+		{
+			cfg: `
+apiVersion: airshipit.org/v1alpha1
+kind: Templater
+metadata:
+  name: notImportantHere
+strict: true
+template: |
+  password: {{ randAlphaNum 8 }}
+`,
+			seed: "test-seed",
+			expectedOut: `password: UARqffSb
+`,
+		},
+		{
+			cfg: `
+apiVersion: airshipit.org/v1alpha1
+kind: Templater
+metadata:
+  name: notImportantHere
+strict: true
+template: |
+  key: {{ genSSHKeyPair 1024 }}
+`,
+			expectedErr: "templater notImportantHere: template line 1, column 9: executing \"notImportantHere\" " +
+				"at <genSSHKeyPair 1024>: error calling genSSHKeyPair: function genSSHKeyPair " +
+				"is not allowed in strict mode: it generates keys or certificates and no secret seed is set\n" +
+				">    1 | key: {{ genSSHKeyPair 1024 }}\n" +
+				"                 ^",
+		},
+		{
+			cfg: `
+apiVersion: airshipit.org/v1alpha1
+kind: Templater
+metadata:
+  name: notImportantHere
+strict: true
+template: |
+  home: {{ env "HOME" }}
+`,
+			expectedErr: "templater notImportantHere: template line 1, column 10: executing \"notImportantHere\" " +
+				"at <env \"HOME\">: error calling env: function env is not allowed in strict mode: " +
+				"it reads the environment\n" +
+				">    1 | home: {{ env \"HOME\" }}\n" +
+				"                  ^",
+		},
+		{
+			in: `
+apiVersion: airshipit.org/v1alpha1
+kind: Templater
+metadata:
+  name: brokenModule
+template: |
+  {{ define "configMap" }}
+  apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: {{ .name }
+  {{ end }}
+`,
+			cfg: `
+apiVersion: airshipit.org/v1alpha1
+kind: Templater
+metadata:
+  name: notImportantHere
+template: |
+  {{ include "configMap" (dict "name" "cfg1") }}
+`,
+			expectedErr: "templater notImportantHere: module brokenModule line 5: unexpected \"}\" in operand\n" +
+				"     3 | kind: ConfigMap\n" +
+				"     4 | metadata:\n" +
+				">    5 |   name: {{ .name }\n" +
+				"     6 | {{ end }}",
+		},
+		{
+			cfg: `
+apiVersion: airshipit.org/v1alpha1
+kind: Templater
+metadata:
+  name: notImportantHere
+values:
+  name: "cfg: broken"
+template: |
+  apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: {{ .name }}
+  data:
+    key: value
+`,
+			expectedErr: "templater notImportantHere: rendered output is not valid YAML: " +
+				"MalformedYAMLError: yaml: line 4: mapping values are not allowed in this context\n" +
+				"     2 | kind: ConfigMap\n" +
+				"     3 | metadata:\n" +
+				">    4 |   name: cfg: broken\n" +
+				"     5 | data:\n" +
+				"     6 |   key: value",
+		},
 	}
 
+	defer os.Unsetenv(SeedEnv)
 	for _, tc := range testCases {
+		require.NoError(t, os.Setenv(SeedEnv, tc.seed))
 		cfg := make(map[string]interface{})
 		err := yaml.Unmarshal([]byte(tc.cfg), &cfg)
 		require.NoError(t, err)
@@ -0,0 +1,113 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package templater
+
+import (
+	"encoding/json"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+
+	airshipv1 "opendev.org/airship/airshipctl/pkg/api/v1alpha1"
+	"opendev.org/airship/airshipctl/pkg/document/plugin/kyamlutils"
+)
+
+// values returns the values to render the template with, the values of the documents
+// referenced by ValuesFrom are merged in order and the inline values are merged on top of them
+func (t *plugin) values(items []*yaml.RNode) (interface{}, error) {
+	var inline interface{}
+	if t.Values != nil {
+		if err := json.Unmarshal(t.Values.Raw, &inline); err != nil {
+			return nil, err
+		}
+	}
+	if len(t.ValuesFrom) == 0 {
+		return inline, nil
+	}
+
+	values := map[string]interface{}{}
+	for _, source := range t.ValuesFrom {
+		sourceValues, err := valuesFromDocument(items, source)
+		if err != nil {
+			return nil, err
+		}
+		mergeValues(values, sourceValues)
+	}
+	if inline == nil {
+		return values, nil
+	}
+	inlineValues, ok := inline.(map[string]interface{})
+	if !ok {
+		return nil, ErrValuesNotMap{Value: inline}
+	}
+	mergeValues(values, inlineValues)
+	return values, nil
+}
+
+// valuesFromDocument returns the map of values held by the field of the document referenced by source
+func valuesFromDocument(items []*yaml.RNode, source airshipv1.TemplaterValuesSource) (map[string]interface{}, error) {
+	ref := source.ObjRef
+	docs, err := kyamlutils.DocumentSelector{}.
+		ByAPIVersion(ref.APIVersion).
+		ByGVK(ref.Group, ref.Version, ref.Kind).
+		ByName(ref.Name).
+		ByNamespace(ref.Namespace).
+		Filter(items)
+	if err != nil {
+		return nil, err
+	}
+	switch len(docs) {
+	case 0:
+		return nil, ErrValuesNotFound{Kind: ref.Kind, Name: ref.Name, Namespace: ref.Namespace}
+	case 1:
+	default:
+		return nil, ErrValuesAmbiguousReference{
+			Kind: ref.Kind, Name: ref.Name, Namespace: ref.Namespace, Found: len(docs)}
+	}
+
+	node := docs[0]
+	if source.FieldRef != "" {
+		if node, err = docs[0].Pipe(kyamlutils.JSONPathFilter{Path: source.FieldRef}); err != nil {
+			return nil, err
+		}
+		if node == nil {
+			return nil, ErrValuesNotFound{
+				Kind: ref.Kind, Name: ref.Name, Namespace: ref.Namespace, Field: source.FieldRef}
+		}
+	}
+
+	var values interface{}
+	if err = node.YNode().Decode(&values); err != nil {
+		return nil, err
+	}
+	result, ok := values.(map[string]interface{})
+	if !ok {
+		return nil, ErrValuesNotMap{Name: ref.Name, Field: source.FieldRef, Value: values}
+	}
+	return result, nil
+}
+
+// mergeValues merges src into dst recursively, maps are merged key by key and
+// any other value of src replaces the value of dst
+func mergeValues(dst, src map[string]interface{}) {
+	for key, srcValue := range src {
+		srcMap, srcIsMap := srcValue.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeValues(dstMap, srcMap)
+			continue
+		}
+		dst[key] = srcValue
+	}
+}
@@ -0,0 +1,213 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package document
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/google/cel-go/cel"
+
+	"opendev.org/airship/airshipctl/pkg/api/v1alpha1"
+)
+
+// policyObjectVariable is the name of the variable holding the document in policy rules
+const policyObjectVariable = "object"
+
+// PolicyViolation is a violation of validation policy by a single document
+type PolicyViolation struct {
+	Policy    string
+	Severity  v1alpha1.PolicySeverity
+	Message   string
+	Kind      string
+	Namespace string
+	Name      string
+	// Origin is the file the document comes from, empty if unknown
+	Origin string
+}
+
+func (v PolicyViolation) String() string {
+	return fmt.Sprintf("%s: document %s violates policy %s: %s",
+		v.Severity, documentID(v.Kind, v.Namespace, v.Name, v.Origin), v.Policy, v.Message)
+}
+
+// PolicyValidator validates documents against ValidationPolicies written in CEL
+type PolicyValidator struct {
+	policies []compiledPolicy
+}
+
+type compiledPolicy struct {
+	name     string
+	spec     v1alpha1.ValidationPolicySpec
+	severity v1alpha1.PolicySeverity
+	program  cel.Program
+}
+
+// NewPolicyValidator compiles the rules of the policies, ErrInvalidPolicy is returned if any of
+// them can't be compiled or uses a language other than CEL
+func NewPolicyValidator(policies []*v1alpha1.ValidationPolicy) (*PolicyValidator, error) {
+	env, err := cel.NewEnv(cel.Variable(policyObjectVariable, cel.DynType))
+	if err != nil {
+		return nil, err
+	}
+	v := &PolicyValidator{}
+	for _, policy := range policies {
+		var compiled compiledPolicy
+		if compiled, err = compilePolicy(env, policy); err != nil {
+			return nil, err
+		}
+		v.policies = append(v.policies, compiled)
+	}
+	return v, nil
+}
+
+func compilePolicy(env *cel.Env, policy *v1alpha1.ValidationPolicy) (compiledPolicy, error) {
+	spec := policy.Spec
+	invalid := func(format string, args ...interface{}) (compiledPolicy, error) {
+		return compiledPolicy{}, ErrInvalidPolicy{Name: policy.Name, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	switch spec.Language {
+	case "", v1alpha1.PolicyLanguageCEL:
+	default:
+		return invalid("unknown policy language %q", spec.Language)
+	}
+	severity := spec.Severity
+	switch severity {
+	case "":
+		severity = v1alpha1.PolicySeverityError
+	case v1alpha1.PolicySeverityError, v1alpha1.PolicySeverityWarning, v1alpha1.PolicySeverityInfo:
+	default:
+		return invalid("unknown severity %q, must be one of %s, %s, %s", severity,
+			v1alpha1.PolicySeverityError, v1alpha1.PolicySeverityWarning, v1alpha1.PolicySeverityInfo)
+	}
+	if spec.Rule == "" {
+		return invalid("rule must be specified")
+	}
+
+	ast, issues := env.Compile(spec.Rule)
+	if issues.Err() != nil {
+		return invalid("%v", issues.Err())
+	}
+	if outputType := ast.OutputType(); outputType != cel.BoolType && outputType != cel.DynType {
+		return invalid("rule must evaluate to bool, got %s", outputType)
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return invalid("%v", err)
+	}
+	return compiledPolicy{name: policy.Name, spec: spec, severity: severity, program: program}, nil
+}
+
+// Validate returns the violations of all the policies by the documents of the bundle ordered
+// as the documents, a document violates the policy if the rule is false or can't be evaluated,
+// e.g. if the rule references a field the document doesn't have
+func (v *PolicyValidator) Validate(bundle Bundle) ([]PolicyViolation, error) {
+	docs, err := bundle.GetAllDocuments()
+	if err != nil {
+		return nil, err
+	}
+	order := make(map[string]int, len(docs))
+	for i, doc := range docs {
+		order[documentKey(doc)] = i
+	}
+
+	// violations are collected per policy and sorted by the position of the document in the bundle
+	type positioned struct {
+		violation PolicyViolation
+		position  int
+	}
+	var found []positioned
+	for _, policy := range v.policies {
+		if docs, err = policy.documents(bundle); err != nil {
+			return nil, err
+		}
+		for _, doc := range docs {
+			var message string
+			var violated bool
+			if message, violated, err = policy.evaluate(doc); err != nil {
+				return nil, err
+			}
+			if !violated {
+				continue
+			}
+			found = append(found, positioned{
+				violation: PolicyViolation{
+					Policy:    policy.name,
+					Severity:  policy.severity,
+					Message:   message,
+					Kind:      doc.GetKind(),
+					Namespace: doc.GetNamespace(),
+					Name:      doc.GetName(),
+					Origin:    documentOrigin(doc),
+				},
+				position: order[documentKey(doc)],
+			})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].position < found[j].position })
+
+	violations := make([]PolicyViolation, len(found))
+	for i, f := range found {
+		violations[i] = f.violation
+	}
+	return violations, nil
+}
+
+// documents returns the documents matching any of the selectors of the policy
+func (p compiledPolicy) documents(bundle Bundle) ([]Document, error) {
+	if len(p.spec.Match) == 0 {
+		return bundle.GetAllDocuments()
+	}
+	selector := NewSelectorFromV1Alpha1(p.spec.Match[0])
+	for _, match := range p.spec.Match[1:] {
+		selector = selector.Or(NewSelectorFromV1Alpha1(match))
+	}
+	return bundle.Select(selector)
+}
+
+// evaluate returns the violation message if the document violates the policy
+func (p compiledPolicy) evaluate(doc Document) (string, bool, error) {
+	data, err := doc.MarshalJSON()
+	if err != nil {
+		return "", false, err
+	}
+	var object map[string]interface{}
+	if err = json.Unmarshal(data, &object); err != nil {
+		return "", false, err
+	}
+
+	message := p.spec.Message
+	if message == "" {
+		message = "rule " + p.spec.Rule + " is not satisfied"
+	}
+	out, _, err := p.program.Eval(map[string]interface{}{policyObjectVariable: object})
+	if err != nil {
+		return fmt.Sprintf("%s (%v)", message, err), true, nil
+	}
+	passed, ok := out.Value().(bool)
+	if !ok {
+		return "", false, ErrInvalidPolicy{
+			Name:   p.name,
+			Reason: fmt.Sprintf("rule must evaluate to bool, got %v for document %s", out.Value(), doc.GetName()),
+		}
+	}
+	return message, !passed, nil
+}
+
+func documentKey(doc Document) string {
+	return doc.GetAPIVersion() + "/" + doc.GetKind() + "/" + doc.GetNamespace() + "/" + doc.GetName()
+}
@@ -0,0 +1,230 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package document_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+
+	"opendev.org/airship/airshipctl/pkg/api/v1alpha1"
+	"opendev.org/airship/airshipctl/pkg/document"
+)
+
+const sitePolicies = `apiVersion: airshipit.org/v1alpha1
+kind: ValidationPolicy
+metadata:
+  name: no-latest-images
+spec:
+  rule: object.spec.template.spec.containers.all(c, !c.image.endsWith(':latest'))
+  message: images must be pinned to a version
+  match:
+  - kind: Deployment
+  - kind: DaemonSet
+---
+apiVersion: airshipit.org/v1alpha1
+kind: ValidationPolicy
+metadata:
+  name: resource-limits
+spec:
+  rule: >-
+    object.spec.template.spec.containers.all(c, has(c.resources) && has(c.resources.limits))
+  message: containers must have resource limits
+  match:
+  - kind: Deployment
+---
+apiVersion: airshipit.org/v1alpha1
+kind: ValidationPolicy
+metadata:
+  name: no-node-ports
+spec:
+  rule: "!has(object.spec.type) || object.spec.type != 'NodePort'"
+  severity: warning
+  match:
+  - kind: Service
+`
+
+const policyTestDocs = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pinned
+  namespace: default
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: quay.io/airshipit/app:v1.0.0
+        resources:
+          limits:
+            cpu: 100m
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: exposed
+  namespace: default
+spec:
+  type: NodePort
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: latest
+  namespace: default
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: quay.io/airshipit/app:latest
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: no-template
+  namespace: default
+spec: {}
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: untouched
+  namespace: default
+`
+
+func loadPolicies(t *testing.T, data string) []*v1alpha1.ValidationPolicy {
+	t.Helper()
+	var policies []*v1alpha1.ValidationPolicy
+	for _, doc := range strings.Split(data, "---\n") {
+		policy := &v1alpha1.ValidationPolicy{}
+		require.NoError(t, yaml.Unmarshal([]byte(doc), policy))
+		policies = append(policies, policy)
+	}
+	return policies
+}
+
+func TestPolicyValidatorValidate(t *testing.T) {
+	validator, err := document.NewPolicyValidator(loadPolicies(t, sitePolicies))
+	require.NoError(t, err)
+	bundle, err := document.NewBundleFromBytes([]byte(policyTestDocs))
+	require.NoError(t, err)
+
+	violations, err := validator.Validate(bundle)
+	require.NoError(t, err)
+	require.Len(t, violations, 4)
+
+	assert.Equal(t, document.PolicyViolation{
+		Policy:    "no-node-ports",
+		Severity:  v1alpha1.PolicySeverityWarning,
+		Message:   "rule !has(object.spec.type) || object.spec.type != 'NodePort' is not satisfied",
+		Kind:      "Service",
+		Namespace: "default",
+		Name:      "exposed",
+	}, violations[0])
+	assert.Equal(t, "warning: document Service default/exposed violates policy no-node-ports: "+
+		"rule !has(object.spec.type) || object.spec.type != 'NodePort' is not satisfied", violations[0].String())
+
+	assert.Equal(t, document.PolicyViolation{
+		Policy:    "no-latest-images",
+		Severity:  v1alpha1.PolicySeverityError,
+		Message:   "images must be pinned to a version",
+		Kind:      "Deployment",
+		Namespace: "default",
+		Name:      "latest",
+	}, violations[1])
+	assert.Equal(t, document.PolicyViolation{
+		Policy:    "resource-limits",
+		Severity:  v1alpha1.PolicySeverityError,
+		Message:   "containers must have resource limits",
+		Kind:      "Deployment",
+		Namespace: "default",
+		Name:      "latest",
+	}, violations[2])
+
+	// the rule can't be evaluated against the document without template
+	assert.Equal(t, "no-latest-images", violations[3].Policy)
+	assert.Equal(t, "no-template", violations[3].Name)
+	assert.True(t, strings.HasPrefix(violations[3].Message, "images must be pinned to a version ("))
+}
+
+func TestNewPolicyValidatorInvalidPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        v1alpha1.ValidationPolicySpec
+		errContains string
+	}{
+		{
+			name:        "rego policy",
+			spec:        v1alpha1.ValidationPolicySpec{Language: "rego", Rule: "allow"},
+			errContains: `invalid validation policy "test": unknown policy language "rego"`,
+		},
+		{
+			name:        "unknown language",
+			spec:        v1alpha1.ValidationPolicySpec{Language: "python", Rule: "True"},
+			errContains: `unknown policy language "python"`,
+		},
+		{
+			name:        "unknown severity",
+			spec:        v1alpha1.ValidationPolicySpec{Severity: "fatal", Rule: "true"},
+			errContains: `unknown severity "fatal", must be one of error, warning, info`,
+		},
+		{
+			name:        "no rule",
+			spec:        v1alpha1.ValidationPolicySpec{},
+			errContains: "rule must be specified",
+		},
+		{
+			name:        "syntax error",
+			spec:        v1alpha1.ValidationPolicySpec{Rule: "object.kind =="},
+			errContains: "Syntax error",
+		},
+		{
+			name:        "not bool rule",
+			spec:        v1alpha1.ValidationPolicySpec{Rule: "1 + 1"},
+			errContains: "rule must evaluate to bool, got int",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &v1alpha1.ValidationPolicy{Spec: tt.spec}
+			policy.Name = "test"
+			_, err := document.NewPolicyValidator([]*v1alpha1.ValidationPolicy{policy})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestErrPolicyValidationFailed(t *testing.T) {
+	err := document.ErrPolicyValidationFailed{Violations: []document.PolicyViolation{
+		{
+			Policy:   "no-latest-images",
+			Severity: v1alpha1.PolicySeverityError,
+			Message:  "images must be pinned to a version",
+			Kind:     "Deployment",
+			Name:     "app",
+			Origin:   "manifests/site/app.yaml",
+		},
+	}}
+	assert.Equal(t, "policy validation failed with 1 violation(s):\n"+
+		"error: document Deployment app (manifests/site/app.yaml) violates policy no-latest-images: "+
+		"images must be pinned to a version", err.Error())
+}
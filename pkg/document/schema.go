@@ -0,0 +1,342 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package document
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+)
+
+const (
+	// DefaultSchemaKubernetesVersion is the version of kubernetes schemas used if none is specified
+	DefaultSchemaKubernetesVersion = "1.18.6"
+
+	preserveUnknownFieldsExtension = "x-kubernetes-preserve-unknown-fields"
+)
+
+// SchemaValidatorOptions defines where the schemas are looked up and how strict the validation is
+type SchemaValidatorOptions struct {
+	// SchemaLocation is a local directory with JSON schemas in kubeval layout, i.e.
+	// v<version>-standalone[-strict]/<kind>[-<group>]-<version>.json, schemas may be
+	// also put directly to the directory
+	SchemaLocation string
+	// KubernetesVersion is the version of kubernetes schemas, DefaultSchemaKubernetesVersion if empty
+	KubernetesVersion string
+	// Strict disallows properties which are not defined in the schema
+	Strict bool
+	// IgnoreMissingSchemas skips documents which have no schema instead of failing them
+	IgnoreMissingSchemas bool
+	// KindsToSkip lists kinds of documents that are not validated
+	KindsToSkip []string
+}
+
+// SchemaValidator validates documents against OpenAPI schemas from local schema directory and
+// the schemas of CustomResourceDefinitions, no containers or network access are required
+type SchemaValidator struct {
+	options SchemaValidatorOptions
+	// validators are cached per GVK, nil value means that there is no schema
+	validators map[schema.GroupVersionKind]*validate.SchemaValidator
+}
+
+// NewSchemaValidator returns schema validator with the given options
+func NewSchemaValidator(options SchemaValidatorOptions) *SchemaValidator {
+	if options.KubernetesVersion == "" {
+		options.KubernetesVersion = DefaultSchemaKubernetesVersion
+	}
+	return &SchemaValidator{
+		options:    options,
+		validators: map[schema.GroupVersionKind]*validate.SchemaValidator{},
+	}
+}
+
+// AddCRDs registers the schemas of all versions of apiextensions.k8s.io/v1 and v1beta1
+// CustomResourceDefinitions found in the bundle, the schemas of CRDs take precedence over
+// the ones from schema location
+func (v *SchemaValidator) AddCRDs(bundle Bundle) error {
+	docs, err := bundle.Select(NewCRDSelector())
+	if err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		switch doc.GetAPIVersion() {
+		case apiextensionsv1.SchemeGroupVersion.String():
+			err = v.addCRD(doc)
+		case apiextensionsv1beta1.SchemeGroupVersion.String():
+			err = v.addV1beta1CRD(doc)
+		default:
+			err = ErrUnsupportedCRDVersion{APIVersion: doc.GetAPIVersion(), Name: doc.GetName()}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *SchemaValidator) addCRD(doc Document) error {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := doc.ToObject(crd); err != nil {
+		return err
+	}
+	for _, version := range crd.Spec.Versions {
+		if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+			continue
+		}
+		internal := &apiextensions.JSONSchemaProps{}
+		err := apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(
+			version.Schema.OpenAPIV3Schema, internal, nil)
+		if err != nil {
+			return err
+		}
+		gvk := schema.GroupVersionKind{Group: crd.Spec.Group, Version: version.Name, Kind: crd.Spec.Names.Kind}
+		if err = v.addSchema(gvk, internal); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addV1beta1CRD registers the schemas of v1beta1 CRD, the versions without their own schema
+// use the schema of spec.validation, which may be the only version set in spec.version
+func (v *SchemaValidator) addV1beta1CRD(doc Document) error {
+	crd := &apiextensionsv1beta1.CustomResourceDefinition{}
+	if err := doc.ToObject(crd); err != nil {
+		return err
+	}
+	versions := crd.Spec.Versions
+	if len(versions) == 0 && crd.Spec.Version != "" {
+		versions = []apiextensionsv1beta1.CustomResourceDefinitionVersion{{Name: crd.Spec.Version}}
+	}
+	for _, version := range versions {
+		crdSchema := crd.Spec.Validation
+		if version.Schema != nil {
+			crdSchema = version.Schema
+		}
+		if crdSchema == nil || crdSchema.OpenAPIV3Schema == nil {
+			continue
+		}
+		internal := &apiextensions.JSONSchemaProps{}
+		err := apiextensionsv1beta1.Convert_v1beta1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(
+			crdSchema.OpenAPIV3Schema, internal, nil)
+		if err != nil {
+			return err
+		}
+		gvk := schema.GroupVersionKind{Group: crd.Spec.Group, Version: version.Name, Kind: crd.Spec.Names.Kind}
+		if err = v.addSchema(gvk, internal); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *SchemaValidator) addSchema(gvk schema.GroupVersionKind, internal *apiextensions.JSONSchemaProps) error {
+	openAPISchema := &spec.Schema{}
+	err := validation.ConvertJSONSchemaPropsWithPostProcess(internal, openAPISchema,
+		validation.StripUnsupportedFormatsPostProcess)
+	if err != nil {
+		return err
+	}
+	addTypeMetaProperties(openAPISchema)
+	v.validators[gvk] = v.newValidator(openAPISchema)
+	return nil
+}
+
+// Validate validates all the documents of the bundle, ErrSchemaValidationFailed listing every
+// invalid document is returned if any of them doesn't match its schema
+func (v *SchemaValidator) Validate(bundle Bundle) error {
+	docs, err := bundle.GetAllDocuments()
+	if err != nil {
+		return err
+	}
+	var invalid []ErrDocumentInvalid
+	for _, doc := range docs {
+		var violations []string
+		if violations, err = v.ValidateDocument(doc); err != nil {
+			return err
+		}
+		if len(violations) == 0 {
+			continue
+		}
+		invalid = append(invalid, ErrDocumentInvalid{
+			APIVersion: doc.GetAPIVersion(),
+			Kind:       doc.GetKind(),
+			Namespace:  doc.GetNamespace(),
+			Name:       doc.GetName(),
+			Origin:     documentOrigin(doc),
+			Errors:     violations,
+		})
+	}
+	if len(invalid) > 0 {
+		return ErrSchemaValidationFailed{Documents: invalid}
+	}
+	return nil
+}
+
+// documentOrigin returns the origin of the document if it is known, or the file it was read from
+func documentOrigin(doc Document) string {
+	if origin, ok := doc.GetOrigin(); ok {
+		return origin.String()
+	}
+	return doc.GetAnnotations()[kioutil.PathAnnotation]
+}
+
+// ValidateDocument returns the list of schema violations of the document
+func (v *SchemaValidator) ValidateDocument(doc Document) ([]string, error) {
+	for _, kind := range v.options.KindsToSkip {
+		if kind == doc.GetKind() {
+			return nil, nil
+		}
+	}
+
+	gvk := schema.FromAPIVersionAndKind(doc.GetAPIVersion(), doc.GetKind())
+	validator, err := v.validator(gvk)
+	if err != nil {
+		return nil, err
+	}
+	if validator == nil {
+		if v.options.IgnoreMissingSchemas {
+			return nil, nil
+		}
+		return []string{"schema for " + gvk.String() + " not found"}, nil
+	}
+
+	data, err := doc.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var obj interface{}
+	if err = json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	var violations []string
+	for _, violation := range validator.Validate(obj).Errors {
+		// errors of the top level fields start with a dot
+		violations = append(violations, strings.TrimPrefix(violation.Error(), "."))
+	}
+	sort.Strings(violations)
+	return violations, nil
+}
+
+// validator returns cached validator for the GVK or loads its schema from the schema location
+func (v *SchemaValidator) validator(gvk schema.GroupVersionKind) (*validate.SchemaValidator, error) {
+	if validator, cached := v.validators[gvk]; cached {
+		return validator, nil
+	}
+
+	var validator *validate.SchemaValidator
+	if path := v.schemaPath(gvk); path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		openAPISchema := &spec.Schema{}
+		if err = json.Unmarshal(data, openAPISchema); err != nil {
+			return nil, ErrInvalidSchema{Path: path, Err: err}
+		}
+		validator = v.newValidator(openAPISchema)
+	}
+	v.validators[gvk] = validator
+	return validator, nil
+}
+
+// schemaPath returns the path to the schema file of the GVK, empty string is returned if there is none
+func (v *SchemaValidator) schemaPath(gvk schema.GroupVersionKind) string {
+	if v.options.SchemaLocation == "" {
+		return ""
+	}
+	name := strings.ToLower(gvk.Kind)
+	if gvk.Group != "" {
+		name += "-" + strings.ToLower(strings.Split(gvk.Group, ".")[0])
+	}
+	name += "-" + strings.ToLower(gvk.Version) + ".json"
+
+	versionDir := v.options.KubernetesVersion
+	if versionDir != "master" {
+		versionDir = "v" + versionDir
+	}
+	versionDir += "-standalone"
+	dirs := []string{filepath.Join(v.options.SchemaLocation, versionDir), v.options.SchemaLocation}
+	if v.options.Strict {
+		dirs = append([]string{filepath.Join(v.options.SchemaLocation, versionDir+"-strict")}, dirs...)
+	}
+
+	for _, dir := range dirs {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+func (v *SchemaValidator) newValidator(openAPISchema *spec.Schema) *validate.SchemaValidator {
+	if v.options.Strict {
+		disallowAdditionalProperties(openAPISchema)
+	}
+	return validate.NewSchemaValidator(openAPISchema, nil, "", strfmt.Default)
+}
+
+// addTypeMetaProperties adds apiVersion, kind and metadata to the schema of custom resource,
+// these are not a part of CRD schema since they are validated by API server itself
+func addTypeMetaProperties(s *spec.Schema) {
+	if s.Properties == nil {
+		s.Properties = map[string]spec.Schema{}
+	}
+	properties := map[string]string{"apiVersion": "string", "kind": "string", "metadata": "object"}
+	for name, typ := range properties {
+		if _, exists := s.Properties[name]; !exists {
+			s.Properties[name] = spec.Schema{SchemaProps: spec.SchemaProps{Type: []string{typ}}}
+		}
+	}
+}
+
+// disallowAdditionalProperties forbids the properties which are not defined by the object
+// schemas, unless the schema explicitly preserves unknown fields
+func disallowAdditionalProperties(s *spec.Schema) {
+	if preserve, _ := s.Extensions.GetBool(preserveUnknownFieldsExtension); preserve {
+		return
+	}
+	if len(s.Properties) > 0 && s.AdditionalProperties == nil {
+		s.AdditionalProperties = &spec.SchemaOrBool{Allows: false}
+	}
+	for name := range s.Properties {
+		property := s.Properties[name]
+		disallowAdditionalProperties(&property)
+		s.Properties[name] = property
+	}
+	if s.Items == nil {
+		return
+	}
+	if s.Items.Schema != nil {
+		disallowAdditionalProperties(s.Items.Schema)
+	}
+	for i := range s.Items.Schemas {
+		disallowAdditionalProperties(&s.Items.Schemas[i])
+	}
+}
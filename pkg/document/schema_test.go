@@ -0,0 +1,262 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package document_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"opendev.org/airship/airshipctl/pkg/document"
+)
+
+const schemaTestCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+    plural: widgets
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              size:
+                type: integer
+                minimum: 1
+          status:
+            type: object
+            x-kubernetes-preserve-unknown-fields: true
+`
+
+const schemaTestV1beta1CRD = `apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: gadgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Gadget
+    plural: gadgets
+  scope: Namespaced
+  version: v1alpha1
+  validation:
+    openAPIV3Schema:
+      type: object
+      properties:
+        spec:
+          type: object
+          properties:
+            color:
+              type: string
+              enum:
+              - red
+              - blue
+`
+
+const schemaTestDocs = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default
+spec:
+  replicas: 3
+  selector: {}
+  template: {}
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: default
+data:
+  key: value
+`
+
+func TestSchemaValidator(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     document.SchemaValidatorOptions
+		docs        string
+		crds        string
+		expectedErr []document.ErrDocumentInvalid
+	}{
+		{
+			name:    "valid documents",
+			options: document.SchemaValidatorOptions{SchemaLocation: "testdata/schemas"},
+			docs:    schemaTestDocs,
+		},
+		{
+			name:    "invalid field type",
+			options: document.SchemaValidatorOptions{SchemaLocation: "testdata/schemas"},
+			docs: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default
+spec:
+  replicas: three
+  template: {}
+`,
+			expectedErr: []document.ErrDocumentInvalid{
+				{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Namespace:  "default",
+					Name:       "app",
+					Errors: []string{
+						`spec.replicas in body must be of type integer: "string"`,
+						`spec.selector in body is required`,
+					},
+				},
+			},
+		},
+		{
+			name:    "missing schema",
+			options: document.SchemaValidatorOptions{SchemaLocation: "testdata/schemas"},
+			docs:    "apiVersion: v1\nkind: Service\nmetadata:\n  name: svc\n",
+			expectedErr: []document.ErrDocumentInvalid{
+				{
+					APIVersion: "v1",
+					Kind:       "Service",
+					Name:       "svc",
+					Errors:     []string{"schema for /v1, Kind=Service not found"},
+				},
+			},
+		},
+		{
+			name: "ignore missing schema",
+			options: document.SchemaValidatorOptions{
+				SchemaLocation:       "testdata/schemas",
+				IgnoreMissingSchemas: true,
+			},
+			docs: "apiVersion: v1\nkind: Service\nmetadata:\n  name: svc\n",
+		},
+		{
+			name: "skip kind",
+			options: document.SchemaValidatorOptions{
+				SchemaLocation: "testdata/schemas",
+				KindsToSkip:    []string{"Deployment"},
+			},
+			docs: "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\nspec:\n  replicas: three\n",
+		},
+		{
+			name:    "unknown field is allowed",
+			options: document.SchemaValidatorOptions{SchemaLocation: "testdata/schemas"},
+			docs:    "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\nbinaryData: {}\n",
+		},
+		{
+			name:    "unknown field in strict mode",
+			options: document.SchemaValidatorOptions{SchemaLocation: "testdata/schemas", Strict: true},
+			docs:    "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\nbinaryData: {}\n",
+			expectedErr: []document.ErrDocumentInvalid{
+				{
+					APIVersion: "v1",
+					Kind:       "ConfigMap",
+					Name:       "settings",
+					Errors:     []string{"binaryData in body is a forbidden property"},
+				},
+			},
+		},
+		{
+			name:    "custom resource",
+			options: document.SchemaValidatorOptions{Strict: true},
+			crds:    schemaTestCRD,
+			docs: `apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: valid
+spec:
+  size: 1
+status:
+  anything: goes
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: invalid
+spec:
+  size: 0
+`,
+			expectedErr: []document.ErrDocumentInvalid{
+				{
+					APIVersion: "example.com/v1",
+					Kind:       "Widget",
+					Name:       "invalid",
+					Errors:     []string{"spec.size in body should be greater than or equal to 1"},
+				},
+			},
+		},
+		{
+			name:    "custom resource of v1beta1 crd",
+			options: document.SchemaValidatorOptions{Strict: true},
+			crds:    schemaTestV1beta1CRD,
+			docs: `apiVersion: example.com/v1alpha1
+kind: Gadget
+metadata:
+  name: valid
+spec:
+  color: red
+---
+apiVersion: example.com/v1alpha1
+kind: Gadget
+metadata:
+  name: invalid
+spec:
+  color: green
+`,
+			expectedErr: []document.ErrDocumentInvalid{
+				{
+					APIVersion: "example.com/v1alpha1",
+					Kind:       "Gadget",
+					Name:       "invalid",
+					Errors:     []string{"spec.color in body should be one of [red blue]"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			validator := document.NewSchemaValidator(tt.options)
+			if tt.crds != "" {
+				crds, err := document.NewBundleFromBytes([]byte(tt.crds))
+				require.NoError(t, err)
+				require.NoError(t, validator.AddCRDs(crds))
+			}
+			bundle, err := document.NewBundleFromBytes([]byte(tt.docs))
+			require.NoError(t, err)
+
+			err = validator.Validate(bundle)
+			if tt.expectedErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, document.ErrSchemaValidationFailed{Documents: tt.expectedErr}, err)
+		})
+	}
+}
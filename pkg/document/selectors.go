@@ -15,21 +15,51 @@
 package document
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
 
 	"opendev.org/airship/airshipctl/pkg/api/v1alpha1"
+	"opendev.org/airship/airshipctl/pkg/document/plugin/kyamlutils"
+)
+
+// Field selector operators
+const (
+	FieldSelectorEquals       = "="
+	FieldSelectorNotEquals    = "!="
+	FieldSelectorIn           = "in"
+	FieldSelectorNotIn        = "notin"
+	FieldSelectorExists       = "exists"
+	FieldSelectorDoesNotExist = "!exists"
 )
 
 // Selector provides abstraction layer in front of kustomize selector
 type Selector struct {
 	types.Selector `json:"selector,omitempty"`
+	// Fields select documents by the values of their fields, all of them must match
+	Fields []FieldSelector `json:"fields,omitempty"`
+	// Exclude drops the documents matching any of the selectors from the result
+	Exclude []Selector `json:"exclude,omitempty"`
+	// AnyOf restricts the result to the documents matching at least one of the selectors
+	AnyOf []Selector `json:"anyOf,omitempty"`
+}
+
+// FieldSelector selects documents by the value of the field referenced by JSON path
+type FieldSelector struct {
+	// Path is a JSON path to the field, e.g. {.spec.replicas} or spec.replicas
+	Path string `json:"path"`
+	// Operator is one of =, !=, in, notin, exists and !exists
+	Operator string `json:"operator"`
+	// Values to compare the field with, must be empty for exists and !exists operators
+	Values []string `json:"values,omitempty"`
 }
 
 // NewSelector returns instance of Selector container
@@ -101,6 +131,23 @@ func (s Selector) ByAnnotation(annotationSelector string) Selector {
 	return s
 }
 
+// ByField select by field selector
+func (s Selector) ByField(field FieldSelector) Selector {
+	s.Fields = append(append([]FieldSelector{}, s.Fields...), field)
+	return s
+}
+
+// Except excludes the documents matching the selector
+func (s Selector) Except(excluded Selector) Selector {
+	s.Exclude = append(append([]Selector{}, s.Exclude...), excluded)
+	return s
+}
+
+// Or selects the documents matching either this or the other selector
+func (s Selector) Or(other Selector) Selector {
+	return Selector{AnyOf: []Selector{s, other}}
+}
+
 // ByObject select by runtime object defined in API schema
 func (s Selector) ByObject(obj runtime.Object, scheme *runtime.Scheme) (Selector, error) {
 	gvks, _, err := scheme.ObjectKinds(obj)
@@ -157,6 +204,19 @@ func (s Selector) String() string {
 	if s.LabelSelector != "" {
 		components = append(components, fmt.Sprintf("Labels=%q", s.LabelSelector))
 	}
+	for _, field := range s.Fields {
+		components = append(components, fmt.Sprintf("Field=%q", field.String()))
+	}
+	for _, excluded := range s.Exclude {
+		components = append(components, fmt.Sprintf("Exclude=%s", excluded))
+	}
+	if len(s.AnyOf) > 0 {
+		alternatives := make([]string, len(s.AnyOf))
+		for i, alt := range s.AnyOf {
+			alternatives[i] = alt.String()
+		}
+		components = append(components, fmt.Sprintf("AnyOf=(%s)", strings.Join(alternatives, " OR ")))
+	}
 
 	if len(components) == 0 {
 		return "No selection conditions specified"
@@ -165,6 +225,247 @@ func (s Selector) String() string {
 	return fmt.Sprintf("[%s]", strings.Join(components, ", "))
 }
 
+// String returns the field selector in the form it is parsed from
+func (f FieldSelector) String() string {
+	switch f.Operator {
+	case FieldSelectorExists:
+		return f.Path
+	case FieldSelectorDoesNotExist:
+		return "!" + f.Path
+	case FieldSelectorIn, FieldSelectorNotIn:
+		return fmt.Sprintf("%s %s (%s)", f.Path, f.Operator, strings.Join(f.Values, ","))
+	default:
+		return f.Path + f.Operator + strings.Join(f.Values, ",")
+	}
+}
+
+// ParseFieldSelector parses field selector expression. Supported forms are
+// 'path=value', 'path==value', 'path!=value', 'path in (v1,v2)', 'path notin (v1,v2)',
+// 'path' (field exists) and '!path' (field doesn't exist). Path is either a JSON path
+// in braces, e.g. {.spec.containers[?(@.name=="app")].image}, or a dot separated path
+func ParseFieldSelector(expr string) (FieldSelector, error) {
+	rest := strings.TrimSpace(expr)
+	negate := strings.HasPrefix(rest, "!")
+	if negate {
+		rest = strings.TrimSpace(rest[1:])
+	}
+
+	path, rest, err := splitFieldSelectorPath(expr, rest)
+	if err != nil {
+		return FieldSelector{}, err
+	}
+
+	rest = strings.TrimSpace(rest)
+	switch {
+	case rest == "":
+		if negate {
+			return FieldSelector{Path: path, Operator: FieldSelectorDoesNotExist}, nil
+		}
+		return FieldSelector{Path: path, Operator: FieldSelectorExists}, nil
+	case negate:
+		return FieldSelector{}, ErrInvalidFieldSelector{Expression: expr,
+			Reason: "negation is allowed for field existence check only"}
+	case strings.HasPrefix(rest, "!="):
+		return FieldSelector{Path: path, Operator: FieldSelectorNotEquals, Values: []string{strings.TrimSpace(rest[2:])}}, nil
+	case strings.HasPrefix(rest, "=="):
+		return FieldSelector{Path: path, Operator: FieldSelectorEquals, Values: []string{strings.TrimSpace(rest[2:])}}, nil
+	case strings.HasPrefix(rest, "="):
+		return FieldSelector{Path: path, Operator: FieldSelectorEquals, Values: []string{strings.TrimSpace(rest[1:])}}, nil
+	}
+
+	return parseSetFieldSelector(expr, path, rest)
+}
+
+// splitFieldSelectorPath splits the path of field selector from the rest of the expression
+func splitFieldSelectorPath(expr, rest string) (string, string, error) {
+	var path string
+	if strings.HasPrefix(rest, "{") {
+		end := strings.Index(rest, "}")
+		if end < 0 {
+			return "", "", ErrInvalidFieldSelector{Expression: expr, Reason: "unterminated JSON path"}
+		}
+		path, rest = rest[:end+1], rest[end+1:]
+	} else {
+		end := operatorIndex(rest)
+		path, rest = rest[:end], rest[end:]
+	}
+	if path == "" {
+		return "", "", ErrInvalidFieldSelector{Expression: expr, Reason: "path is empty"}
+	}
+	return path, rest, nil
+}
+
+// parseSetFieldSelector parses 'in (v1,v2)' and 'notin (v1,v2)' parts of field selector
+func parseSetFieldSelector(expr, path, rest string) (FieldSelector, error) {
+	for _, op := range []string{FieldSelectorNotIn, FieldSelectorIn} {
+		if !strings.HasPrefix(rest, op) {
+			continue
+		}
+		values := strings.TrimSpace(strings.TrimPrefix(rest, op))
+		if !strings.HasPrefix(values, "(") || !strings.HasSuffix(values, ")") {
+			return FieldSelector{}, ErrInvalidFieldSelector{Expression: expr,
+				Reason: "values must be enclosed in parentheses"}
+		}
+		field := FieldSelector{Path: path, Operator: op}
+		for _, value := range strings.Split(values[1:len(values)-1], ",") {
+			field.Values = append(field.Values, strings.TrimSpace(value))
+		}
+		return field, nil
+	}
+	return FieldSelector{}, ErrInvalidFieldSelector{Expression: expr, Reason: "unknown operator"}
+}
+
+// operatorIndex returns the index where the dot separated path ends, filters
+// in brackets like spec.containers[name=app] are a part of the path
+func operatorIndex(expr string) int {
+	depth := 0
+	for i, c := range expr {
+		switch {
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case depth == 0 && strings.ContainsRune("!= ", c):
+			return i
+		}
+	}
+	return len(expr)
+}
+
+// splitConditions splits selector expression by semicolons, the semicolons enclosed
+// in brackets, braces, parentheses or quotes are a part of the condition
+func splitConditions(expr string) []string {
+	var conditions []string
+	depth, start := 0, 0
+	var quote rune
+	for i, c := range expr {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case strings.ContainsRune("[{(", c):
+			depth++
+		case strings.ContainsRune("]})", c):
+			depth--
+		case c == ';' && depth == 0:
+			conditions = append(conditions, expr[start:i])
+			start = i + 1
+		}
+	}
+	return append(conditions, expr[start:])
+}
+
+// ParseSelector parses selector expression which consists of semicolon separated
+// key=value conditions, e.g. 'kind=Secret;label=app=helm,tier=backend'. Supported
+// keys are apiVersion, kind, name, namespace, label, annotation and field, the value
+// of field is parsed with ParseFieldSelector. Semicolons enclosed in brackets, braces,
+// parentheses or quotes don't separate conditions, e.g. 'field={.data[?(@.sep==";")]}'
+func ParseSelector(expr string) (Selector, error) {
+	selector := NewSelector()
+	for _, condition := range splitConditions(expr) {
+		condition = strings.TrimSpace(condition)
+		if condition == "" {
+			continue
+		}
+		kv := strings.SplitN(condition, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return Selector{}, ErrInvalidSelectorExpression{Expression: expr,
+				Reason: fmt.Sprintf("condition %q must be in the form key=value", condition)}
+		}
+		key, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		switch key {
+		case "apiVersion":
+			gv, err := schema.ParseGroupVersion(value)
+			if err != nil {
+				return Selector{}, ErrInvalidSelectorExpression{Expression: expr, Reason: err.Error()}
+			}
+			selector.Group, selector.Version = gv.Group, gv.Version
+		case "kind":
+			selector.Kind = value
+		case "name":
+			selector = selector.ByName(value)
+		case "namespace":
+			selector = selector.ByNamespace(value)
+		case "label":
+			selector = selector.ByLabel(value)
+		case "annotation":
+			selector = selector.ByAnnotation(value)
+		case "field":
+			field, err := ParseFieldSelector(value)
+			if err != nil {
+				return Selector{}, err
+			}
+			selector = selector.ByField(field)
+		default:
+			return Selector{}, ErrInvalidSelectorExpression{Expression: expr,
+				Reason: fmt.Sprintf("unknown key %q", key)}
+		}
+	}
+	return selector, nil
+}
+
+// matchFields returns true if all the field selectors match the document
+func (s Selector) matchFields(rn *yaml.RNode) (bool, error) {
+	for _, field := range s.Fields {
+		matched, err := field.match(rn)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// match returns true if the field selector matches the document
+func (f FieldSelector) match(rn *yaml.RNode) (bool, error) {
+	node, err := rn.Pipe(kyamlutils.JSONPathFilter{Path: f.Path})
+	if err != nil && !errors.As(err, &kyamlutils.ErrIndexOutOfBound{}) {
+		return false, err
+	}
+	if err != nil || node == nil {
+		return f.Operator == FieldSelectorDoesNotExist || f.Operator == FieldSelectorNotEquals ||
+			f.Operator == FieldSelectorNotIn, nil
+	}
+
+	found := false
+	for _, value := range scalarValues(node) {
+		for _, expected := range f.Values {
+			found = found || value == expected
+		}
+	}
+
+	switch f.Operator {
+	case FieldSelectorExists:
+		return true, nil
+	case FieldSelectorDoesNotExist:
+		return false, nil
+	case FieldSelectorEquals, FieldSelectorIn:
+		return found, nil
+	case FieldSelectorNotEquals, FieldSelectorNotIn:
+		return !found, nil
+	}
+	return false, ErrInvalidFieldSelector{Expression: f.String(), Reason: "unknown operator"}
+}
+
+// scalarValues returns the value of scalar node or the values of scalar items of sequence node
+func scalarValues(node *yaml.RNode) []string {
+	switch node.YNode().Kind {
+	case yaml.ScalarNode:
+		return []string{node.YNode().Value}
+	case yaml.SequenceNode:
+		var values []string
+		for _, item := range node.Content() {
+			if item.Kind == yaml.ScalarNode {
+				values = append(values, item.Value)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
 // NewEphemeralCloudDataSelector returns selector to get BaremetalHost for ephemeral node
 func NewEphemeralCloudDataSelector() Selector {
 	return NewSelector().ByKind(SecretKind).ByLabel(EphemeralUserDataSelector)
@@ -246,3 +547,11 @@ func NewApplierContainerExecutorSelector() Selector {
 		ApplierContainerKind).
 		ByName(ApplierContainerName)
 }
+
+// NewValidationPolicySelector returns selector to get validation policy document by name
+func NewValidationPolicySelector(name string) Selector {
+	return NewSelector().ByGvk(ValidationPolicyGroup,
+		ValidationPolicyVersion,
+		ValidationPolicyKind).
+		ByName(name)
+}
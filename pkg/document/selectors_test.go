@@ -133,6 +133,15 @@ func TestSelectorString(t *testing.T) {
 				`Namespace="testNamespace", Name="testName", ` +
 				`Annotations="testAnnotation=true", Labels="testLabel=true"]`,
 		},
+		{
+			name: "by-fields-exclude-any-of",
+			selector: document.NewSelector().ByKind("Deployment").
+				Or(document.NewSelector().ByNamespace("metal3")).
+				ByField(document.FieldSelector{Path: "spec.replicas", Operator: "=", Values: []string{"3"}}).
+				Except(document.NewSelector().ByKind("Secret")),
+			expected: `[Field="spec.replicas=3", Exclude=[Kind="Secret"], ` +
+				`AnyOf=([Kind="Deployment"] OR [Namespace="metal3"])]`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -246,3 +255,204 @@ func TestSelectorByObjRef(t *testing.T) {
 		})
 	}
 }
+
+func TestParseFieldSelector(t *testing.T) {
+	tests := []struct {
+		expr        string
+		expected    document.FieldSelector
+		expectedErr error
+	}{
+		{
+			expr:     "spec.replicas=3",
+			expected: document.FieldSelector{Path: "spec.replicas", Operator: "=", Values: []string{"3"}},
+		},
+		{
+			expr:     "spec.replicas == 3",
+			expected: document.FieldSelector{Path: "spec.replicas", Operator: "=", Values: []string{"3"}},
+		},
+		{
+			expr: "spec.containers[name=app].image!=app:v1",
+			expected: document.FieldSelector{Path: "spec.containers[name=app].image", Operator: "!=",
+				Values: []string{"app:v1"}},
+		},
+		{
+			expr: `{.spec.containers[?(@.name=="app")].image} in (app:v1, app:v2)`,
+			expected: document.FieldSelector{Path: `{.spec.containers[?(@.name=="app")].image}`, Operator: "in",
+				Values: []string{"app:v1", "app:v2"}},
+		},
+		{
+			expr:     "spec.type notin (A)",
+			expected: document.FieldSelector{Path: "spec.type", Operator: "notin", Values: []string{"A"}},
+		},
+		{
+			expr:     "spec.paused",
+			expected: document.FieldSelector{Path: "spec.paused", Operator: "exists"},
+		},
+		{
+			expr:     "!spec.paused",
+			expected: document.FieldSelector{Path: "spec.paused", Operator: "!exists"},
+		},
+		{
+			expr: "!spec.paused=true",
+			expectedErr: document.ErrInvalidFieldSelector{Expression: "!spec.paused=true",
+				Reason: "negation is allowed for field existence check only"},
+		},
+		{
+			expr: "spec.type in A,B",
+			expectedErr: document.ErrInvalidFieldSelector{Expression: "spec.type in A,B",
+				Reason: "values must be enclosed in parentheses"},
+		},
+		{
+			expr:        "{.spec.type",
+			expectedErr: document.ErrInvalidFieldSelector{Expression: "{.spec.type", Reason: "unterminated JSON path"},
+		},
+		{
+			expr:        "spec.type like A",
+			expectedErr: document.ErrInvalidFieldSelector{Expression: "spec.type like A", Reason: "unknown operator"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.expr, func(t *testing.T) {
+			field, err := document.ParseFieldSelector(tt.expr)
+			if tt.expectedErr != nil {
+				assert.Equal(t, tt.expectedErr, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, field)
+		})
+	}
+}
+
+func TestParseSelector(t *testing.T) {
+	selector, err := document.ParseSelector("apiVersion=apps/v1; kind=Deployment;label=app=helm,tier=backend;" +
+		"namespace=default;name=app;annotation=example.com/skip=true;field=spec.replicas=3")
+	require.NoError(t, err)
+	assert.Equal(t, document.NewSelector().
+		ByGvk("apps", "v1", "Deployment").
+		ByLabel("app=helm,tier=backend").
+		ByNamespace("default").
+		ByName("app").
+		ByAnnotation("example.com/skip=true").
+		ByField(document.FieldSelector{Path: "spec.replicas", Operator: "=", Values: []string{"3"}}), selector)
+
+	selector, err = document.ParseSelector(`kind=ConfigMap;field={.data[?(@.separator==";")].name};` +
+		"field=spec.containers[name=app;v2].image")
+	require.NoError(t, err)
+	assert.Equal(t, document.NewSelector().
+		ByKind("ConfigMap").
+		ByField(document.FieldSelector{Path: `{.data[?(@.separator==";")].name}`, Operator: "exists"}).
+		ByField(document.FieldSelector{Path: "spec.containers[name=app;v2].image", Operator: "exists"}), selector)
+
+	_, err = document.ParseSelector("kind")
+	assert.Equal(t, document.ErrInvalidSelectorExpression{Expression: "kind",
+		Reason: `condition "kind" must be in the form key=value`}, err)
+
+	_, err = document.ParseSelector("color=red")
+	assert.Equal(t, document.ErrInvalidSelectorExpression{Expression: "color=red",
+		Reason: `unknown key "color"`}, err)
+}
+
+func TestSelectFieldsExcludeAnyOf(t *testing.T) {
+	bundle, err := document.NewBundleFromBytes([]byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:v1
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+spec:
+  replicas: 1
+  paused: true
+  template:
+    spec:
+      containers:
+      - name: worker
+        image: worker:v1
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+  namespace: metal3
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: metal3
+`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		selector document.Selector
+		expected []string
+	}{
+		{
+			name: "field equals",
+			selector: document.NewSelector().
+				ByField(document.FieldSelector{Path: "spec.replicas", Operator: "=", Values: []string{"3"}}),
+			expected: []string{"app"},
+		},
+		{
+			name: "field in list",
+			selector: document.NewSelector().ByField(document.FieldSelector{
+				Path:     "{.spec.template.spec.containers[*].image}",
+				Operator: "in",
+				Values:   []string{"worker:v1", "worker:v2"},
+			}),
+			expected: []string{"worker"},
+		},
+		{
+			name: "field does not exist",
+			selector: document.NewSelector().ByKind("Deployment").
+				ByField(document.FieldSelector{Path: "spec.paused", Operator: "!exists"}),
+			expected: []string{"app"},
+		},
+		{
+			name:     "exclude",
+			selector: document.NewSelector().Except(document.NewSelector().ByKind("Secret")),
+			expected: []string{"settings", "app", "worker"},
+		},
+		{
+			name: "any of",
+			selector: document.NewSelector().ByName("app").
+				Or(document.NewSelector().ByNamespace("metal3")).
+				Except(document.NewSelector().ByKind("ConfigMap")),
+			expected: []string{"creds", "app"},
+		},
+		{
+			name: "any of restricted union",
+			selector: document.NewSelector().ByName("app").
+				Or(document.NewSelector().ByNamespace("metal3")).
+				ByKind("Secret").
+				Or(document.NewSelector().ByName("worker")),
+			expected: []string{"creds", "worker"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			docs, err := bundle.Select(tt.selector)
+			require.NoError(t, err)
+			names := make([]string, len(docs))
+			for i, doc := range docs {
+				names[i] = doc.GetName()
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
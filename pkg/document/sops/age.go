@@ -0,0 +1,122 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package sops
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// AgeIdentity is an age X25519 private key, the only age key type sops uses
+type AgeIdentity struct {
+	identity *age.X25519Identity
+}
+
+// ParseAgeIdentity parses age identity encoded as AGE-SECRET-KEY-1...
+func ParseAgeIdentity(s string) (*AgeIdentity, error) {
+	identity, err := age.ParseX25519Identity(s)
+	if err != nil {
+		return nil, ErrInvalidAgeKey{Key: "identity", Err: err}
+	}
+	return &AgeIdentity{identity: identity}, nil
+}
+
+// GenerateAgeIdentity returns new random age identity
+func GenerateAgeIdentity() (*AgeIdentity, error) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		return nil, err
+	}
+	return &AgeIdentity{identity: identity}, nil
+}
+
+// String returns bech32 encoded identity
+func (i *AgeIdentity) String() string {
+	return i.identity.String()
+}
+
+// Recipient returns bech32 encoded public key of the identity
+func (i *AgeIdentity) Recipient() string {
+	return i.identity.Recipient().String()
+}
+
+// ParseAgeIdentities reads age identities from the keys file content,
+// empty lines and lines starting with # are ignored
+func ParseAgeIdentities(data string) ([]*AgeIdentity, error) {
+	var identities []*AgeIdentity
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		identity, err := ParseAgeIdentity(line)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+	return identities, scanner.Err()
+}
+
+// ageEncrypt encrypts plaintext for the recipient and returns ASCII armored age file
+func ageEncrypt(recipient string, plaintext []byte) (string, error) {
+	r, err := age.ParseX25519Recipient(recipient)
+	if err != nil {
+		return "", ErrInvalidAgeKey{Key: "recipient " + recipient, Err: err}
+	}
+
+	buf := &bytes.Buffer{}
+	armored := armor.NewWriter(buf)
+	w, err := age.Encrypt(armored, r)
+	if err != nil {
+		return "", err
+	}
+	if _, err = w.Write(plaintext); err != nil {
+		return "", err
+	}
+	if err = w.Close(); err != nil {
+		return "", err
+	}
+	if err = armored.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// ageDecrypt decrypts ASCII armored age file with one of the identities
+func ageDecrypt(identities []*AgeIdentity, armored string) ([]byte, error) {
+	ids := make([]age.Identity, 0, len(identities))
+	for _, identity := range identities {
+		ids = append(ids, identity.identity)
+	}
+	r, err := age.Decrypt(armor.NewReader(strings.NewReader(strings.TrimSpace(armored))), ids...)
+	if _, ok := err.(*age.NoIdentityMatchError); ok {
+		return nil, ErrNoMatchingKey{}
+	}
+	if err != nil {
+		return nil, ErrMalformedAgeFile{Reason: err.Error()}
+	}
+	plaintext, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, ErrMalformedAgeFile{Reason: err.Error()}
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,93 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package sops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// generated with age reference implementation
+	testAgeIdentity  = "AGE-SECRET-KEY-1QHWHWU6WP2CFVMHWY8FEQXHEQ7VMFDJ282AE3CS462CDDX9CR8HQZR4VXN"
+	testAgeRecipient = "age1e2atvetucwd2jq3pyz5tlremefc9n35tj2epgqpy6s4267fpnaeq25dp3j"
+	testAgeFile      = `-----BEGIN AGE ENCRYPTED FILE-----
+YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBWUkZOdkpyc3VIdG9TNkRE
+V095clhOY0x5VnB3ZGs3MStmc3B3dFlqU1ZZCnUrMlJ4WFAzRWFjclBsc3pYY2hz
+S0FlRFVtY1RVcHlQRlVlaHl5UXY3aWcKLS0tIGxJWVZObWZOWTVhTWRlMytxeXdm
+Z2R1YnRBZ0xDZFF0YmxRbDdwV0RqaFkK+6iqU29Ea5s2jFIhQr9UkTvhUcmrYFuI
+GjXocbXoCF9j2t0bUdqbevDE1m6/y4baCmBJaD3YOgo/VL39Euc=
+-----END AGE ENCRYPTED FILE-----
+`
+)
+
+func TestParseAgeIdentity(t *testing.T) {
+	identity, err := ParseAgeIdentity(testAgeIdentity)
+	require.NoError(t, err)
+	assert.Equal(t, testAgeIdentity, identity.String())
+	assert.Equal(t, testAgeRecipient, identity.Recipient())
+
+	_, err = ParseAgeIdentity(testAgeRecipient)
+	assert.Error(t, err)
+	_, err = ParseAgeIdentity(testAgeIdentity[:len(testAgeIdentity)-1] + "Q")
+	assert.Error(t, err)
+}
+
+func TestParseAgeIdentities(t *testing.T) {
+	identities, err := ParseAgeIdentities("# created: 2021-10-08\n# public key: " + testAgeRecipient +
+		"\n" + testAgeIdentity + "\n\n")
+	require.NoError(t, err)
+	require.Len(t, identities, 1)
+	assert.Equal(t, testAgeRecipient, identities[0].Recipient())
+
+	_, err = ParseAgeIdentities("not-a-key")
+	assert.Error(t, err)
+}
+
+func TestAgeDecrypt(t *testing.T) {
+	identity, err := ParseAgeIdentity(testAgeIdentity)
+	require.NoError(t, err)
+
+	plaintext, err := ageDecrypt([]*AgeIdentity{identity}, testAgeFile)
+	require.NoError(t, err)
+	assert.Equal(t, "data key encrypted by age tool", string(plaintext))
+
+	other, err := GenerateAgeIdentity()
+	require.NoError(t, err)
+	_, err = ageDecrypt([]*AgeIdentity{other}, testAgeFile)
+	assert.Equal(t, ErrNoMatchingKey{}, err)
+
+	_, err = ageDecrypt([]*AgeIdentity{identity}, "not armored")
+	assert.IsType(t, ErrMalformedAgeFile{}, err)
+}
+
+func TestAgeEncrypt(t *testing.T) {
+	identity, err := GenerateAgeIdentity()
+	require.NoError(t, err)
+
+	// payload spanning several 64KiB STREAM chunks
+	plaintext := make([]byte, 64*1024*2+10)
+	enc, err := ageEncrypt(identity.Recipient(), plaintext)
+	require.NoError(t, err)
+
+	decrypted, err := ageDecrypt([]*AgeIdentity{identity}, enc)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	_, err = ageEncrypt("age1invalid", plaintext)
+	assert.Error(t, err)
+}
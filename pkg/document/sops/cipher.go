@@ -0,0 +1,96 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package sops
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+)
+
+const (
+	dataKeySize = 32
+	ivSize      = 32
+)
+
+var encryptedValueRe = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.+),iv:(.+),tag:(.+),type:(.+)\]`)
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCMWithNonceSize(block, ivSize)
+}
+
+// encryptValue encrypts the value in the same way as sops does: with AES256-GCM using
+// the data key and the path of the value as additional data
+func encryptValue(key, plaintext []byte, valueType, additionalData string) (string, error) {
+	// sops leaves empty values as is
+	if len(plaintext) == 0 {
+		return "", nil
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, ivSize)
+	if _, err = rand.Read(iv); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nil, iv, plaintext, []byte(additionalData))
+	tagStart := len(sealed) - gcm.Overhead()
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s,type:%s]",
+		base64.StdEncoding.EncodeToString(sealed[:tagStart]),
+		base64.StdEncoding.EncodeToString(iv),
+		base64.StdEncoding.EncodeToString(sealed[tagStart:]),
+		valueType), nil
+}
+
+// decryptValue returns plaintext and its type for the value encrypted by sops
+func decryptValue(key []byte, value, additionalData string) ([]byte, string, error) {
+	if value == "" {
+		return nil, valueTypeString, nil
+	}
+	matches := encryptedValueRe.FindStringSubmatch(value)
+	if matches == nil {
+		return nil, "", ErrMalformedValue{Reason: "value isn't encrypted by sops"}
+	}
+	var parts [3][]byte
+	for i := range parts {
+		decoded, err := base64.StdEncoding.DecodeString(matches[i+1])
+		if err != nil {
+			return nil, "", ErrMalformedValue{Reason: err.Error()}
+		}
+		parts[i] = decoded
+	}
+	data, iv, tag := parts[0], parts[1], parts[2]
+	if len(iv) != ivSize {
+		return nil, "", ErrMalformedValue{Reason: "unexpected IV length"}
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, "", err
+	}
+	plaintext, err := gcm.Open(nil, iv, append(data, tag...), []byte(additionalData))
+	if err != nil {
+		return nil, "", ErrMalformedValue{Reason: "authentication failed, wrong key or value was moved"}
+	}
+	return plaintext, matches[4], nil
+}
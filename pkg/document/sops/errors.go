@@ -0,0 +1,132 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package sops
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrNotEncrypted returned if the document doesn't contain sops metadata
+type ErrNotEncrypted struct{}
+
+func (e ErrNotEncrypted) Error() string {
+	return "document isn't encrypted with sops"
+}
+
+// ErrAlreadyEncrypted returned if the document being encrypted already contains sops metadata
+type ErrAlreadyEncrypted struct{}
+
+func (e ErrAlreadyEncrypted) Error() string {
+	return "document is already encrypted with sops"
+}
+
+// ErrNoRecipients returned if the document is encrypted without any recipients
+type ErrNoRecipients struct{}
+
+func (e ErrNoRecipients) Error() string {
+	return "at least one age recipient or PGP fingerprint is required to encrypt the document"
+}
+
+// ErrNoMatchingKey returned if none of the available keys can decrypt the data key of the document
+type ErrNoMatchingKey struct {
+	Recipients []string
+}
+
+func (e ErrNoMatchingKey) Error() string {
+	if len(e.Recipients) == 0 {
+		return "no matching key found to decrypt the data key"
+	}
+	return fmt.Sprintf("no matching key found to decrypt the data key, document is encrypted for: %s",
+		strings.Join(e.Recipients, ", "))
+}
+
+// ErrMACMismatch returned if the document was modified after encryption
+type ErrMACMismatch struct {
+	Err error
+}
+
+func (e ErrMACMismatch) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("failed to verify MAC of the document: %v", e.Err)
+	}
+	return "MAC mismatch, document was modified after encryption"
+}
+
+// ErrDecryptValue returned if the value of the document can't be decrypted
+type ErrDecryptValue struct {
+	Path string
+	Err  error
+}
+
+func (e ErrDecryptValue) Error() string {
+	return fmt.Sprintf("failed to decrypt value of %s: %v", e.Path, e.Err)
+}
+
+// ErrMalformedValue returned if the value doesn't have the format of sops encrypted value
+type ErrMalformedValue struct {
+	Reason string
+}
+
+func (e ErrMalformedValue) Error() string {
+	return fmt.Sprintf("malformed encrypted value: %s", e.Reason)
+}
+
+// ErrMalformedAgeFile returned if the data key encrypted with age can't be parsed
+type ErrMalformedAgeFile struct {
+	Reason string
+}
+
+func (e ErrMalformedAgeFile) Error() string {
+	return fmt.Sprintf("malformed age encrypted data key: %s", e.Reason)
+}
+
+// ErrInvalidAgeKey returned if age identity or recipient can't be parsed
+type ErrInvalidAgeKey struct {
+	Key string
+	Err error
+}
+
+func (e ErrInvalidAgeKey) Error() string {
+	return fmt.Sprintf("invalid age %s: %v", e.Key, e.Err)
+}
+
+// ErrInvalidPGPKey returned if PGP keys can't be parsed
+type ErrInvalidPGPKey struct {
+	Err error
+}
+
+func (e ErrInvalidPGPKey) Error() string {
+	return fmt.Sprintf("failed to read PGP keys: %v", e.Err)
+}
+
+// ErrPGPKeyNotFound returned if public key of PGP recipient isn't available
+type ErrPGPKeyNotFound struct {
+	Fingerprint string
+}
+
+func (e ErrPGPKeyNotFound) Error() string {
+	return fmt.Sprintf("PGP key with fingerprint %s not found, import it with %s or %s",
+		e.Fingerprint, EnvImportPGP, EnvPGPKeyFile)
+}
+
+// ErrUnsupported returned if the document uses sops features which aren't supported
+type ErrUnsupported struct {
+	Feature string
+}
+
+func (e ErrUnsupported) Error() string {
+	return fmt.Sprintf("sops %s aren't supported", e.Feature)
+}
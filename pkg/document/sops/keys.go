@@ -0,0 +1,131 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package sops
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/openpgp"
+
+	"opendev.org/airship/airshipctl/pkg/util"
+)
+
+const (
+	// EnvAgeKey contains age identities, one per line
+	EnvAgeKey = "SOPS_AGE_KEY"
+	// EnvAgeKeyFile contains path to the file with age identities
+	EnvAgeKeyFile = "SOPS_AGE_KEY_FILE"
+	// EnvImportAge contains age identities, kept for compatibility with sops KRM function
+	EnvImportAge = "SOPS_IMPORT_AGE"
+	// EnvImportPGP contains ASCII armored PGP private keys
+	EnvImportPGP = "SOPS_IMPORT_PGP"
+	// EnvPGPKeyFile contains path to the file with ASCII armored PGP keys
+	EnvPGPKeyFile = "SOPS_PGP_KEY_FILE"
+)
+
+// Keys holds the keys used to encrypt and decrypt the documents
+type Keys struct {
+	AgeIdentities []*AgeIdentity
+	PGPKeyRing    openpgp.EntityList
+}
+
+// KeyFiles holds the paths of the key files configured in airship config,
+// the keys are used in addition to the ones given by the environment
+type KeyFiles struct {
+	// Age are the files with age identities, one per line
+	Age []string
+	// PGP are the files with ASCII armored PGP private keys
+	PGP []string
+}
+
+// DefaultAgeKeyFile returns the location of age keys file used by sops,
+// which is $XDG_CONFIG_HOME/sops/age/keys.txt
+func DefaultAgeKeyFile() string {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		configDir = filepath.Join(util.UserHomeDir(), ".config")
+	}
+	return filepath.Join(configDir, "sops", "age", "keys.txt")
+}
+
+// KeysFromEnv collects the keys from the environment variables, the sops age keys file
+// and the key files configured in airship config
+func KeysFromEnv(files KeyFiles) (*Keys, error) {
+	keys := &Keys{}
+
+	ageData := []string{os.Getenv(EnvAgeKey), os.Getenv(EnvImportAge)}
+	ageFile := os.Getenv(EnvAgeKeyFile)
+	if ageFile == "" {
+		ageFile = DefaultAgeKeyFile()
+	}
+	data, err := readKeyFile(ageFile, os.Getenv(EnvAgeKeyFile) != "")
+	if err != nil {
+		return nil, err
+	}
+	ageData = append(ageData, data)
+	for _, path := range files.Age {
+		if data, err = readKeyFile(path, true); err != nil {
+			return nil, err
+		}
+		ageData = append(ageData, data)
+	}
+	for _, d := range ageData {
+		identities, err := ParseAgeIdentities(d)
+		if err != nil {
+			return nil, err
+		}
+		keys.AgeIdentities = append(keys.AgeIdentities, identities...)
+	}
+
+	pgpData := []string{os.Getenv(EnvImportPGP)}
+	if pgpFile := os.Getenv(EnvPGPKeyFile); pgpFile != "" {
+		data, err = readKeyFile(pgpFile, true)
+		if err != nil {
+			return nil, err
+		}
+		pgpData = append(pgpData, data)
+	}
+	for _, path := range files.PGP {
+		if data, err = readKeyFile(path, true); err != nil {
+			return nil, err
+		}
+		pgpData = append(pgpData, data)
+	}
+	for _, d := range pgpData {
+		if d == "" {
+			continue
+		}
+		keyRing, err := ParsePGPKeyRing(d)
+		if err != nil {
+			return nil, err
+		}
+		keys.PGPKeyRing = append(keys.PGPKeyRing, keyRing...)
+	}
+	return keys, nil
+}
+
+// readKeyFile returns content of the key file, missing file is an error only if it was requested explicitly
+func readKeyFile(path string, required bool) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) && !required {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
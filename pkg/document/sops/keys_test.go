@@ -0,0 +1,51 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package sops
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeysFromEnvKeyFiles(t *testing.T) {
+	dir := t.TempDir()
+	// make sure that the default age key file of the user running tests is not used
+	oldConfigHome, configHomeSet := os.LookupEnv("XDG_CONFIG_HOME")
+	os.Setenv("XDG_CONFIG_HOME", dir)
+	defer func() {
+		if configHomeSet {
+			os.Setenv("XDG_CONFIG_HOME", oldConfigHome)
+		} else {
+			os.Unsetenv("XDG_CONFIG_HOME")
+		}
+	}()
+
+	keyFile := filepath.Join(dir, "keys.txt")
+	data := "# public key: " + testAgeRecipient + "\n" + testAgeIdentity + "\n"
+	require.NoError(t, ioutil.WriteFile(keyFile, []byte(data), 0600))
+
+	keys, err := KeysFromEnv(KeyFiles{Age: []string{keyFile}})
+	require.NoError(t, err)
+	require.Len(t, keys.AgeIdentities, 1)
+	assert.Equal(t, testAgeRecipient, keys.AgeIdentities[0].Recipient())
+
+	_, err = KeysFromEnv(KeyFiles{Age: []string{filepath.Join(dir, "missing.txt")}})
+	assert.Error(t, err)
+}
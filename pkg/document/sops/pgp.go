@@ -0,0 +1,91 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package sops
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	// openpgp falls back to RIPEMD160 for the keys without hash preferences
+	_ "golang.org/x/crypto/ripemd160"
+)
+
+const pgpMessageType = "PGP MESSAGE"
+
+// ParsePGPKeyRing reads ASCII armored PGP keys
+func ParsePGPKeyRing(armored string) (openpgp.EntityList, error) {
+	keyRing, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armored))
+	if err != nil {
+		return nil, ErrInvalidPGPKey{Err: err}
+	}
+	return keyRing, nil
+}
+
+// pgpFingerprint returns fingerprint of the entity in the form used by sops
+func pgpFingerprint(entity *openpgp.Entity) string {
+	return fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint[:])
+}
+
+func pgpEncrypt(keyRing openpgp.EntityList, fingerprint string, plaintext []byte) (string, error) {
+	var recipient *openpgp.Entity
+	for _, entity := range keyRing {
+		if strings.EqualFold(pgpFingerprint(entity), fingerprint) {
+			recipient = entity
+			break
+		}
+	}
+	if recipient == nil {
+		return "", ErrPGPKeyNotFound{Fingerprint: fingerprint}
+	}
+
+	buf := &bytes.Buffer{}
+	armorWriter, err := armor.Encode(buf, pgpMessageType, nil)
+	if err != nil {
+		return "", err
+	}
+	w, err := openpgp.Encrypt(armorWriter, []*openpgp.Entity{recipient}, nil, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	if _, err = w.Write(plaintext); err != nil {
+		return "", err
+	}
+	if err = w.Close(); err != nil {
+		return "", err
+	}
+	if err = armorWriter.Close(); err != nil {
+		return "", err
+	}
+	return buf.String() + "\n", nil
+}
+
+func pgpDecrypt(keyRing openpgp.EntityList, armored string) ([]byte, error) {
+	block, err := armor.Decode(strings.NewReader(armored))
+	if err != nil {
+		return nil, err
+	}
+	if block.Type != pgpMessageType {
+		return nil, fmt.Errorf("unexpected PGP block type %s", block.Type)
+	}
+	md, err := openpgp.ReadMessage(block.Body, keyRing, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(md.UnverifiedBody)
+}
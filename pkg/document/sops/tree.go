@@ -0,0 +1,483 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package sops
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	// MetadataKey is the top level key of the document holding sops metadata
+	MetadataKey = "sops"
+	// MetadataVersion is the version of sops format written to the encrypted documents
+	MetadataVersion = "3.7.1"
+
+	defaultUnencryptedSuffix = "_unencrypted"
+
+	valueTypeString = "str"
+	valueTypeInt    = "int"
+	valueTypeFloat  = "float"
+	valueTypeBool   = "bool"
+)
+
+// AgeRecipient holds the data key encrypted for the age recipient
+type AgeRecipient struct {
+	Recipient string `yaml:"recipient"`
+	Enc       string `yaml:"enc"`
+}
+
+// PGPRecipient holds the data key encrypted for the PGP key
+type PGPRecipient struct {
+	CreatedAt   string `yaml:"created_at"`
+	Enc         string `yaml:"enc"`
+	Fingerprint string `yaml:"fp"`
+}
+
+// Metadata is sops metadata stored in the encrypted document
+type Metadata struct {
+	Age               []AgeRecipient `yaml:"age"`
+	AzureKV           []interface{}  `yaml:"azure_kv"`
+	EncryptedRegex    string         `yaml:"encrypted_regex,omitempty"`
+	EncryptedSuffix   string         `yaml:"encrypted_suffix,omitempty"`
+	GCPKMS            []interface{}  `yaml:"gcp_kms"`
+	HCVault           []interface{}  `yaml:"hc_vault"`
+	KeyGroups         []interface{}  `yaml:"key_groups,omitempty"`
+	KMS               []interface{}  `yaml:"kms"`
+	LastModified      string         `yaml:"lastmodified"`
+	MAC               string         `yaml:"mac"`
+	PGP               []PGPRecipient `yaml:"pgp"`
+	UnencryptedRegex  string         `yaml:"unencrypted_regex,omitempty"`
+	UnencryptedSuffix string         `yaml:"unencrypted_suffix,omitempty"`
+	Version           string         `yaml:"version"`
+}
+
+// EncryptOptions defines recipients of the encrypted document and which of its values are encrypted.
+// If none of the rules is set, all values except the ones with keys ending with _unencrypted are encrypted
+type EncryptOptions struct {
+	AgeRecipients     []string
+	PGPFingerprints   []string
+	EncryptedRegex    string
+	UnencryptedRegex  string
+	EncryptedSuffix   string
+	UnencryptedSuffix string
+}
+
+// RotateOptions defines the changes of recipients made when the data key is rotated
+type RotateOptions struct {
+	AddAge    []string
+	RemoveAge []string
+	AddPGP    []string
+	RemovePGP []string
+	IgnoreMAC bool
+}
+
+// IsEncrypted returns true if the document contains sops metadata
+func IsEncrypted(node *yaml.RNode) bool {
+	if node == nil || node.YNode().Kind != yaml.MappingNode {
+		return false
+	}
+	field := node.Field(MetadataKey)
+	return field != nil && field.Value.YNode().Kind == yaml.MappingNode && field.Value.Field("mac") != nil
+}
+
+// Recipients returns the list of keys the document is encrypted for
+func (m *Metadata) Recipients() []string {
+	var recipients []string
+	for _, r := range m.Age {
+		recipients = append(recipients, "age "+r.Recipient)
+	}
+	for _, r := range m.PGP {
+		recipients = append(recipients, "pgp "+r.Fingerprint)
+	}
+	return recipients
+}
+
+// Decrypt decrypts the values of the document in place and removes sops metadata from it.
+// Integrity of the document is checked with the MAC unless ignoreMAC is set, the check is expected to fail
+// if the document was modified after encryption, e.g. unencrypted fields were edited by hand
+func Decrypt(node *yaml.RNode, keys *Keys, ignoreMAC bool) (*Metadata, error) {
+	meta, err := readMetadata(node)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := meta.dataKey(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	work := node.Copy()
+	hash := sha512.New()
+	err = walk(work.YNode(), nil, func(leaf *yaml.Node, path []string) error {
+		// values added to the modified document after encryption are left as is
+		if meta.encrypted(path) && !(ignoreMAC && !encryptedValueRe.MatchString(leaf.Value)) {
+			if err := decryptLeaf(leaf, dataKey, path); err != nil {
+				return err
+			}
+		}
+		return hashLeaf(hash, leaf)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !ignoreMAC {
+		mac, _, err := decryptValue(dataKey, meta.MAC, meta.LastModified)
+		if err != nil {
+			return nil, ErrMACMismatch{Err: err}
+		}
+		if string(mac) != fmt.Sprintf("%X", hash.Sum(nil)) {
+			return nil, ErrMACMismatch{}
+		}
+	}
+
+	if err = work.PipeE(yaml.Clear(MetadataKey)); err != nil {
+		return nil, err
+	}
+	node.SetYNode(work.YNode())
+	return meta, nil
+}
+
+// Encrypt encrypts the values of the document in place with a new data key and adds sops metadata to it
+func Encrypt(node *yaml.RNode, keys *Keys, opts EncryptOptions) error {
+	if IsEncrypted(node) {
+		return ErrAlreadyEncrypted{}
+	}
+	if len(opts.AgeRecipients) == 0 && len(opts.PGPFingerprints) == 0 {
+		return ErrNoRecipients{}
+	}
+
+	meta := newMetadata(opts)
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return err
+	}
+
+	work := node.Copy()
+	hash := sha512.New()
+	err := walk(work.YNode(), nil, func(leaf *yaml.Node, path []string) error {
+		if err := hashLeaf(hash, leaf); err != nil {
+			return err
+		}
+		if !meta.encrypted(path) {
+			return nil
+		}
+		return encryptLeaf(leaf, dataKey, path)
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	meta.LastModified = now
+	if meta.MAC, err = encryptValue(dataKey, []byte(fmt.Sprintf("%X", hash.Sum(nil))), valueTypeString, now); err != nil {
+		return err
+	}
+	if err = meta.addRecipients(keys, opts, dataKey); err != nil {
+		return err
+	}
+
+	metaNode := &yaml.Node{}
+	if err = metaNode.Encode(meta); err != nil {
+		return err
+	}
+	if err = work.PipeE(yaml.SetField(MetadataKey, yaml.NewRNode(metaNode))); err != nil {
+		return err
+	}
+	node.SetYNode(work.YNode())
+	return nil
+}
+
+// newMetadata returns sops metadata with the encryption rules of the options, if none of
+// the rules are set values of the keys with default unencrypted suffix are left as is
+func newMetadata(opts EncryptOptions) *Metadata {
+	meta := &Metadata{
+		EncryptedRegex:    opts.EncryptedRegex,
+		UnencryptedRegex:  opts.UnencryptedRegex,
+		EncryptedSuffix:   opts.EncryptedSuffix,
+		UnencryptedSuffix: opts.UnencryptedSuffix,
+		Version:           MetadataVersion,
+	}
+	if meta.EncryptedRegex == "" && meta.UnencryptedRegex == "" &&
+		meta.EncryptedSuffix == "" && meta.UnencryptedSuffix == "" {
+		meta.UnencryptedSuffix = defaultUnencryptedSuffix
+	}
+	return meta
+}
+
+// addRecipients encrypts the data key for each of the age and pgp recipients
+func (meta *Metadata) addRecipients(keys *Keys, opts EncryptOptions, dataKey []byte) error {
+	for _, recipient := range opts.AgeRecipients {
+		enc, err := ageEncrypt(recipient, dataKey)
+		if err != nil {
+			return err
+		}
+		meta.Age = append(meta.Age, AgeRecipient{Recipient: recipient, Enc: enc})
+	}
+	for _, fp := range opts.PGPFingerprints {
+		enc, err := pgpEncrypt(keys.PGPKeyRing, fp, dataKey)
+		if err != nil {
+			return err
+		}
+		meta.PGP = append(meta.PGP, PGPRecipient{CreatedAt: meta.LastModified, Enc: enc, Fingerprint: fp})
+	}
+	return nil
+}
+
+// Rotate re-encrypts the document with a new data key, recipients may be changed at the same time
+func Rotate(node *yaml.RNode, keys *Keys, opts RotateOptions) error {
+	work := node.Copy()
+	meta, err := Decrypt(work, keys, opts.IgnoreMAC)
+	if err != nil {
+		return err
+	}
+
+	encryptOpts := EncryptOptions{
+		EncryptedRegex:    meta.EncryptedRegex,
+		UnencryptedRegex:  meta.UnencryptedRegex,
+		EncryptedSuffix:   meta.EncryptedSuffix,
+		UnencryptedSuffix: meta.UnencryptedSuffix,
+	}
+	for _, r := range meta.Age {
+		encryptOpts.AgeRecipients = append(encryptOpts.AgeRecipients, r.Recipient)
+	}
+	for _, r := range meta.PGP {
+		encryptOpts.PGPFingerprints = append(encryptOpts.PGPFingerprints, r.Fingerprint)
+	}
+	encryptOpts.AgeRecipients = updateList(encryptOpts.AgeRecipients, opts.AddAge, opts.RemoveAge)
+	encryptOpts.PGPFingerprints = updateList(encryptOpts.PGPFingerprints, opts.AddPGP, opts.RemovePGP)
+
+	if err = Encrypt(work, keys, encryptOpts); err != nil {
+		return err
+	}
+	node.SetYNode(work.YNode())
+	return nil
+}
+
+func updateList(list, add, remove []string) []string {
+	var result []string
+	seen := map[string]bool{}
+	for _, item := range remove {
+		seen[strings.ToLower(item)] = true
+	}
+	for _, item := range append(list, add...) {
+		if !seen[strings.ToLower(item)] {
+			seen[strings.ToLower(item)] = true
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func readMetadata(node *yaml.RNode) (*Metadata, error) {
+	if !IsEncrypted(node) {
+		return nil, ErrNotEncrypted{}
+	}
+	meta := &Metadata{}
+	if err := node.Field(MetadataKey).Value.YNode().Decode(meta); err != nil {
+		return nil, err
+	}
+	if len(meta.KeyGroups) > 0 {
+		return nil, ErrUnsupported{Feature: "key groups"}
+	}
+	return meta, nil
+}
+
+// dataKey decrypts the data key of the document with the first matching key
+func (m *Metadata) dataKey(keys *Keys) ([]byte, error) {
+	if len(keys.AgeIdentities) > 0 {
+		for _, r := range m.Age {
+			key, err := ageDecrypt(keys.AgeIdentities, r.Enc)
+			if err == nil {
+				return key, nil
+			}
+			if _, ok := err.(ErrNoMatchingKey); !ok {
+				return nil, err
+			}
+		}
+	}
+	if len(keys.PGPKeyRing) > 0 {
+		for _, r := range m.PGP {
+			if key, err := pgpDecrypt(keys.PGPKeyRing, r.Enc); err == nil {
+				return key, nil
+			}
+		}
+	}
+	return nil, ErrNoMatchingKey{Recipients: m.Recipients()}
+}
+
+// encrypted returns true if the value with the path is encrypted according to the metadata rules
+func (m *Metadata) encrypted(path []string) bool {
+	encrypted := true
+	if m.UnencryptedSuffix != "" {
+		for _, p := range path {
+			if strings.HasSuffix(p, m.UnencryptedSuffix) {
+				encrypted = false
+				break
+			}
+		}
+	}
+	if m.EncryptedSuffix != "" {
+		encrypted = false
+		for _, p := range path {
+			if strings.HasSuffix(p, m.EncryptedSuffix) {
+				encrypted = true
+				break
+			}
+		}
+	}
+	if m.UnencryptedRegex != "" {
+		for _, p := range path {
+			if matched, _ := regexp.MatchString(m.UnencryptedRegex, p); matched {
+				encrypted = false
+				break
+			}
+		}
+	}
+	if m.EncryptedRegex != "" {
+		encrypted = false
+		for _, p := range path {
+			if matched, _ := regexp.MatchString(m.EncryptedRegex, p); matched {
+				encrypted = true
+				break
+			}
+		}
+	}
+	return encrypted
+}
+
+type leafFunc func(leaf *yaml.Node, path []string) error
+
+// walk calls fn for every scalar of the document except sops metadata in the document order,
+// path contains keys of the mappings leading to the scalar, sequence indexes aren't included
+func walk(node *yaml.Node, path []string, fn leafFunc) error {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			if err := walk(child, path, fn); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if len(path) == 0 && key == MetadataKey {
+				continue
+			}
+			if err := walk(node.Content[i+1], append(path[:len(path):len(path)], key), fn); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		return fn(node, path)
+	}
+	return nil
+}
+
+// leafValue returns the value of the scalar and its type in the form sops uses for encryption and MAC
+func leafValue(leaf *yaml.Node) ([]byte, string, error) {
+	switch leaf.ShortTag() {
+	case "!!null":
+		return nil, "", nil
+	case "!!int":
+		var v int
+		if err := leaf.Decode(&v); err != nil {
+			return nil, "", err
+		}
+		return []byte(strconv.Itoa(v)), valueTypeInt, nil
+	case "!!float":
+		var v float64
+		if err := leaf.Decode(&v); err != nil {
+			return nil, "", err
+		}
+		return []byte(strconv.FormatFloat(v, 'f', -1, 64)), valueTypeFloat, nil
+	case "!!bool":
+		var v bool
+		if err := leaf.Decode(&v); err != nil {
+			return nil, "", err
+		}
+		// sops writes booleans capitalized
+		if v {
+			return []byte("True"), valueTypeBool, nil
+		}
+		return []byte("False"), valueTypeBool, nil
+	default:
+		return []byte(leaf.Value), valueTypeString, nil
+	}
+}
+
+func hashLeaf(h hash.Hash, leaf *yaml.Node) error {
+	value, _, err := leafValue(leaf)
+	if err != nil {
+		return err
+	}
+	h.Write(value) //nolint:errcheck // hash.Hash never returns an error
+	return nil
+}
+
+func additionalData(path []string) string {
+	return strings.Join(path, ":") + ":"
+}
+
+func encryptLeaf(leaf *yaml.Node, key []byte, path []string) error {
+	value, valueType, err := leafValue(leaf)
+	if err != nil || valueType == "" {
+		return err
+	}
+	enc, err := encryptValue(key, value, valueType, additionalData(path))
+	if err != nil {
+		return err
+	}
+	leaf.Tag = yaml.NodeTagString
+	leaf.Value = enc
+	leaf.Style = 0
+	return nil
+}
+
+func decryptLeaf(leaf *yaml.Node, key []byte, path []string) error {
+	if leaf.ShortTag() == "!!null" {
+		return nil
+	}
+	value, valueType, err := decryptValue(key, leaf.Value, additionalData(path))
+	if err != nil {
+		return ErrDecryptValue{Path: strings.Join(path, "."), Err: err}
+	}
+
+	leaf.Value = string(value)
+	leaf.Style = 0
+	switch valueType {
+	case valueTypeInt:
+		leaf.Tag = yaml.NodeTagInt
+	case valueTypeFloat:
+		leaf.Tag = yaml.NodeTagFloat
+	case valueTypeBool:
+		leaf.Tag = yaml.NodeTagBool
+		leaf.Value = strings.ToLower(leaf.Value)
+	default:
+		leaf.Tag = yaml.NodeTagString
+		if strings.Contains(leaf.Value, "\n") {
+			leaf.Style = yaml.LiteralStyle
+		}
+	}
+	return nil
+}
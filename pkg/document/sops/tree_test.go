@@ -0,0 +1,200 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package sops_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"opendev.org/airship/airshipctl/pkg/document/sops"
+)
+
+const testSecret = `apiVersion: v1
+kind: Secret
+metadata:
+  name: test-secret
+data:
+  password: cGFzc3dvcmQ=
+  port: 6443
+  enabled: true
+  ratio: 0.5
+  hosts:
+  - host1
+  - host2
+stringData:
+  empty: ""
+type: Opaque
+`
+
+func testKeys(t *testing.T) (*sops.Keys, string, string) {
+	t.Helper()
+	identity, err := sops.GenerateAgeIdentity()
+	require.NoError(t, err)
+
+	entity, err := openpgp.NewEntity("airship", "test", "airship@example.com", nil)
+	require.NoError(t, err)
+	buf := &bytes.Buffer{}
+	w, err := armor.Encode(buf, openpgp.PrivateKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.SerializePrivate(w, nil))
+	require.NoError(t, w.Close())
+	keyRing, err := sops.ParsePGPKeyRing(buf.String())
+	require.NoError(t, err)
+
+	keys := &sops.Keys{AgeIdentities: []*sops.AgeIdentity{identity}, PGPKeyRing: keyRing}
+	return keys, identity.Recipient(), fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint[:])
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	keys, ageRecipient, pgpFingerprint := testKeys(t)
+	tests := []struct {
+		name         string
+		opts         sops.EncryptOptions
+		decryptKeys  *sops.Keys
+		plainFields  []string
+		cipherFields []string
+	}{
+		{
+			name: "age recipient",
+			opts: sops.EncryptOptions{
+				AgeRecipients:  []string{ageRecipient},
+				EncryptedRegex: "^(data|stringData)$",
+			},
+			decryptKeys:  &sops.Keys{AgeIdentities: keys.AgeIdentities},
+			plainFields:  []string{"metadata.name", "type"},
+			cipherFields: []string{"data.password", "data.port", "data.enabled", "data.ratio"},
+		},
+		{
+			name: "pgp recipient",
+			opts: sops.EncryptOptions{
+				PGPFingerprints: []string{pgpFingerprint},
+				EncryptedRegex:  "^password$",
+			},
+			decryptKeys:  &sops.Keys{PGPKeyRing: keys.PGPKeyRing},
+			plainFields:  []string{"metadata.name", "data.port"},
+			cipherFields: []string{"data.password"},
+		},
+		{
+			name: "default rules",
+			opts: sops.EncryptOptions{
+				AgeRecipients:   []string{ageRecipient},
+				PGPFingerprints: []string{pgpFingerprint},
+			},
+			decryptKeys:  &sops.Keys{AgeIdentities: keys.AgeIdentities},
+			cipherFields: []string{"kind", "metadata.name", "data.password"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			node := yaml.MustParse(testSecret)
+			require.NoError(t, sops.Encrypt(node, keys, tt.opts))
+			assert.True(t, sops.IsEncrypted(node))
+
+			encrypted := node.MustString()
+			for _, field := range tt.plainFields {
+				assert.NotContains(t, fieldValue(t, node, field), "ENC[", field)
+			}
+			for _, field := range tt.cipherFields {
+				assert.Contains(t, fieldValue(t, node, field), "ENC[AES256_GCM", field)
+			}
+
+			decrypted := yaml.MustParse(encrypted)
+			_, err := sops.Decrypt(decrypted, tt.decryptKeys, false)
+			require.NoError(t, err)
+			assert.False(t, sops.IsEncrypted(decrypted))
+			assert.Equal(t, testSecret, decrypted.MustString())
+		})
+	}
+}
+
+func TestDecryptErrors(t *testing.T) {
+	keys, ageRecipient, _ := testKeys(t)
+	node := yaml.MustParse(testSecret)
+	require.NoError(t, sops.Encrypt(node, keys, sops.EncryptOptions{AgeRecipients: []string{ageRecipient}}))
+	encrypted := node.MustString()
+
+	_, err := sops.Decrypt(yaml.MustParse(testSecret), keys, false)
+	assert.Equal(t, sops.ErrNotEncrypted{}, err)
+
+	_, err = sops.Decrypt(yaml.MustParse(encrypted), &sops.Keys{}, false)
+	assert.Equal(t, sops.ErrNoMatchingKey{Recipients: []string{"age " + ageRecipient}}, err)
+
+	// value added after encryption isn't encrypted
+	modified := yaml.MustParse(encrypted)
+	require.NoError(t, modified.PipeE(yaml.SetLabel("app", "test")))
+	_, err = sops.Decrypt(modified, keys, false)
+	assert.IsType(t, sops.ErrDecryptValue{}, err)
+	_, err = sops.Decrypt(modified, keys, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "test", fieldValue(t, modified, "metadata.labels.app"))
+
+	assert.Equal(t, sops.ErrAlreadyEncrypted{}, sops.Encrypt(yaml.MustParse(encrypted), keys,
+		sops.EncryptOptions{AgeRecipients: []string{ageRecipient}}))
+	assert.Equal(t, sops.ErrNoRecipients{}, sops.Encrypt(yaml.MustParse(testSecret), keys, sops.EncryptOptions{}))
+}
+
+func TestDecryptMACMismatch(t *testing.T) {
+	keys, ageRecipient, _ := testKeys(t)
+	node := yaml.MustParse(testSecret)
+	require.NoError(t, sops.Encrypt(node, keys, sops.EncryptOptions{
+		AgeRecipients:  []string{ageRecipient},
+		EncryptedRegex: "^data$",
+	}))
+	require.NoError(t, node.PipeE(yaml.SetField("type", yaml.NewScalarRNode("kubernetes.io/tls"))))
+
+	_, err := sops.Decrypt(yaml.MustParse(node.MustString()), keys, false)
+	assert.Equal(t, sops.ErrMACMismatch{}, err)
+	_, err = sops.Decrypt(node, keys, true)
+	assert.NoError(t, err)
+}
+
+func TestRotate(t *testing.T) {
+	keys, ageRecipient, pgpFingerprint := testKeys(t)
+	node := yaml.MustParse(testSecret)
+	require.NoError(t, sops.Encrypt(node, keys, sops.EncryptOptions{
+		AgeRecipients:  []string{ageRecipient},
+		EncryptedRegex: "^data$",
+	}))
+	before := fieldValue(t, node, "data.password")
+
+	require.NoError(t, sops.Rotate(node, keys, sops.RotateOptions{
+		AddPGP:    []string{pgpFingerprint},
+		RemoveAge: []string{ageRecipient},
+	}))
+	assert.NotEqual(t, before, fieldValue(t, node, "data.password"))
+
+	meta, err := sops.Decrypt(yaml.MustParse(node.MustString()), &sops.Keys{PGPKeyRing: keys.PGPKeyRing}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pgp " + pgpFingerprint}, meta.Recipients())
+	assert.Equal(t, "^data$", meta.EncryptedRegex)
+}
+
+func fieldValue(t *testing.T, node *yaml.RNode, path string) string {
+	t.Helper()
+	field, err := node.Pipe(yaml.Lookup(strings.Split(path, ".")...))
+	require.NoError(t, err)
+	require.NotNil(t, field, path)
+	return field.YNode().Value
+}
@@ -15,10 +15,15 @@ limitations under the License.
 package document
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"path/filepath"
+	"sort"
+	"strings"
 
+	"sigs.k8s.io/kustomize/api/konfig"
 	"sigs.k8s.io/kustomize/api/types"
 
 	"opendev.org/airship/airshipctl/pkg/fs"
@@ -26,61 +31,108 @@ import (
 
 // KustomNode is used to create name and data to display tree structure
 type KustomNode struct {
-	Name     string // name used for display purposes (cli)
-	Data     string // this could be a Kustomization object, or a string containing a file path
-	Children []KustomNode
-	Writer   io.Writer
+	// Name is used for display purposes (cli)
+	Name string `json:"name"`
+	// Data could be a Kustomization object, or a string containing a file path
+	Data     string       `json:"path,omitempty"`
+	Children []KustomNode `json:"children,omitempty"`
+	Writer   io.Writer    `json:"-"`
 }
 
-// BuildKustomTree creates a tree based on entrypoint
+// BuildKustomTree creates a tree based on entrypoint, ErrKustomizationCycle is returned if kustomizations
+// include each other and ErrKustomizeSourceNotFound if a local source doesn't exist
 func BuildKustomTree(entrypoint string, writer io.Writer, manifestsDir string) (KustomNode, error) {
-	fs := fs.NewDocumentFs()
-	name, err := filepath.Rel(manifestsDir, entrypoint)
-	if err != nil {
-		name = entrypoint
+	return buildKustomTree(fs.NewDocumentFs(), entrypoint, writer, manifestsDir, nil)
+}
+
+func buildKustomTree(fSys fs.FileSystem, entrypoint string, writer io.Writer, manifestsDir string,
+	chain []string) (KustomNode, error) {
+	for _, visited := range chain {
+		if visited == filepath.Clean(entrypoint) {
+			return KustomNode{}, ErrKustomizationCycle{Chain: append(chain, entrypoint)}
+		}
 	}
+	chain = append(chain[:len(chain):len(chain)], filepath.Clean(entrypoint))
+
 	root := KustomNode{
-		Name:     name,
+		Name:     relativeName(manifestsDir, entrypoint),
 		Data:     entrypoint,
 		Children: []KustomNode{},
 		Writer:   writer,
 	}
 
-	resMap, err := MakeResMap(fs, entrypoint)
+	resMap, err := MakeResMap(fSys, entrypoint)
 	if err != nil {
 		return KustomNode{}, err
 	}
 
-	for sourceType, sources := range resMap {
+	sourceTypes := make([]string, 0, len(resMap))
+	for sourceType := range resMap {
+		sourceTypes = append(sourceTypes, sourceType)
+	}
+	sort.Strings(sourceTypes)
+	for _, sourceType := range sourceTypes {
 		n := KustomNode{
 			Name:   sourceType,
 			Writer: writer,
 		}
 
-		for _, s := range sources {
-			if !fs.IsDir(s) {
-				name, err := filepath.Rel(manifestsDir, s)
-				if err != nil {
-					name = s
-				}
-				n.Children = append(n.Children, KustomNode{
-					Name: name,
-					Data: s,
-				})
-			} else {
-				s = filepath.Join(s, KustomizationFile)
-				child, err := BuildKustomTree(s, writer, "")
-				if err != nil {
-					return KustomNode{}, err
-				}
-				n.Children = append(n.Children, child)
+		for _, s := range resMap[sourceType] {
+			child, err := buildSourceNode(fSys, entrypoint, s, writer, manifestsDir, chain)
+			if err != nil {
+				return KustomNode{}, err
 			}
+			n.Children = append(n.Children, child)
 		}
 		root.Children = append(root.Children, n)
 	}
 	return root, nil
 }
 
+// buildSourceNode returns the leaf node of a file or remote source, or the subtree of kustomization directory
+func buildSourceNode(fSys fs.FileSystem, entrypoint, source string, writer io.Writer, manifestsDir string,
+	chain []string) (KustomNode, error) {
+	if isRemoteSource(source) {
+		return KustomNode{Name: source, Data: source}, nil
+	}
+	if !fSys.Exists(source) {
+		return KustomNode{}, ErrKustomizeSourceNotFound{Kustomization: entrypoint, Path: source}
+	}
+	if !fSys.IsDir(source) {
+		return KustomNode{Name: relativeName(manifestsDir, source), Data: source}, nil
+	}
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		kfile := filepath.Join(source, name)
+		if fSys.Exists(kfile) {
+			return buildKustomTree(fSys, kfile, writer, manifestsDir, chain)
+		}
+	}
+	return KustomNode{}, ErrKustomizeSourceNotFound{Kustomization: entrypoint,
+		Path: filepath.Join(source, KustomizationFile)}
+}
+
+func relativeName(manifestsDir, path string) string {
+	name, err := filepath.Rel(manifestsDir, path)
+	if err != nil {
+		return path
+	}
+	return name
+}
+
+// isRemoteSource checks if the source is a URL or a remote git repository, which are not resolved
+func isRemoteSource(source string) bool {
+	return strings.Contains(source, "://") || strings.HasPrefix(source, "git@") ||
+		strings.HasPrefix(source, "github.com/")
+}
+
+// sourcePath returns the path of the source relative to the kustomization directory, remote sources are kept
+func sourcePath(basedir, source string) string {
+	if isRemoteSource(source) {
+		return source
+	}
+	return filepath.Join(basedir, source)
+}
+
 //MakeResMap creates resmap based of kustomize types
 func MakeResMap(fs fs.FileSystem, kfile string) (map[string][]string, error) {
 	if fs == nil {
@@ -96,29 +148,26 @@ func MakeResMap(fs fs.FileSystem, kfile string) (map[string][]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	k.FixKustomizationPostUnmarshalling()
 	basedir := filepath.Dir(kfile)
 	var resMap = make(map[string][]string)
-	for _, p := range k.Resources {
-		path := filepath.Join(basedir, p)
-		resMap["Resources"] = append(resMap["Resources"], path)
+	sources := map[string][]string{
+		"Resources":    k.Resources,
+		"Crds":         k.Crds,
+		"Components":   k.Components,
+		"Generators":   k.Generators,
+		"Transformers": k.Transformers,
+		"Validators":   k.Validators,
 	}
-
-	for _, p := range k.Crds {
-		path := filepath.Join(basedir, p)
-		resMap["Crds"] = append(resMap["Crds"], path)
+	for sourceType, paths := range sources {
+		for _, p := range paths {
+			resMap[sourceType] = append(resMap[sourceType], sourcePath(basedir, p))
+		}
 	}
 
 	buildConfigMapAndSecretGenerator(k, basedir, resMap)
-
-	for _, p := range k.Generators {
-		path := filepath.Join(basedir, p)
-		resMap["Generators"] = append(resMap["Generators"], path)
-	}
-
-	for _, p := range k.Transformers {
-		path := filepath.Join(basedir, p)
-		resMap["Transformers"] = append(resMap["Transformers"], path)
-	}
+	buildPatches(k, basedir, resMap)
+	buildHelmCharts(k, basedir, resMap)
 
 	return resMap, nil
 }
@@ -126,16 +175,99 @@ func MakeResMap(fs fs.FileSystem, kfile string) (map[string][]string, error) {
 func buildConfigMapAndSecretGenerator(k types.Kustomization, basedir string, resMap map[string][]string) {
 	for _, p := range k.SecretGenerator {
 		for _, s := range p.FileSources {
-			path := filepath.Join(basedir, s)
-			resMap["SecretGenerator"] = append(resMap["SecretGenerator"], path)
+			resMap["SecretGenerator"] = append(resMap["SecretGenerator"], filepath.Join(basedir, fileSourcePath(s)))
 		}
 	}
 	for _, p := range k.ConfigMapGenerator {
 		for _, s := range p.FileSources {
-			path := filepath.Join(basedir, s)
-			resMap["ConfigMapGenerator"] = append(resMap["ConfigMapGenerator"], path)
+			resMap["ConfigMapGenerator"] = append(resMap["ConfigMapGenerator"], filepath.Join(basedir, fileSourcePath(s)))
+		}
+	}
+}
+
+// fileSourcePath returns the path of generator file source, which may be given as key=path
+func fileSourcePath(source string) string {
+	if parts := strings.SplitN(source, "=", 2); len(parts) == 2 {
+		return parts[1]
+	}
+	return source
+}
+
+// buildPatches adds the files of strategic merge and JSON patches, inline patches are skipped
+func buildPatches(k types.Kustomization, basedir string, resMap map[string][]string) {
+	for _, p := range k.PatchesStrategicMerge {
+		if !strings.Contains(string(p), "\n") {
+			resMap["Patches"] = append(resMap["Patches"], filepath.Join(basedir, string(p)))
+		}
+	}
+	for _, p := range k.Patches {
+		if p.Path != "" {
+			resMap["Patches"] = append(resMap["Patches"], filepath.Join(basedir, p.Path))
+		}
+	}
+}
+
+// buildHelmCharts adds local helm charts and values files, charts pulled from repositories are remote sources
+func buildHelmCharts(k types.Kustomization, basedir string, resMap map[string][]string) {
+	chartHome := "charts"
+	if k.HelmGlobals != nil && k.HelmGlobals.ChartHome != "" {
+		chartHome = k.HelmGlobals.ChartHome
+	}
+	for _, chart := range k.HelmCharts {
+		if chart.Repo != "" {
+			resMap["HelmCharts"] = append(resMap["HelmCharts"], strings.TrimSuffix(chart.Repo, "/")+"/"+chart.Name)
+		} else {
+			resMap["HelmCharts"] = append(resMap["HelmCharts"], filepath.Join(basedir, chartHome, chart.Name, "Chart.yaml"))
+		}
+		if chart.ValuesFile != "" {
+			resMap["HelmCharts"] = append(resMap["HelmCharts"], sourcePath(basedir, chart.ValuesFile))
+		}
+	}
+}
+
+// WriteJSON writes the tree as JSON document
+func (k KustomNode) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(k)
+}
+
+// WriteDOT writes the tree as Graphviz DOT digraph, kustomizations are drawn as boxes and
+// the edges are labeled with the source types
+func (k KustomNode) WriteDOT(w io.Writer) error {
+	buf := &bytes.Buffer{}
+	buf.WriteString("digraph kustomization {\n")
+	k.writeDOT(buf, map[string]bool{})
+	buf.WriteString("}\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (k KustomNode) writeDOT(buf *bytes.Buffer, declared map[string]bool) {
+	if !declared[k.Data] {
+		fmt.Fprintf(buf, "  %q [label=%q, shape=box];\n", k.Data, k.Name)
+		declared[k.Data] = true
+	}
+	for _, group := range k.Children {
+		for _, child := range group.Children {
+			fmt.Fprintf(buf, "  %q -> %q [label=%q];\n", k.Data, child.Data, group.Name)
+			if isKustomizationFile(child.Data) {
+				child.writeDOT(buf, declared)
+			} else if !declared[child.Data] {
+				fmt.Fprintf(buf, "  %q [label=%q];\n", child.Data, child.Name)
+				declared[child.Data] = true
+			}
+		}
+	}
+}
+
+func isKustomizationFile(path string) bool {
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		if filepath.Base(path) == name {
+			return true
 		}
 	}
+	return false
 }
 
 // PrintTree prints tree view of phase
@@ -101,6 +101,22 @@ func TestBuildKustomTree(t *testing.T) {
 			want1:       KustomNodeTestdata(w),
 			errContains: "no such file or directory",
 		},
+		{
+			name: "kustomization cycle",
+			args: func(t *testing.T) args {
+				return args{entrypoint: "testdata/tree/cycle/a/kustomization.yaml"}
+			},
+			errContains: "kustomization cycle detected: testdata/tree/cycle/a/kustomization.yaml -> " +
+				"testdata/tree/cycle/b/kustomization.yaml -> testdata/tree/cycle/a/kustomization.yaml",
+		},
+		{
+			name: "missing source",
+			args: func(t *testing.T) args {
+				return args{entrypoint: "testdata/tree/missing/kustomization.yaml"}
+			},
+			errContains: "source testdata/tree/missing/missing.yaml referenced by " +
+				"testdata/tree/missing/kustomization.yaml not found",
+		},
 	}
 
 	for _, tt := range tests {
@@ -146,6 +162,26 @@ func Test_makeResMap(t *testing.T) {
 				},
 			},
 		},
+		{
+			args: func(t *testing.T) args {
+				return args{kfile: "testdata/tree/full/kustomization.yaml", fs: fs.NewDocumentFs()}
+			},
+			name: "success resmap with all source types",
+			want1: map[string][]string{
+				"Resources": {
+					"testdata/tree/full/base",
+					"https://example.com/manifests/service.yaml",
+				},
+				"Components":         {"testdata/tree/full/component"},
+				"Transformers":       {"testdata/tree/full/transformer.yaml"},
+				"Patches":            {"testdata/tree/full/patch.yaml"},
+				"ConfigMapGenerator": {"testdata/tree/full/config.txt"},
+				"HelmCharts": {
+					"https://charts.example.com/app",
+					"testdata/tree/full/values.yaml",
+				},
+			},
+		},
 		{
 			args: func(t *testing.T) args {
 				return args{kfile: "testdata/no_plan_site/phases/kustomization.yaml"}
@@ -184,6 +220,60 @@ func Test_makeResMap(t *testing.T) {
 	}
 }
 
+func TestKustomNode_WriteJSON(t *testing.T) {
+	tree, err := document.BuildKustomTree("testdata/tree/full/base/kustomization.yaml", nil, "testdata/tree")
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, tree.WriteJSON(buf))
+	assert.Equal(t, `{
+  "name": "full/base/kustomization.yaml",
+  "path": "testdata/tree/full/base/kustomization.yaml",
+  "children": [
+    {
+      "name": "Resources",
+      "children": [
+        {
+          "name": "full/base/deployment.yaml",
+          "path": "testdata/tree/full/base/deployment.yaml"
+        }
+      ]
+    }
+  ]
+}
+`, buf.String())
+}
+
+func TestKustomNode_WriteDOT(t *testing.T) {
+	tree, err := document.BuildKustomTree("testdata/tree/full/kustomization.yaml", nil, "testdata/tree")
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, tree.WriteDOT(buf))
+	assert.Equal(t, `digraph kustomization {
+  "testdata/tree/full/kustomization.yaml" [label="full/kustomization.yaml", shape=box];
+  "testdata/tree/full/kustomization.yaml" -> "testdata/tree/full/component/kustomization.yaml" [label="Components"];
+  "testdata/tree/full/component/kustomization.yaml" [label="full/component/kustomization.yaml", shape=box];
+  "testdata/tree/full/kustomization.yaml" -> "testdata/tree/full/config.txt" [label="ConfigMapGenerator"];
+  "testdata/tree/full/config.txt" [label="full/config.txt"];
+  "testdata/tree/full/kustomization.yaml" -> "https://charts.example.com/app" [label="HelmCharts"];
+  "https://charts.example.com/app" [label="https://charts.example.com/app"];
+  "testdata/tree/full/kustomization.yaml" -> "testdata/tree/full/values.yaml" [label="HelmCharts"];
+  "testdata/tree/full/values.yaml" [label="full/values.yaml"];
+  "testdata/tree/full/kustomization.yaml" -> "testdata/tree/full/patch.yaml" [label="Patches"];
+  "testdata/tree/full/patch.yaml" [label="full/patch.yaml"];
+  "testdata/tree/full/kustomization.yaml" -> "testdata/tree/full/base/kustomization.yaml" [label="Resources"];
+  "testdata/tree/full/base/kustomization.yaml" [label="full/base/kustomization.yaml", shape=box];
+  "testdata/tree/full/base/kustomization.yaml" -> "testdata/tree/full/base/deployment.yaml" [label="Resources"];
+  "testdata/tree/full/base/deployment.yaml" [label="full/base/deployment.yaml"];
+  "testdata/tree/full/kustomization.yaml" -> "https://example.com/manifests/service.yaml" [label="Resources"];
+  "https://example.com/manifests/service.yaml" [label="https://example.com/manifests/service.yaml"];
+  "testdata/tree/full/kustomization.yaml" -> "testdata/tree/full/transformer.yaml" [label="Transformers"];
+  "testdata/tree/full/transformer.yaml" [label="full/transformer.yaml"];
+}
+`, buf.String())
+}
+
 func TestKustomNode_PrintTree(t *testing.T) {
 	var b bytes.Buffer
 	writer := bufio.NewWriter(&b)
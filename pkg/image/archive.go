@@ -0,0 +1,157 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package image
+
+import (
+	"archive/tar"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"sigs.k8s.io/yaml"
+
+	"opendev.org/airship/airshipctl/pkg/container"
+	"opendev.org/airship/airshipctl/pkg/log"
+)
+
+const (
+	// ManifestFileName is a name of the archive entry that holds the list of images
+	ManifestFileName = "manifest.yaml"
+	// ImagesFileName is a name of the archive entry that holds the images saved by container runtime
+	ImagesFileName = "images.tar"
+
+	archiveFileMode = 0644
+)
+
+// Manifest describes the content of the image archive
+type Manifest struct {
+	Images []string `json:"images"`
+}
+
+// Save pulls images that are not present locally and writes them to out as a
+// single tarball, which contains the manifest and the images in the format of
+// the container runtime. tmpDir is used to keep images until they are added to
+// the archive, if empty default directory for temporary files is used.
+func Save(archive container.ImageArchive, images []string, out io.Writer, tmpDir string) error {
+	if len(images) == 0 {
+		return ErrNoImages{}
+	}
+
+	for _, img := range images {
+		log.Printf("pulling image '%s'", img)
+		if err := archive.ImagePull(img); err != nil {
+			return err
+		}
+	}
+
+	manifest, err := yaml.Marshal(Manifest{Images: images})
+	if err != nil {
+		return err
+	}
+
+	// size of the tar entry must be known before writing it, so images are
+	// saved to a temporary file first
+	tmp, err := ioutil.TempFile(tmpDir, "airship-images-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := tmp.Close(); closeErr != nil {
+			log.Debugf("failed to close temporary file %s: %v", tmp.Name(), closeErr)
+		}
+		if rmErr := os.Remove(tmp.Name()); rmErr != nil {
+			log.Debugf("failed to remove temporary file %s: %v", tmp.Name(), rmErr)
+		}
+	}()
+
+	log.Printf("saving %d images", len(images))
+	if err = archive.ImageSave(tmp, images); err != nil {
+		return err
+	}
+	info, err := tmp.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	tw := tar.NewWriter(out)
+	if err = tw.WriteHeader(&tar.Header{
+		Name: ManifestFileName,
+		Mode: archiveFileMode,
+		Size: int64(len(manifest)),
+	}); err != nil {
+		return err
+	}
+	if _, err = tw.Write(manifest); err != nil {
+		return err
+	}
+	if err = tw.WriteHeader(&tar.Header{
+		Name: ImagesFileName,
+		Mode: archiveFileMode,
+		Size: info.Size(),
+	}); err != nil {
+		return err
+	}
+	if _, err = io.Copy(tw, tmp); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// Load reads the tarball created by Save and imports its images using container
+// runtime. Manifest of the loaded archive is returned
+func Load(archive container.ImageArchive, in io.Reader) (*Manifest, error) {
+	var manifest *Manifest
+	tr := tar.NewReader(in)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch hdr.Name {
+		case ManifestFileName:
+			if manifest, err = readManifest(tr); err != nil {
+				return nil, err
+			}
+		case ImagesFileName:
+			if manifest == nil {
+				return nil, ErrMalformedArchive{Reason: ManifestFileName + " must precede " + ImagesFileName}
+			}
+			log.Printf("loading %d images", len(manifest.Images))
+			if err = archive.ImageLoad(tr); err != nil {
+				return nil, err
+			}
+			return manifest, nil
+		default:
+			log.Debugf("skipping unknown archive entry '%s'", hdr.Name)
+		}
+	}
+	return nil, ErrMalformedArchive{Reason: ImagesFileName + " not found"}
+}
+
+func readManifest(r io.Reader) (*Manifest, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{}
+	return manifest, yaml.Unmarshal(data, manifest)
+}
@@ -0,0 +1,132 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package image_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"opendev.org/airship/airshipctl/pkg/image"
+)
+
+type fakeArchive struct {
+	pulled []string
+	loaded []byte
+	data   string
+	err    error
+}
+
+func (a *fakeArchive) ImagePull(url string) error {
+	a.pulled = append(a.pulled, url)
+	return a.err
+}
+
+func (a *fakeArchive) ImageSave(out io.Writer, urls []string) error {
+	_, err := fmt.Fprint(out, a.data)
+	return err
+}
+
+func (a *fakeArchive) ImageLoad(in io.Reader) error {
+	var err error
+	a.loaded, err = ioutil.ReadAll(in)
+	return err
+}
+
+func TestSaveLoad(t *testing.T) {
+	images := []string{"quay.io/airshipit/init:v1", "quay.io/airshipit/manager:v1"}
+	saver := &fakeArchive{data: "docker save output"}
+	buf := &bytes.Buffer{}
+	require.NoError(t, image.Save(saver, images, buf, ""))
+	assert.Equal(t, images, saver.pulled)
+
+	loader := &fakeArchive{}
+	manifest, err := image.Load(loader, buf)
+	require.NoError(t, err)
+	assert.Equal(t, images, manifest.Images)
+	assert.Equal(t, "docker save output", string(loader.loaded))
+}
+
+func TestSaveErrors(t *testing.T) {
+	pullErr := fmt.Errorf("pull error")
+	tests := []struct {
+		name        string
+		images      []string
+		archive     *fakeArchive
+		expectedErr error
+	}{
+		{
+			name:        "no images",
+			archive:     &fakeArchive{},
+			expectedErr: image.ErrNoImages{},
+		},
+		{
+			name:        "pull error",
+			images:      []string{"quay.io/airshipit/init:v1"},
+			archive:     &fakeArchive{err: pullErr},
+			expectedErr: pullErr,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := image.Save(tt.archive, tt.images, &bytes.Buffer{}, "")
+			assert.Equal(t, tt.expectedErr, err)
+		})
+	}
+}
+
+func TestLoadMalformedArchive(t *testing.T) {
+	tests := []struct {
+		name        string
+		entries     []string
+		expectedErr error
+	}{
+		{
+			name:        "images missing",
+			entries:     []string{image.ManifestFileName},
+			expectedErr: image.ErrMalformedArchive{Reason: image.ImagesFileName + " not found"},
+		},
+		{
+			name:    "manifest missing",
+			entries: []string{image.ImagesFileName},
+			expectedErr: image.ErrMalformedArchive{
+				Reason: image.ManifestFileName + " must precede " + image.ImagesFileName,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			tw := tar.NewWriter(buf)
+			for _, name := range tt.entries {
+				require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644}))
+			}
+			require.NoError(t, tw.Close())
+
+			_, err := image.Load(&fakeArchive{}, buf)
+			assert.Equal(t, tt.expectedErr, err)
+		})
+	}
+}
@@ -0,0 +1,169 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package image
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"opendev.org/airship/airshipctl/pkg/config"
+	"opendev.org/airship/airshipctl/pkg/container"
+	"opendev.org/airship/airshipctl/pkg/document"
+	"opendev.org/airship/airshipctl/pkg/phase"
+	"opendev.org/airship/airshipctl/pkg/phase/ifc"
+)
+
+// ArchiveFunc is a type of function which returns container image archive for the driver
+type ArchiveFunc func(ctx context.Context, driver string) (container.ImageArchive, error)
+
+// ListCommand holds options for image list command
+type ListCommand struct {
+	Factory config.Factory
+	Writer  io.Writer
+}
+
+// RunE prints out images used by the site
+func (c *ListCommand) RunE() error {
+	helper, err := newHelper(c.Factory)
+	if err != nil {
+		return err
+	}
+	images, err := List(helper)
+	if err != nil {
+		return err
+	}
+	for _, img := range images {
+		if _, err = fmt.Fprintln(c.Writer, img); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveCommand holds options for image save command
+type SaveCommand struct {
+	Factory     config.Factory
+	Driver      string
+	Output      string
+	Writer      io.Writer
+	ArchiveFunc ArchiveFunc
+}
+
+// RunE saves images used by the site into a single tarball
+func (c *SaveCommand) RunE() error {
+	helper, err := newHelper(c.Factory)
+	if err != nil {
+		return err
+	}
+	images, err := List(helper)
+	if err != nil {
+		return err
+	}
+
+	archive, err := newArchive(c.ArchiveFunc, c.Driver)
+	if err != nil {
+		return err
+	}
+
+	save := func(out io.Writer) error {
+		return Save(archive, images, out, helper.WorkDir())
+	}
+	if c.Output == "" {
+		return save(c.Writer)
+	}
+	return writeFile(c.Output, save)
+}
+
+// writeFile writes to a temporary file next to path and renames it to path on success,
+// so that no partial file is left at path if write or close fails
+func writeFile(path string, write func(io.Writer) error) (err error) {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+"-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(f.Name())
+		}
+	}()
+
+	if err = write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// LoadCommand holds options for image load command
+type LoadCommand struct {
+	Driver      string
+	Input       string
+	Reader      io.Reader
+	Writer      io.Writer
+	ArchiveFunc ArchiveFunc
+}
+
+// RunE loads images from the tarball created by image save command
+func (c *LoadCommand) RunE() error {
+	archive, err := newArchive(c.ArchiveFunc, c.Driver)
+	if err != nil {
+		return err
+	}
+
+	in := c.Reader
+	if c.Input != "" {
+		var f *os.File
+		if f, err = os.Open(c.Input); err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	manifest, err := Load(archive, in)
+	if err != nil {
+		return err
+	}
+	for _, img := range manifest.Images {
+		if _, err = fmt.Fprintf(c.Writer, "Loaded image: %s\n", img); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newHelper(cfgFactory config.Factory) (ifc.Helper, error) {
+	cfg, err := cfgFactory()
+	if err != nil {
+		return nil, err
+	}
+	// images are never part of encrypted documents, so rendering of the phases
+	// must not fail if decryption keys are not available
+	return phase.NewHelper(cfg, document.TolerateDecryptionFailures())
+}
+
+func newArchive(archiveFunc ArchiveFunc, driver string) (container.ImageArchive, error) {
+	if archiveFunc == nil {
+		archiveFunc = container.NewImageArchive
+	}
+	return archiveFunc(context.Background(), driver)
+}
@@ -0,0 +1,53 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package image
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "images.tar")
+
+	err := writeFile(path, func(out io.Writer) error {
+		_, err := out.Write([]byte("archive"))
+		return err
+	})
+	require.NoError(t, err)
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "archive", string(data))
+
+	// failed write leaves neither partial nor temporary file behind
+	failed := filepath.Join(dir, "failed.tar")
+	err = writeFile(failed, func(out io.Writer) error {
+		_, err := out.Write([]byte("partial"))
+		require.NoError(t, err)
+		return errors.New("save failed")
+	})
+	assert.EqualError(t, err, "save failed")
+	assert.NoFileExists(t, failed)
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
@@ -0,0 +1,36 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package image
+
+import (
+	"fmt"
+)
+
+// ErrNoImages returned if there are no images to save
+type ErrNoImages struct {
+}
+
+func (e ErrNoImages) Error() string {
+	return "no images found to be saved"
+}
+
+// ErrMalformedArchive returned if image archive doesn't have expected structure
+type ErrMalformedArchive struct {
+	Reason string
+}
+
+func (e ErrMalformedArchive) Error() string {
+	return fmt.Sprintf("malformed image archive: %s", e.Reason)
+}
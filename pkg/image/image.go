@@ -0,0 +1,200 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package image
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"opendev.org/airship/airshipctl/pkg/api/v1alpha1"
+	"opendev.org/airship/airshipctl/pkg/document"
+	"opendev.org/airship/airshipctl/pkg/log"
+	"opendev.org/airship/airshipctl/pkg/phase"
+	"opendev.org/airship/airshipctl/pkg/phase/ifc"
+)
+
+const (
+	// imageKey is a key of a document field holding complete image reference,
+	// e.g. containers[].image of Deployment or spec.image of GenericContainer
+	imageKey = "image"
+	// versionsCatalogueKind is a kind of VersionsCatalogue documents
+	versionsCatalogueKind = "VersionsCatalogue"
+)
+
+// List returns sorted list of unique image references used by the site: images
+// of the documents in the phase config bundle and in the rendered documents of
+// every phase that has document entry point defined
+func List(helper ifc.Helper) ([]string, error) {
+	images := make(map[string]struct{})
+	if err := collect(helper.PhaseConfigBundle(), images); err != nil {
+		return nil, err
+	}
+
+	phases, err := helper.ListPhases(ifc.ListPhaseOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	client := phase.NewClient(helper)
+	for _, phaseObj := range phases {
+		if phaseObj.Config.DocumentEntryPoint == "" {
+			log.Debugf("phase '%s' has no document entry point, skipping", phaseObj.Name)
+			continue
+		}
+		if err = collectFromPhase(client, phaseObj, helper.BundleOptions(), images); err != nil {
+			return nil, err
+		}
+	}
+	return sortedKeys(images), nil
+}
+
+// FromBundle returns sorted list of unique image references found in bundle documents
+func FromBundle(bundle document.Bundle) ([]string, error) {
+	images := make(map[string]struct{})
+	if err := collect(bundle, images); err != nil {
+		return nil, err
+	}
+	return sortedKeys(images), nil
+}
+
+func collectFromPhase(client ifc.Client, phaseObj *v1alpha1.Phase, opts []document.BundleOption,
+	images map[string]struct{}) error {
+	p, err := client.PhaseByAPIObj(phaseObj)
+	if err != nil {
+		return err
+	}
+	root, err := p.DocumentRoot()
+	if err != nil {
+		return err
+	}
+	log.Debugf("collecting images from documents of phase '%s'", phaseObj.Name)
+	bundle, err := document.NewBundleByPath(root, opts...)
+	if err != nil {
+		return err
+	}
+	return collect(bundle, images)
+}
+
+func collect(bundle document.Bundle, images map[string]struct{}) error {
+	docs, err := bundle.GetAllDocuments()
+	if err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		if err = collectFromDocument(doc, images); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func collectFromDocument(doc document.Document, images map[string]struct{}) error {
+	data, err := doc.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	var obj interface{}
+	if err = json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	walk(obj, images)
+
+	if doc.GetKind() != versionsCatalogueKind {
+		return nil
+	}
+	// images declared as discrete parts can't be found by the walker
+	catalogue := &v1alpha1.VersionsCatalogue{}
+	if err = doc.ToAPIObject(catalogue, v1alpha1.Scheme); err != nil {
+		return err
+	}
+	for _, capiImages := range catalogue.Spec.CAPIImages {
+		for _, url := range []v1alpha1.ImageURLSpec{capiImages.Manager, capiImages.AuthProxy, capiImages.IPAMManager} {
+			if url.Repository != "" {
+				add(images, joinRef(url.Repository, url.Tag))
+			}
+		}
+	}
+	for _, components := range catalogue.Spec.ImageComponents {
+		for _, component := range components {
+			add(images, componentRef(component))
+		}
+	}
+	return nil
+}
+
+// walk traverses unstructured object and adds string values of every "image" key to images
+func walk(obj interface{}, images map[string]struct{}) {
+	switch typed := obj.(type) {
+	case map[string]interface{}:
+		for key, val := range typed {
+			if str, ok := val.(string); ok && key == imageKey {
+				add(images, str)
+				continue
+			}
+			walk(val, images)
+		}
+	case []interface{}:
+		for _, val := range typed {
+			walk(val, images)
+		}
+	}
+}
+
+// componentRef builds image reference from Helm-style image definition
+func componentRef(component v1alpha1.ImageRepositorySpec) string {
+	repo := component.Repository
+	if component.Name != "" {
+		repo = strings.Join([]string{repo, component.Name}, "/")
+	}
+	switch {
+	case component.Digest != "":
+		return repo + "@" + component.Digest
+	case component.SHA != "":
+		sha := component.SHA
+		if !strings.Contains(sha, ":") {
+			sha = "sha256:" + sha
+		}
+		return repo + "@" + sha
+	case component.Hash != "":
+		return repo + "@" + component.Hash
+	}
+	return joinRef(repo, component.Tag)
+}
+
+func joinRef(repo, tag string) string {
+	if tag == "" {
+		return repo
+	}
+	return repo + ":" + tag
+}
+
+func add(images map[string]struct{}, ref string) {
+	ref = strings.TrimSpace(ref)
+	// skip empty values and values that can't be image references, e.g. templates
+	if ref == "" || strings.ContainsAny(ref, " {}") {
+		return
+	}
+	images[ref] = struct{}{}
+}
+
+func sortedKeys(images map[string]struct{}) []string {
+	result := make([]string, 0, len(images))
+	for ref := range images {
+		result = append(result, ref)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,41 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package image_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"opendev.org/airship/airshipctl/pkg/image"
+	"opendev.org/airship/airshipctl/testutil"
+)
+
+func TestFromBundle(t *testing.T) {
+	bundle := testutil.NewTestBundle(t, "testdata")
+	images, err := image.FromBundle(bundle)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"docker.io/nginx@sha256:0123456789abcdef",
+		"gcr.io/kubebuilder/kube-rbac-proxy:v0.4.1",
+		"quay.io/airshipit/init:v1",
+		"quay.io/airshipit/kubeval-validator:latest",
+		"quay.io/airshipit/manager:v1",
+		"quay.io/dexidp/dex:v2.28.1",
+		"quay.io/metal3-io/cluster-api-provider-metal3:v0.4.0",
+		"quay.io/metal3-io/ironic:capm3-v0.4.0",
+	}, images)
+}
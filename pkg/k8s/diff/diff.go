@@ -0,0 +1,297 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package diff
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"opendev.org/airship/airshipctl/pkg/document"
+)
+
+const (
+	// InventoryLabel is the label of cli-utils inventory objects, its value is the inventory id
+	InventoryLabel = "cli-utils.sigs.k8s.io/inventory-id"
+
+	defaultNamespace = "default"
+)
+
+var configMapResource = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+// Options defines how to reach the cluster and where to look for the inventory
+type Options struct {
+	Client dynamic.Interface
+	Mapper meta.RESTMapper
+	// InventoryID and InventoryNamespace identify cli-utils inventory of the applied documents,
+	// they are used unless the documents contain an inventory object
+	InventoryID        string
+	InventoryNamespace string
+}
+
+// objectKey identifies an object in the cluster the same way as cli-utils inventory does
+type objectKey struct {
+	Group     string
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// Live compares the documents with the objects of the cluster. Only the fields present in the documents
+// are compared, so the fields managed by the server are ignored. Documents missing in the cluster are
+// reported as added, objects of the inventory which aren't in the documents any more are reported as removed
+func Live(docs []document.Document, opts Options) ([]document.DocumentDiff, error) {
+	var diffs []document.DocumentDiff
+	inventoryID, inventoryNamespace := opts.InventoryID, opts.InventoryNamespace
+	desired := map[objectKey]bool{}
+	for _, doc := range docs {
+		if id, ok := doc.GetLabels()[InventoryLabel]; ok && doc.GetKind() == "ConfigMap" {
+			inventoryID, inventoryNamespace = id, doc.GetNamespace()
+		}
+
+		key, diff, err := liveDocumentDiff(doc, opts)
+		if err != nil {
+			return nil, err
+		}
+		desired[key] = true
+		if diff != nil {
+			diffs = append(diffs, *diff)
+		}
+	}
+	// the namespace of the inventory is applied together with the documents
+	desired[objectKey{Kind: "Namespace", Name: inventoryNamespace}] = true
+
+	inventory, err := inventoryObjects(opts, inventoryID, inventoryNamespace)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range inventory {
+		if desired[key] {
+			continue
+		}
+		apiVersion := key.Group
+		mapping, mappingErr := opts.Mapper.RESTMapping(schema.GroupKind{Group: key.Group, Kind: key.Kind})
+		if mappingErr == nil {
+			apiVersion = mapping.GroupVersionKind.GroupVersion().String()
+		}
+		diffs = append(diffs, document.DocumentDiff{
+			APIVersion: apiVersion,
+			Kind:       key.Kind,
+			Namespace:  key.Namespace,
+			Name:       key.Name,
+			Status:     document.DiffRemoved,
+		})
+	}
+	return diffs, nil
+}
+
+// liveDocumentDiff returns the difference between the document and the object of the cluster,
+// nil is returned if there is no difference
+func liveDocumentDiff(doc document.Document, opts Options) (objectKey, *document.DocumentDiff, error) {
+	key := objectKey{Group: doc.GetGroup(), Kind: doc.GetKind(), Namespace: doc.GetNamespace(), Name: doc.GetName()}
+	gvk := schema.GroupVersionKind{Group: doc.GetGroup(), Version: doc.GetVersion(), Kind: doc.GetKind()}
+	diff := &document.DocumentDiff{
+		APIVersion: gvk.GroupVersion().String(),
+		Kind:       key.Kind,
+		Name:       key.Name,
+		Status:     document.DiffModified,
+	}
+
+	mapping, err := opts.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		// the resource definition is not applied yet
+		diff.Namespace, diff.Status = key.Namespace, document.DiffAdded
+		return key, diff, nil
+	}
+	if err != nil {
+		return key, nil, err
+	}
+	var resource dynamic.ResourceInterface = opts.Client.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		if key.Namespace == "" {
+			key.Namespace = defaultNamespace
+		}
+		resource = opts.Client.Resource(mapping.Resource).Namespace(key.Namespace)
+	} else {
+		key.Namespace = ""
+	}
+	live, err := resource.Get(context.Background(), key.Name, metav1.GetOptions{})
+	diff.Namespace = key.Namespace
+	if apierrors.IsNotFound(err) {
+		diff.Status = document.DiffAdded
+		return key, diff, nil
+	}
+	if err != nil {
+		return key, nil, err
+	}
+
+	desiredDoc, liveDoc, err := normalize(doc, live.Object)
+	if err != nil {
+		return key, nil, err
+	}
+	if diff.Changes, err = document.DiffDocuments(liveDoc, desiredDoc); err != nil {
+		return key, nil, err
+	}
+	if len(diff.Changes) == 0 {
+		return key, nil, nil
+	}
+	return key, diff, nil
+}
+
+// normalize returns the documents which can be compared: the live object keeps only
+// the fields present in the document, stringData of secrets is moved to data
+func normalize(doc document.Document, live map[string]interface{}) (document.Document, document.Document, error) {
+	data, err := doc.MarshalJSON()
+	if err != nil {
+		return nil, nil, err
+	}
+	desired := map[string]interface{}{}
+	if err = json.Unmarshal(data, &desired); err != nil {
+		return nil, nil, err
+	}
+	if doc.GetGroup() == "" && doc.GetKind() == document.SecretKind {
+		moveStringData(desired)
+	}
+
+	desiredDoc, err := documentFromObject(desired)
+	if err != nil {
+		return nil, nil, err
+	}
+	liveDoc, err := documentFromObject(prune(live, desired))
+	if err != nil {
+		return nil, nil, err
+	}
+	return desiredDoc, liveDoc, nil
+}
+
+func moveStringData(secret map[string]interface{}) {
+	stringData, ok := secret["stringData"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	data, ok := secret["data"].(map[string]interface{})
+	if !ok {
+		data = map[string]interface{}{}
+	}
+	for k, v := range stringData {
+		if s, ok := v.(string); ok {
+			data[k] = base64.StdEncoding.EncodeToString([]byte(s))
+		}
+	}
+	secret["data"] = data
+	delete(secret, "stringData")
+}
+
+// prune removes the fields of the live value which are not set in the desired value,
+// extra items of the lists are kept since they are the drift of the list
+func prune(live, desired interface{}) interface{} {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			return live
+		}
+		pruned := make(map[string]interface{}, len(d))
+		for k, dv := range d {
+			if lv, exists := l[k]; exists {
+				pruned[k] = prune(lv, dv)
+			}
+		}
+		return pruned
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok {
+			return live
+		}
+		pruned := make([]interface{}, len(l))
+		for i := range l {
+			if i < len(d) {
+				pruned[i] = prune(l[i], d[i])
+			} else {
+				pruned[i] = l[i]
+			}
+		}
+		return pruned
+	default:
+		return live
+	}
+}
+
+func documentFromObject(obj interface{}) (document.Document, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	return document.NewDocumentFromBytes(data)
+}
+
+// inventoryObjects returns the objects listed in cli-utils inventory
+func inventoryObjects(opts Options, id, namespace string) ([]objectKey, error) {
+	if id == "" {
+		return nil, nil
+	}
+	list, err := opts.Client.Resource(configMapResource).Namespace(namespace).List(context.Background(),
+		metav1.ListOptions{LabelSelector: InventoryLabel + "=" + id})
+	if err != nil {
+		return nil, err
+	}
+	var keys []objectKey
+	for _, item := range list.Items {
+		data, ok := item.Object["data"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		objects := make([]string, 0, len(data))
+		for obj := range data {
+			objects = append(objects, obj)
+		}
+		sort.Strings(objects)
+		for _, obj := range objects {
+			if key, ok := parseInventoryKey(obj); ok {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys, nil
+}
+
+// parseInventoryKey parses the object reference in the format of cli-utils
+// <namespace>_<name>_<group>_<kind>, colons of the name are encoded as double underscores
+func parseInventoryKey(s string) (objectKey, bool) {
+	first, last := strings.Index(s, "_"), strings.LastIndex(s, "_")
+	if first < 0 || first == last {
+		return objectKey{}, false
+	}
+	key := objectKey{Namespace: s[:first], Kind: s[last+1:]}
+	s = s[first+1 : last]
+	groupIdx := strings.LastIndex(s, "_")
+	if groupIdx < 0 {
+		return objectKey{}, false
+	}
+	key.Group = s[groupIdx+1:]
+	key.Name = strings.ReplaceAll(s[:groupIdx], "__", ":")
+	if strings.Contains(key.Name, "_") {
+		return objectKey{}, false
+	}
+	return key, true
+}
@@ -0,0 +1,245 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+
+	"opendev.org/airship/airshipctl/pkg/document"
+	"opendev.org/airship/airshipctl/pkg/k8s/diff"
+)
+
+const desiredDocs = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:v2
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: same
+data:
+  key: value
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+  namespace: default
+stringData:
+  password: s3cr3t
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: new
+  namespace: default
+data:
+  key: value
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: widget
+  namespace: default
+spec:
+  size: 1
+`
+
+func object(obj map[string]interface{}) runtime.Object {
+	return &unstructured.Unstructured{Object: obj}
+}
+
+func liveObjects() []runtime.Object {
+	return []runtime.Object{
+		object(map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":            "app",
+				"namespace":       "default",
+				"resourceVersion": "42",
+				"uid":             "0f1b3b5c",
+			},
+			"spec": map[string]interface{}{
+				"replicas": int64(1),
+				"strategy": map[string]interface{}{"type": "RollingUpdate"},
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{
+								"name":            "app",
+								"image":           "app:v2",
+								"imagePullPolicy": "IfNotPresent",
+							},
+						},
+					},
+				},
+			},
+			"status": map[string]interface{}{"replicas": int64(1)},
+		}),
+		object(map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":        "same",
+				"namespace":   "default",
+				"annotations": map[string]interface{}{"example.com/applied": "true"},
+			},
+			"data": map[string]interface{}{"key": "value"},
+		}),
+		object(map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   map[string]interface{}{"name": "creds", "namespace": "default"},
+			"type":       "Opaque",
+			"data":       map[string]interface{}{"password": "czNjcjN0"},
+		}),
+		object(map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "old", "namespace": "default"},
+			"data":       map[string]interface{}{"key": "value"},
+		}),
+		object(map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      "inventory-abcde",
+				"namespace": "airshipit-test",
+				"labels":    map[string]interface{}{diff.InventoryLabel: "test"},
+			},
+			"data": map[string]interface{}{
+				"default_app_apps_Deployment": "",
+				"default_old__ConfigMap":      "",
+				"_airshipit-test__Namespace":  "",
+			},
+		}),
+	}
+}
+
+func testMapper() meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	for _, kind := range []string{"ConfigMap", "Secret"} {
+		mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: kind}, meta.RESTScopeNamespace)
+	}
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+	return mapper
+}
+
+func TestLive(t *testing.T) {
+	bundle, err := document.NewBundleFromBytes([]byte(desiredDocs))
+	require.NoError(t, err)
+	docs, err := bundle.GetAllDocuments()
+	require.NoError(t, err)
+
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{{Version: "v1", Resource: "configmaps"}: "ConfigMapList"},
+		liveObjects()...)
+
+	diffs, err := diff.Live(docs, diff.Options{
+		Client:             client,
+		Mapper:             testMapper(),
+		InventoryID:        "test",
+		InventoryNamespace: "airshipit-test",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []document.DocumentDiff{
+		{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Namespace:  "default",
+			Name:       "app",
+			Status:     document.DiffModified,
+			Changes:    []document.FieldChange{{Path: "spec.replicas", Old: float64(1), New: float64(3)}},
+		},
+		{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+			Namespace:  "default",
+			Name:       "new",
+			Status:     document.DiffAdded,
+		},
+		{
+			APIVersion: "example.com/v1",
+			Kind:       "Widget",
+			Namespace:  "default",
+			Name:       "widget",
+			Status:     document.DiffAdded,
+		},
+		{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+			Namespace:  "default",
+			Name:       "old",
+			Status:     document.DiffRemoved,
+		},
+	}, diffs)
+}
+
+func TestLiveInventoryFromDocuments(t *testing.T) {
+	const inventoryDoc = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: inventory-fghij
+  namespace: custom-inventory
+  labels:
+    cli-utils.sigs.k8s.io/inventory-id: custom
+`
+	bundle, err := document.NewBundleFromBytes([]byte(inventoryDoc))
+	require.NoError(t, err)
+	docs, err := bundle.GetAllDocuments()
+	require.NoError(t, err)
+
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{{Version: "v1", Resource: "configmaps"}: "ConfigMapList"},
+		object(map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      "inventory-fghij",
+				"namespace": "custom-inventory",
+				"labels":    map[string]interface{}{diff.InventoryLabel: "custom"},
+			},
+			"data": map[string]interface{}{"_custom-inventory__Namespace": ""},
+		}))
+
+	// the namespace of the inventory from the documents is not reported as removed
+	diffs, err := diff.Live(docs, diff.Options{
+		Client:             client,
+		Mapper:             testMapper(),
+		InventoryID:        "test",
+		InventoryNamespace: "airshipit-test",
+	})
+	require.NoError(t, err)
+	assert.Empty(t, diffs)
+}
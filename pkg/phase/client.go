@@ -16,9 +16,11 @@ package phase
 
 import (
 	"bytes"
+	goerrors "errors"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/yaml"
@@ -26,6 +28,7 @@ import (
 	"opendev.org/airship/airshipctl/pkg/api/v1alpha1"
 	"opendev.org/airship/airshipctl/pkg/container"
 	"opendev.org/airship/airshipctl/pkg/document"
+	"opendev.org/airship/airshipctl/pkg/fs"
 	"opendev.org/airship/airshipctl/pkg/k8s/kubeconfig"
 	"opendev.org/airship/airshipctl/pkg/log"
 	"opendev.org/airship/airshipctl/pkg/phase/errors"
@@ -58,6 +61,8 @@ type phase struct {
 	helper   ifc.Helper
 	apiObj   *v1alpha1.Phase
 	registry ExecutorRegistry
+	noCache  bool
+	offline  bool
 }
 
 // Executor returns executor interface associated with the phase
@@ -95,7 +100,7 @@ func (p *phase) Executor() (ifc.Executor, error) {
 	return executorFactory(
 		ifc.ExecutorConfig{
 			ClusterMap:        cMap,
-			BundleFactory:     document.BundleFactoryFromDocRoot(p.DocumentRoot),
+			BundleFactory:     document.BundleFactoryFromDocRoot(p.DocumentRoot, p.helper.BundleOptions()...),
 			PhaseName:         p.apiObj.Name,
 			KubeConfig:        kubeconf,
 			ExecutorDocument:  executorDoc,
@@ -123,10 +128,24 @@ func (p *phase) Validate() error {
 	if err != nil {
 		return err
 	}
-	return validate(executor, p.helper, p.apiObj.Config.ValidationCfg)
+	docRoot, err := documentRoot(p)
+	if err != nil {
+		return err
+	}
+	return validate(executor, docRoot, p.helper, p.apiObj.Config.ValidationCfg, p.noCache, p.offline)
+}
+
+// documentRoot returns the document entrypoint of the phase, empty if the phase doesn't define it
+func documentRoot(p ifc.Phase) (string, error) {
+	root, err := p.DocumentRoot()
+	if goerrors.As(err, &errors.ErrDocumentEntrypointNotDefined{}) {
+		return "", nil
+	}
+	return root, err
 }
 
-func validate(executor ifc.Executor, helper ifc.Helper, validationCfg v1alpha1.ValidationConfig) error {
+func validate(executor ifc.Executor, docRoot string, helper ifc.Helper, validationCfg v1alpha1.ValidationConfig,
+	noCache, offline bool) error {
 	if err := executor.Validate(); err != nil {
 		return err
 	}
@@ -135,7 +154,25 @@ func validate(executor ifc.Executor, helper ifc.Helper, validationCfg v1alpha1.V
 	if err := executor.Render(buf, ifc.RenderOptions{FilterSelector: document.NewSelector()}); err != nil {
 		return err
 	}
+	if len(validationCfg.Policies) > 0 || offline {
+		rendered, err := renderedBundle(buf.Bytes(), docRoot, helper)
+		if err != nil {
+			return err
+		}
+		if err = validatePolicies(rendered, helper, validationCfg); err != nil {
+			return err
+		}
+		if offline {
+			return validateOffline(rendered, helper, validationCfg)
+		}
+	}
+	return validateContainer(buf, helper, validationCfg, noCache)
+}
 
+// validateContainer validates the rendered documents and the CRDs of validation config by running
+// the validation container
+func validateContainer(buf *bytes.Buffer, helper ifc.Helper, validationCfg v1alpha1.ValidationConfig,
+	noCache bool) error {
 	doc, err := helper.PhaseConfigBundle().SelectOne(document.NewValidatorExecutorSelector())
 	if err != nil {
 		return err
@@ -167,7 +204,120 @@ func validate(executor ifc.Executor, helper ifc.Helper, validationCfg v1alpha1.V
 		}
 	}
 
-	return container.NewClientV1Alpha1("", buf, os.Stdout, apiObj, helper.TargetPath()).Run()
+	var opts []container.ClientOption
+	if !noCache {
+		opts = append(opts, container.WithCache(container.NewCache(helper.WorkDir())))
+	}
+	return container.NewClientV1Alpha1("", buf, os.Stdout, apiObj, helper.TargetPath(), opts...).Run()
+}
+
+// renderedBundle returns the bundle of the documents rendered by the executor, the documents built from
+// the document entrypoint of the phase are annotated with their origin, so the validation errors refer to it
+func renderedBundle(rendered []byte, docRoot string, helper ifc.Helper) (document.Bundle, error) {
+	bundle, err := document.NewBundleFromStream(rendered)
+	if err != nil || docRoot == "" {
+		return bundle, err
+	}
+	origins, err := document.NewBundleWithOrigin(fs.NewDocumentFs(), docRoot, helper.TargetPath(),
+		helper.BundleOptions()...)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := bundle.GetAllDocuments()
+	if err != nil {
+		return nil, err
+	}
+	for _, doc := range docs {
+		source, selectErr := origins.SelectOne(document.NewSelector().
+			ByGvk(doc.GetGroup(), doc.GetVersion(), doc.GetKind()).
+			ByName(doc.GetName()).
+			ByNamespace(doc.GetNamespace()))
+		if selectErr != nil {
+			// the document isn't built from the entrypoint, e.g. it's added by the executor
+			continue
+		}
+		if origin, ok := source.GetOrigin(); ok {
+			doc.Annotate(origin.Annotations())
+		}
+	}
+	return bundle, nil
+}
+
+// validatePolicies checks the rendered documents against the validation policies of validation config,
+// all the violations are logged and the ones of error severity fail the validation
+func validatePolicies(rendered document.Bundle, helper ifc.Helper, validationCfg v1alpha1.ValidationConfig) error {
+	if len(validationCfg.Policies) == 0 {
+		return nil
+	}
+
+	policies := make([]*v1alpha1.ValidationPolicy, 0, len(validationCfg.Policies))
+	for _, name := range validationCfg.Policies {
+		doc, err := helper.PhaseConfigBundle().SelectOne(document.NewValidationPolicySelector(name))
+		if err != nil {
+			return err
+		}
+		policy := &v1alpha1.ValidationPolicy{}
+		if err = doc.ToAPIObject(policy, v1alpha1.Scheme); err != nil {
+			return err
+		}
+		policies = append(policies, policy)
+	}
+	validator, err := document.NewPolicyValidator(policies)
+	if err != nil {
+		return err
+	}
+
+	violations, err := validator.Validate(rendered)
+	if err != nil {
+		return err
+	}
+	var failed []document.PolicyViolation
+	for _, violation := range violations {
+		log.Print(violation.String())
+		if violation.Severity == v1alpha1.PolicySeverityError {
+			failed = append(failed, violation)
+		}
+	}
+	if len(failed) > 0 {
+		return document.ErrPolicyValidationFailed{Violations: failed}
+	}
+	return nil
+}
+
+// validateOffline validates the rendered documents in-process against the schemas from the local
+// schema location of validation config and the CRDs, no validation container is executed
+func validateOffline(rendered document.Bundle, helper ifc.Helper, validationCfg v1alpha1.ValidationConfig) error {
+	location := strings.TrimPrefix(validationCfg.SchemaLocation, "file://")
+	if location == "" || strings.Contains(location, "://") {
+		return errors.ErrSchemaLocationNotLocal{Location: validationCfg.SchemaLocation}
+	}
+	if !filepath.IsAbs(location) {
+		location = filepath.Join(helper.TargetPath(), location)
+	}
+
+	// defaults are the same as the ones of the validation container
+	validator := document.NewSchemaValidator(document.SchemaValidatorOptions{
+		SchemaLocation:       location,
+		KubernetesVersion:    validationCfg.KubernetesVersion,
+		Strict:               validationCfg.Strict == nil || *validationCfg.Strict,
+		IgnoreMissingSchemas: validationCfg.IgnoreMissingSchemas != nil && *validationCfg.IgnoreMissingSchemas,
+		KindsToSkip:          validationCfg.KindsToSkip,
+	})
+
+	if err := validator.AddCRDs(rendered); err != nil {
+		return err
+	}
+	for _, path := range validationCfg.CRDList {
+		crds, err := document.NewBundleByPath(filepath.Join(helper.TargetPath(), path))
+		if err != nil {
+			return err
+		}
+		if err = validator.AddCRDs(crds); err != nil {
+			return err
+		}
+	}
+	return validator.Validate(rendered)
 }
 
 // Render executor documents
@@ -185,7 +335,13 @@ func (p *phase) Render(w io.Writer, executorRender bool, options ifc.RenderOptio
 		return err
 	}
 
-	bundle, err := document.NewBundleByPath(root)
+	var bundle document.Bundle
+	if options.ShowOrigin {
+		bundle, err = document.NewBundleWithOrigin(fs.NewDocumentFs(), root, p.helper.TargetPath(),
+			p.helper.BundleOptions()...)
+	} else {
+		bundle, err = document.NewBundleByPath(root, p.helper.BundleOptions()...)
+	}
 	if err != nil {
 		return err
 	}
@@ -237,6 +393,8 @@ type plan struct {
 	helper      ifc.Helper
 	apiObj      *v1alpha1.PhasePlan
 	phaseClient ifc.Client
+	noCache     bool
+	offline     bool
 }
 
 // Validate makes sure that phase plan is properly configured
@@ -255,7 +413,11 @@ func (p *plan) Validate() error {
 		if err != nil {
 			return err
 		}
-		if err = validate(executor, p.helper, p.apiObj.ValidationCfg); err != nil {
+		docRoot, err := documentRoot(phaseRunner)
+		if err != nil {
+			return err
+		}
+		if err = validate(executor, docRoot, p.helper, p.apiObj.ValidationCfg, p.noCache, p.offline); err != nil {
 			return err
 		}
 	}
@@ -307,6 +469,8 @@ type client struct {
 	ifc.Helper
 
 	registry ExecutorRegistry
+	noCache  bool
+	offline  bool
 }
 
 // Option allows to add various options to a phase
@@ -319,6 +483,22 @@ func InjectRegistry(registry ExecutorRegistry) Option {
 	}
 }
 
+// DisableCache is an option that disables caching of the container output for phases and plans
+// created by the phase client, containers are always executed in that case
+func DisableCache() Option {
+	return func(c *client) {
+		c.noCache = true
+	}
+}
+
+// OfflineValidation is an option that makes phases and plans created by the phase client validate
+// their documents in-process against local schemas instead of running the validation container
+func OfflineValidation() Option {
+	return func(c *client) {
+		c.offline = true
+	}
+}
+
 // NewClient returns implementation of phase Client interface
 func NewClient(helper ifc.Helper, opts ...Option) ifc.Client {
 	c := &client{Helper: helper}
@@ -341,6 +521,8 @@ func (c *client) PhaseByID(id ifc.ID) (ifc.Phase, error) {
 		apiObj:   phaseObj,
 		helper:   c.Helper,
 		registry: c.registry,
+		noCache:  c.noCache,
+		offline:  c.offline,
 	}
 	return phase, nil
 }
@@ -354,6 +536,8 @@ func (c *client) PlanByID(id ifc.ID) (ifc.Plan, error) {
 		apiObj:      planObj,
 		helper:      c.Helper,
 		phaseClient: c,
+		noCache:     c.noCache,
+		offline:     c.offline,
 	}, nil
 }
 
@@ -362,6 +546,8 @@ func (c *client) PhaseByAPIObj(phaseObj *v1alpha1.Phase) (ifc.Phase, error) {
 		apiObj:   phaseObj,
 		helper:   c.Helper,
 		registry: c.registry,
+		noCache:  c.noCache,
+		offline:  c.offline,
 	}
 	return phase, nil
 }
@@ -15,6 +15,7 @@
 package phase_test
 
 import (
+	goerrors "errors"
 	"fmt"
 	"io"
 	"testing"
@@ -25,6 +26,7 @@ import (
 
 	"opendev.org/airship/airshipctl/pkg/api/v1alpha1"
 	"opendev.org/airship/airshipctl/pkg/config"
+	"opendev.org/airship/airshipctl/pkg/document"
 	"opendev.org/airship/airshipctl/pkg/phase"
 	"opendev.org/airship/airshipctl/pkg/phase/errors"
 	"opendev.org/airship/airshipctl/pkg/phase/ifc"
@@ -276,6 +278,85 @@ func TestPhaseValidate(t *testing.T) {
 	}
 }
 
+func TestPhaseValidateOffline(t *testing.T) {
+	tests := []struct {
+		name        string
+		phaseID     ifc.ID
+		expectedErr error
+	}{
+		{
+			name:    "local schema location",
+			phaseID: ifc.ID{Name: "kube_apply_offline"},
+		},
+		{
+			name:        "remote schema location",
+			phaseID:     ifc.ID{Name: "kube_apply_remote_schemas"},
+			expectedErr: errors.ErrSchemaLocationNotLocal{Location: "https://example.com/schemas/"},
+		},
+		{
+			name:    "validation policies",
+			phaseID: ifc.ID{Name: "kube_apply_policies"},
+		},
+		{
+			name:        "unknown validation policy",
+			phaseID:     ifc.ID{Name: "kube_apply_unknown_policy"},
+			expectedErr: document.ErrDocNotFound{Selector: document.NewValidationPolicySelector("not-exist")},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			conf := testConfig(t)
+			conf.Manifests["dummy_manifest"].MetadataPath = "valid_validation_site/metadata.yaml"
+			helper, err := phase.NewHelper(conf)
+			require.NoError(t, err)
+			registry := func() map[schema.GroupVersionKind]ifc.ExecutorFactory {
+				gvk := schema.GroupVersionKind{
+					Group:   "airshipit.org",
+					Version: "v1alpha1",
+					Kind:    "KubernetesApply",
+				}
+				return map[schema.GroupVersionKind]ifc.ExecutorFactory{
+					gvk: fakeExecFactory,
+				}
+			}
+			client := phase.NewClient(helper, phase.InjectRegistry(registry), phase.OfflineValidation())
+			p, err := client.PhaseByID(tt.phaseID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedErr, p.Validate())
+		})
+	}
+}
+
+func TestPhaseValidateOrigin(t *testing.T) {
+	conf := testConfig(t)
+	conf.Manifests["dummy_manifest"].MetadataPath = "valid_validation_site/metadata.yaml"
+	helper, err := phase.NewHelper(conf)
+	require.NoError(t, err)
+	registry := func() map[schema.GroupVersionKind]ifc.ExecutorFactory {
+		gvk := schema.GroupVersionKind{
+			Group:   "airshipit.org",
+			Version: "v1alpha1",
+			Kind:    "KubernetesApply",
+		}
+		return map[schema.GroupVersionKind]ifc.ExecutorFactory{
+			gvk: renderExecFactory,
+		}
+	}
+	client := phase.NewClient(helper, phase.InjectRegistry(registry))
+	p, err := client.PhaseByID(ifc.ID{Name: "kube_apply_policy_violation"})
+	require.NoError(t, err)
+
+	err = p.Validate()
+	failed := document.ErrPolicyValidationFailed{}
+	require.True(t, goerrors.As(err, &failed))
+	require.Len(t, failed.Violations, 1)
+	assert.Equal(t, "site-app", failed.Violations[0].Name)
+	assert.Equal(t, "valid_validation_site/latest/deployment.yaml "+
+		"(via valid_validation_site/latest/kustomization.yaml)", failed.Violations[0].Origin)
+}
+
 func (e fakeExecutor) Status() (ifc.ExecutorStatus, error) {
 	return ifc.ExecutorStatus{}, nil
 }
@@ -503,14 +584,27 @@ func fakeExecFactory(_ ifc.ExecutorConfig) (ifc.Executor, error) {
 	return fakeExecutor{}, nil
 }
 
+// renderExecFactory returns the executor rendering the documents of the phase entrypoint
+func renderExecFactory(cfg ifc.ExecutorConfig) (ifc.Executor, error) {
+	bundle, err := cfg.BundleFactory()
+	if err != nil {
+		return nil, err
+	}
+	return fakeExecutor{bundle: bundle}, nil
+}
+
 var _ ifc.Executor = fakeExecutor{}
 
 type fakeExecutor struct {
 	validate error
+	bundle   document.Bundle
 }
 
-func (e fakeExecutor) Render(_ io.Writer, _ ifc.RenderOptions) error {
-	return nil
+func (e fakeExecutor) Render(w io.Writer, _ ifc.RenderOptions) error {
+	if e.bundle == nil {
+		return nil
+	}
+	return e.bundle.Write(w)
 }
 
 func (e fakeExecutor) Run(_ ifc.RunOptions) error {
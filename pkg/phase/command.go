@@ -53,6 +53,8 @@ type RunCommand struct {
 	PhaseID ifc.ID
 	Options ifc.RunOptions
 	Factory config.Factory
+	// TolerateDecryptionFailures leaves the documents which can't be decrypted encrypted
+	TolerateDecryptionFailures bool
 }
 
 // RunE runs the phase
@@ -62,7 +64,11 @@ func (c *RunCommand) RunE() error {
 		return err
 	}
 
-	helper, err := NewHelper(cfg)
+	var opts []document.BundleOption
+	if c.TolerateDecryptionFailures {
+		opts = append(opts, document.TolerateDecryptionFailures())
+	}
+	helper, err := NewHelper(cfg, opts...)
 	if err != nil {
 		return err
 	}
@@ -111,16 +117,37 @@ func (c *ListCommand) RunE() error {
 	return yaml.WriteOut(c.Writer, phaseList)
 }
 
+// Output formats of tree command
+const (
+	// TreeOutputTree prints the tree view of kustomize entrypoints
+	TreeOutputTree = "tree"
+	// TreeOutputJSON writes the tree as JSON document
+	TreeOutputJSON = "json"
+	// TreeOutputDOT writes the tree as Graphviz DOT digraph
+	TreeOutputDOT = "dot"
+)
+
 // TreeCommand plan command
 type TreeCommand struct {
 	Factory  config.Factory
 	PhaseID  ifc.ID
 	Writer   io.Writer
 	Argument string
+	// Output is the format of the tree, these can be [tree|json|dot], tree is used if empty
+	Output string
 }
 
 // RunE runs the phase tree command
 func (c *TreeCommand) RunE() error {
+	switch c.Output {
+	case "", TreeOutputTree, TreeOutputJSON, TreeOutputDOT:
+	default:
+		return phaseerrors.ErrUnknownTreeOutput{
+			Output:       c.Output,
+			ValidOutputs: []string{TreeOutputTree, TreeOutputJSON, TreeOutputDOT},
+		}
+	}
+
 	var entrypoint string
 	cfg, err := c.Factory()
 	if err != nil {
@@ -158,8 +185,15 @@ func (c *TreeCommand) RunE() error {
 	if err != nil {
 		return err
 	}
-	t.PrintTree("")
-	return nil
+	switch c.Output {
+	case TreeOutputJSON:
+		return t.WriteJSON(c.Writer)
+	case TreeOutputDOT:
+		return t.WriteDOT(c.Writer)
+	default:
+		t.PrintTree("")
+		return nil
+	}
 }
 
 // PlanListFlags flags given for plan list command
@@ -267,6 +301,9 @@ func (c *ClusterListCommand) RunE() error {
 // ValidateFlags options for phase validate command
 type ValidateFlags struct {
 	PhaseID ifc.ID
+	NoCache bool
+	// Offline validates the documents in-process against local schemas instead of running validation container
+	Offline bool
 }
 
 // ValidateCommand phase validate command
@@ -287,7 +324,14 @@ func (c *ValidateCommand) RunE() error {
 		return err
 	}
 
-	client := NewClient(helper)
+	var opts []Option
+	if c.Options.NoCache {
+		opts = append(opts, DisableCache())
+	}
+	if c.Options.Offline {
+		opts = append(opts, OfflineValidation())
+	}
+	client := NewClient(helper, opts...)
 
 	phase, err := client.PhaseByID(c.Options.PhaseID)
 	if err != nil {
@@ -332,7 +376,8 @@ func (s *StatusCommand) RunE() error {
 
 // PlanValidateFlags options for plan validate command
 type PlanValidateFlags struct {
-	PlanID ifc.ID
+	PlanID  ifc.ID
+	NoCache bool
 }
 
 // PlanValidateCommand plan validate command
@@ -353,7 +398,11 @@ func (c *PlanValidateCommand) RunE() error {
 		return err
 	}
 
-	client := NewClient(helper)
+	var opts []Option
+	if c.Options.NoCache {
+		opts = append(opts, DisableCache())
+	}
+	client := NewClient(helper, opts...)
 
 	plan, err := client.PlanByID(c.Options.PlanID)
 	if err != nil {
@@ -256,6 +256,7 @@ func TestListCommand(t *testing.T) {
 func TestTreeCommand(t *testing.T) {
 	tests := []struct {
 		name        string
+		output      string
 		errContains string
 		factory     config.Factory
 	}{
@@ -266,6 +267,11 @@ func TestTreeCommand(t *testing.T) {
 			},
 			errContains: testFactoryErr,
 		},
+		{
+			name:        "Error unknown output",
+			output:      "yaml",
+			errContains: "wrong tree output 'yaml' specified must be one of [tree json dot]",
+		},
 		{
 			name: "Error new helper",
 			factory: func() (*config.Config, error) {
@@ -308,6 +314,7 @@ func TestTreeCommand(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			command := phase.TreeCommand{
 				Factory: tt.factory,
+				Output:  tt.output,
 			}
 			err := command.RunE()
 			if tt.errContains != "" {
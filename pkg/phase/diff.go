@@ -0,0 +1,284 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package phase
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"opendev.org/airship/airshipctl/pkg/config"
+	"opendev.org/airship/airshipctl/pkg/document"
+	phaseerrors "opendev.org/airship/airshipctl/pkg/phase/errors"
+	"opendev.org/airship/airshipctl/pkg/phase/ifc"
+	"opendev.org/airship/airshipctl/pkg/util/yaml"
+)
+
+const (
+	// TextOutputFormat human readable output
+	TextOutputFormat = "text"
+	// JSONOutputFormat json
+	JSONOutputFormat = "json"
+)
+
+// DiffCommand phase diff command
+type DiffCommand struct {
+	Factory config.Factory
+	PhaseID ifc.ID
+	// Against is either a path to another checkout of the phase repository or a git revision of it
+	Against string
+	// Live compares the phase documents with the objects of the target cluster
+	Live bool
+	// FailOnDecryptionError makes sure that encrypted documents are decrypted
+	FailOnDecryptionError bool
+	Format                string
+	Writer                io.Writer
+}
+
+// RunE renders the phase documents from the current and the other version of the phase
+// repository, or takes the objects of the target cluster, and prints the differences
+func (c *DiffCommand) RunE() error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+	var opts []document.BundleOption
+	if !c.FailOnDecryptionError {
+		opts = append(opts, document.TolerateDecryptionFailures())
+	}
+
+	cfg, err := c.Factory()
+	if err != nil {
+		return err
+	}
+	helper, err := NewHelper(cfg, opts...)
+	if err != nil {
+		return err
+	}
+	phase, err := NewClient(helper).PhaseByID(c.PhaseID)
+	if err != nil {
+		return err
+	}
+	if c.Live {
+		return c.liveDiff(phase)
+	}
+	return c.againstDiff(helper, phase)
+}
+
+func (c *DiffCommand) validate() error {
+	if c.Format != TextOutputFormat && c.Format != JSONOutputFormat && c.Format != YamlOutputFormat {
+		return phaseerrors.ErrInvalidDiffFormat{RequestedFormat: c.Format}
+	}
+	if c.Against == "" && !c.Live {
+		return phaseerrors.ErrDiffSourceNotSpecified{}
+	}
+	if c.Against != "" && c.Live {
+		return phaseerrors.ErrDiffSourceConflict{}
+	}
+	return nil
+}
+
+// againstDiff compares the documents of the phase with the ones rendered from the other checkout
+// or git revision of the phase repository
+func (c *DiffCommand) againstDiff(helper ifc.Helper, phase ifc.Phase) error {
+	root, err := phase.DocumentRoot()
+	if err != nil {
+		return err
+	}
+	repoDir := filepath.Join(helper.TargetPath(), helper.PhaseRepoDir())
+	relativeRoot, err := filepath.Rel(repoDir, root)
+	if err != nil {
+		return err
+	}
+
+	againstRepoDir := c.Against
+	if info, statErr := os.Stat(c.Against); statErr != nil || !info.IsDir() {
+		var tmpDir string
+		if tmpDir, err = ioutil.TempDir("", "airshipctl-phase-diff-"); err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmpDir)
+		if againstRepoDir, err = checkoutRevision(helper, c.Against, tmpDir); err != nil {
+			return err
+		}
+	}
+
+	oldBundle, err := document.NewBundleByPath(filepath.Join(againstRepoDir, relativeRoot), helper.BundleOptions()...)
+	if err != nil {
+		return err
+	}
+	newBundle, err := document.NewBundleByPath(root, helper.BundleOptions()...)
+	if err != nil {
+		return err
+	}
+	diffs, err := document.DiffBundles(oldBundle, newBundle)
+	if err != nil {
+		return err
+	}
+	return printDiff(c.Writer, c.Format, diffs)
+}
+
+// liveDiff compares the documents of the phase with the objects of the target cluster
+func (c *DiffCommand) liveDiff(phase ifc.Phase) error {
+	executor, err := phase.Executor()
+	if err != nil {
+		return err
+	}
+	differ, ok := executor.(ifc.LiveDiffer)
+	if !ok {
+		return phaseerrors.ErrLiveDiffNotSupported{PhaseName: c.PhaseID.Name}
+	}
+	diffs, err := differ.LiveDiff()
+	if err != nil {
+		return err
+	}
+	return printDiff(c.Writer, c.Format, diffs)
+}
+
+// checkoutRevision writes the files of the phase repository at the given revision to the dir.
+// Other repositories of the target path are linked next to it, so that the references
+// between the repositories keep working
+func checkoutRevision(helper ifc.Helper, revision, dir string) (string, error) {
+	repoDir := filepath.Join(helper.TargetPath(), helper.PhaseRepoDir())
+	repo, err := git.PlainOpenWithOptions(repoDir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return "", phaseerrors.ErrDiffRevision{Revision: revision, Err: err}
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return "", phaseerrors.ErrDiffRevision{Revision: revision, Err: err}
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return "", err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", err
+	}
+
+	// phase repository may be a subdirectory of git work tree
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	absRepoDir, err := filepath.Abs(repoDir)
+	if err != nil {
+		return "", err
+	}
+	prefix, err := filepath.Rel(worktree.Filesystem.Root(), absRepoDir)
+	if err != nil {
+		return "", err
+	}
+	if prefix != "." {
+		if tree, err = tree.Tree(filepath.ToSlash(prefix)); err != nil {
+			return "", err
+		}
+	}
+
+	siblings, err := ioutil.ReadDir(helper.TargetPath())
+	if err != nil {
+		return "", err
+	}
+	for _, sibling := range siblings {
+		if sibling.Name() == helper.PhaseRepoDir() {
+			continue
+		}
+		var target string
+		if target, err = filepath.Abs(filepath.Join(helper.TargetPath(), sibling.Name())); err != nil {
+			return "", err
+		}
+		if err = os.Symlink(target, filepath.Join(dir, sibling.Name())); err != nil {
+			return "", err
+		}
+	}
+
+	checkoutDir := filepath.Join(dir, helper.PhaseRepoDir())
+	return checkoutDir, tree.Files().ForEach(func(f *object.File) error {
+		return writeTreeFile(checkoutDir, f)
+	})
+}
+
+func writeTreeFile(dir string, f *object.File) error {
+	path := filepath.Join(dir, filepath.FromSlash(f.Name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	contents, err := f.Contents()
+	if err != nil {
+		return err
+	}
+	switch f.Mode {
+	case filemode.Symlink:
+		return os.Symlink(contents, path)
+	case filemode.Executable:
+		return ioutil.WriteFile(path, []byte(contents), 0755)
+	default:
+		return ioutil.WriteFile(path, []byte(contents), 0644)
+	}
+}
+
+func printDiff(w io.Writer, format string, diffs []document.DocumentDiff) error {
+	if diffs == nil {
+		diffs = []document.DocumentDiff{}
+	}
+	switch format {
+	case JSONOutputFormat:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(diffs)
+	case YamlOutputFormat:
+		return yaml.WriteOut(w, diffs)
+	}
+
+	counts := map[string]int{}
+	for _, diff := range diffs {
+		counts[diff.Status]++
+		name := diff.Name
+		if diff.Namespace != "" {
+			name = diff.Namespace + "/" + name
+		}
+		fmt.Fprintf(w, "%s %s %s %s\n", diff.Status, diff.APIVersion, diff.Kind, name)
+		for _, change := range diff.Changes {
+			switch {
+			case change.Old == nil:
+				fmt.Fprintf(w, "  + %s: %s\n", change.Path, formatDiffValue(change.New))
+			case change.New == nil:
+				fmt.Fprintf(w, "  - %s: %s\n", change.Path, formatDiffValue(change.Old))
+			default:
+				fmt.Fprintf(w, "  ~ %s: %s -> %s\n", change.Path,
+					formatDiffValue(change.Old), formatDiffValue(change.New))
+			}
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d added, %d removed, %d modified\n",
+		counts[document.DiffAdded], counts[document.DiffRemoved], counts[document.DiffModified])
+	return err
+}
+
+func formatDiffValue(val interface{}) string {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprintf("%v", val)
+	}
+	return string(data)
+}
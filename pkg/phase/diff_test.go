@@ -0,0 +1,159 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package phase_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"opendev.org/airship/airshipctl/pkg/config"
+	"opendev.org/airship/airshipctl/pkg/phase"
+	"opendev.org/airship/airshipctl/pkg/phase/errors"
+	"opendev.org/airship/airshipctl/pkg/phase/ifc"
+	"opendev.org/airship/airshipctl/testutil"
+)
+
+func TestDiffCommand(t *testing.T) {
+	// prepare a copy of the phase documents with changed entrypoint of capi_init phase
+	againstDir, cleanup := testutil.TempDir(t, "airship-phase-diff")
+	defer cleanup(t)
+	phasesDir := filepath.Join(againstDir, "valid_site", "phases")
+	require.NoError(t, copyFiles(filepath.Join("testdata", "valid_site", "phases"), phasesDir))
+	capiInit := filepath.Join(phasesDir, "capi_init.yaml")
+	data, err := ioutil.ReadFile(capiInit)
+	require.NoError(t, err)
+	data = []byte(strings.Replace(string(data), "documentEntryPoint: valid_site/phases",
+		"documentEntryPoint: valid_site/old", 1))
+	require.NoError(t, ioutil.WriteFile(capiInit, data, 0600))
+
+	tests := []struct {
+		name        string
+		against     string
+		live        bool
+		format      string
+		expectedOut string
+		expectedErr error
+	}{
+		{
+			name:        "no differences",
+			against:     "testdata",
+			format:      phase.TextOutputFormat,
+			expectedOut: "0 added, 0 removed, 0 modified\n",
+		},
+		{
+			name:    "text",
+			against: againstDir,
+			format:  phase.TextOutputFormat,
+			expectedOut: "modified airshipit.org/v1alpha1 Phase capi_init\n" +
+				"  ~ config.documentEntryPoint: \"valid_site/old\" -> \"valid_site/phases\"\n" +
+				"0 added, 0 removed, 1 modified\n",
+		},
+		{
+			name:    "json",
+			against: againstDir,
+			format:  phase.JSONOutputFormat,
+			expectedOut: `[
+  {
+    "apiVersion": "airshipit.org/v1alpha1",
+    "kind": "Phase",
+    "name": "capi_init",
+    "status": "modified",
+    "changes": [
+      {
+        "path": "config.documentEntryPoint",
+        "old": "valid_site/old",
+        "new": "valid_site/phases"
+      }
+    ]
+  }
+]
+`,
+		},
+		{
+			name:        "invalid format",
+			against:     "testdata",
+			format:      "table",
+			expectedErr: errors.ErrInvalidDiffFormat{RequestedFormat: "table"},
+		},
+		{
+			name:        "against and live",
+			against:     "testdata",
+			live:        true,
+			format:      phase.TextOutputFormat,
+			expectedErr: errors.ErrDiffSourceConflict{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			cmd := phase.DiffCommand{
+				Factory: func() (*config.Config, error) { return testConfig(t), nil },
+				PhaseID: ifc.ID{Name: "capi_init"},
+				Against: tt.against,
+				Live:    tt.live,
+				Format:  tt.format,
+				Writer:  out,
+			}
+			err := cmd.RunE()
+			if tt.expectedErr != nil {
+				assert.Equal(t, tt.expectedErr, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedOut, out.String())
+		})
+	}
+}
+
+func TestDiffCommandUnknownRevision(t *testing.T) {
+	cmd := phase.DiffCommand{
+		Factory: func() (*config.Config, error) { return testConfig(t), nil },
+		PhaseID: ifc.ID{Name: "capi_init"},
+		Against: "does-not-exist",
+		Format:  phase.TextOutputFormat,
+		Writer:  ioutil.Discard,
+	}
+	err := cmd.RunE()
+	assert.IsType(t, errors.ErrDiffRevision{}, err)
+}
+
+func copyFiles(src, dst string) error {
+	files, err := ioutil.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+	for _, f := range files {
+		var data []byte
+		if data, err = ioutil.ReadFile(filepath.Join(src, f.Name())); err != nil {
+			return err
+		}
+		if err = ioutil.WriteFile(filepath.Join(dst, f.Name()), data, 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
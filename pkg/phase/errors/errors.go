@@ -41,6 +41,28 @@ func (e ErrUnknownRenderSource) Error() string {
 		e.Source, e.ValidSources)
 }
 
+// ErrUnknownRenderOutput returned when render command output doesn't match any known formats
+type ErrUnknownRenderOutput struct {
+	Output       string
+	ValidOutputs []string
+}
+
+func (e ErrUnknownRenderOutput) Error() string {
+	return fmt.Sprintf("wrong render output '%s' specified must be one of %v",
+		e.Output, e.ValidOutputs)
+}
+
+// ErrUnknownTreeOutput returned when tree command output doesn't match any known formats
+type ErrUnknownTreeOutput struct {
+	Output       string
+	ValidOutputs []string
+}
+
+func (e ErrUnknownTreeOutput) Error() string {
+	return fmt.Sprintf("wrong tree output '%s' specified must be one of %v",
+		e.Output, e.ValidOutputs)
+}
+
 // ErrRenderPhaseNameNotSpecified returned when render command is called with either phase or
 // executor source and phase name is not specified
 type ErrRenderPhaseNameNotSpecified struct {
@@ -52,6 +74,16 @@ func (e ErrRenderPhaseNameNotSpecified) Error() string {
 		e.Sources)
 }
 
+// ErrShowOriginNotSupported returned when render command is asked to show the origin of
+// the documents of the source which doesn't support it
+type ErrShowOriginNotSupported struct {
+	Source string
+}
+
+func (e ErrShowOriginNotSupported) Error() string {
+	return fmt.Sprintf("showing the origin of the documents is not supported for '%s' source", e.Source)
+}
+
 // ErrInvalidFormat is called when the user provides format other than yaml/json
 type ErrInvalidFormat struct {
 	RequestedFormat string
@@ -78,3 +110,57 @@ type ErrInvalidOutputFormat struct {
 func (e ErrInvalidOutputFormat) Error() string {
 	return fmt.Sprintf("invalid output format specified %s. Allowed values are table|name", e.RequestedFormat)
 }
+
+// ErrInvalidDiffFormat is returned when the user provides format other than text/json/yaml
+type ErrInvalidDiffFormat struct {
+	RequestedFormat string
+}
+
+func (e ErrInvalidDiffFormat) Error() string {
+	return fmt.Sprintf("invalid output format specified %s. Allowed values are text|json|yaml", e.RequestedFormat)
+}
+
+// ErrDiffRevision is returned when the revision to compare with can't be found in the phase repository
+type ErrDiffRevision struct {
+	Revision string
+	Err      error
+}
+
+func (e ErrDiffRevision) Error() string {
+	return fmt.Sprintf("%s is neither a directory nor a revision of the phase repository: %v", e.Revision, e.Err)
+}
+
+// ErrDiffSourceNotSpecified is returned when there is nothing to compare the phase documents with
+type ErrDiffSourceNotSpecified struct{}
+
+func (e ErrDiffSourceNotSpecified) Error() string {
+	return "either the path or git revision to compare the phase documents with or the live cluster must be specified"
+}
+
+// ErrDiffSourceConflict is returned when the phase documents are requested to be compared
+// with another version of the phase repository and with the cluster at the same time
+type ErrDiffSourceConflict struct{}
+
+func (e ErrDiffSourceConflict) Error() string {
+	return "comparing with a path or git revision and with the live cluster are mutually exclusive"
+}
+
+// ErrLiveDiffNotSupported is returned when the executor of the phase can't compare its documents with the cluster
+type ErrLiveDiffNotSupported struct {
+	PhaseName string
+}
+
+func (e ErrLiveDiffNotSupported) Error() string {
+	return fmt.Sprintf("executor of phase %s doesn't support comparison with the live cluster", e.PhaseName)
+}
+
+// ErrSchemaLocationNotLocal returned when offline validation is requested and schema location of
+// the validation config is not a local directory
+type ErrSchemaLocationNotLocal struct {
+	Location string
+}
+
+func (e ErrSchemaLocationNotLocal) Error() string {
+	return fmt.Sprintf("offline validation requires schemaLocation of validation config to be a local "+
+		"directory, got '%s'", e.Location)
+}
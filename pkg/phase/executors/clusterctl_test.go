@@ -320,7 +320,7 @@ func TestClusterctlExecutorRun(t *testing.T) {
 				return "cluster", nil
 			}},
 			clientFunc: func(_ string, _ io.Reader, _ io.Writer,
-				_ *v1alpha1.GenericContainer, _ string) container.ClientV1Alpha1 {
+				_ *v1alpha1.GenericContainer, _ string, _ ...container.ClientOption) container.ClientV1Alpha1 {
 				return MockClientFuncInterface{MockRun: func() error {
 					return nil
 				}}
@@ -339,7 +339,7 @@ func TestClusterctlExecutorRun(t *testing.T) {
 					return "parentCluster", nil
 				}},
 			clientFunc: func(_ string, _ io.Reader, _ io.Writer,
-				_ *v1alpha1.GenericContainer, _ string) container.ClientV1Alpha1 {
+				_ *v1alpha1.GenericContainer, _ string, _ ...container.ClientOption) container.ClientV1Alpha1 {
 				return MockClientFuncInterface{MockRun: func() error {
 					return nil
 				}}
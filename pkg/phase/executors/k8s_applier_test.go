@@ -295,7 +295,7 @@ func TestKubeApplierExecutorRun(t *testing.T) {
 				},
 			}),
 			clientFunc: func(_ string, _ io.Reader, _ io.Writer,
-				_ *v1alpha1.GenericContainer, _ string) container.ClientV1Alpha1 {
+				_ *v1alpha1.GenericContainer, _ string, _ ...container.ClientOption) container.ClientV1Alpha1 {
 				return MockClientFuncInterface{MockRun: func() error {
 					return errors.New("applier failure")
 				}}
@@ -314,7 +314,7 @@ func TestKubeApplierExecutorRun(t *testing.T) {
 				},
 			}),
 			clientFunc: func(_ string, _ io.Reader, _ io.Writer,
-				_ *v1alpha1.GenericContainer, _ string) container.ClientV1Alpha1 {
+				_ *v1alpha1.GenericContainer, _ string, _ ...container.ClientOption) container.ClientV1Alpha1 {
 				return MockClientFuncInterface{MockRun: func() error {
 					return nil
 				}}
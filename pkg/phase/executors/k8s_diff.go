@@ -0,0 +1,66 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package executors
+
+import (
+	"opendev.org/airship/airshipctl/pkg/document"
+	"opendev.org/airship/airshipctl/pkg/k8s/diff"
+	"opendev.org/airship/airshipctl/pkg/k8s/utils"
+	"opendev.org/airship/airshipctl/pkg/log"
+	"opendev.org/airship/airshipctl/pkg/phase/ifc"
+)
+
+var _ ifc.LiveDiffer = &KubeApplierExecutor{}
+
+// LiveDiff compares the documents which would be applied by the executor with the objects of the cluster
+func (e *KubeApplierExecutor) LiveDiff() ([]document.DocumentDiff, error) {
+	kcfg, kctx := e.apiObject.Config.Kubeconfig, e.apiObject.Config.Context
+	if kcfg == "" {
+		var cleanup func()
+		var err error
+		kcfg, kctx, cleanup, err = e.getKubeconfig()
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+	}
+	log.Debugf("using kubeconfig at '%s' and context '%s'", kcfg, kctx)
+
+	factory := utils.FactoryFromKubeConfig(kcfg, kctx)
+	client, err := factory.DynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	mapper, err := factory.ToRESTMapper()
+	if err != nil {
+		return nil, err
+	}
+
+	bundle, err := e.ExecutorBundle.SelectBundle(document.NewDeployToK8sSelector())
+	if err != nil {
+		return nil, err
+	}
+	docs, err := bundle.GetAllDocuments()
+	if err != nil {
+		return nil, err
+	}
+
+	return diff.Live(docs, diff.Options{
+		Client:             client,
+		Mapper:             mapper,
+		InventoryID:        e.BundleName,
+		InventoryNamespace: "airshipit-" + e.BundleName,
+	})
+}
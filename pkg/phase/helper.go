@@ -25,6 +25,8 @@ import (
 	"opendev.org/airship/airshipctl/pkg/config"
 	"opendev.org/airship/airshipctl/pkg/document"
 	"opendev.org/airship/airshipctl/pkg/document/metadata"
+	"opendev.org/airship/airshipctl/pkg/document/plugin"
+	"opendev.org/airship/airshipctl/pkg/document/sops"
 	"opendev.org/airship/airshipctl/pkg/inventory"
 	inventoryifc "opendev.org/airship/airshipctl/pkg/inventory/ifc"
 	"opendev.org/airship/airshipctl/pkg/log"
@@ -45,13 +47,31 @@ type Helper struct {
 
 	inventory         inventoryifc.Inventory
 	phaseConfigBundle document.Bundle
+	bundleOptions     []document.BundleOption
 }
 
-// NewHelper constructs metadata interface based on config
-func NewHelper(cfg *config.Config) (ifc.Helper, error) {
-	helper := &Helper{}
+// NewHelper constructs metadata interface based on config, the bundle options are used
+// to build all the document bundles together with the decryption keys from the config,
+// airshipctl KRM functions referenced by kustomizations are run in-process
+func NewHelper(cfg *config.Config, opts ...document.BundleOption) (ifc.Helper, error) {
+	helper := &Helper{
+		bundleOptions: []document.BundleOption{document.NativeFunctions(func(image string) bool {
+			_, found := plugin.Lookup(image)
+			return found
+		})},
+	}
+
+	decryption, err := cfg.CurrentContextDecryption()
+	if err != nil {
+		return nil, err
+	}
+	keyFiles := sops.KeyFiles{Age: decryption.AgeKeyFiles, PGP: decryption.PGPKeyFiles}
+	helper.bundleOptions = append(helper.bundleOptions, document.DecryptionKeyFiles(keyFiles))
+	if decryption.IgnoreMAC {
+		helper.bundleOptions = append(helper.bundleOptions, document.IgnoreDecryptionMAC())
+	}
+	helper.bundleOptions = append(helper.bundleOptions, opts...)
 
-	var err error
 	helper.targetPath, err = cfg.CurrentContextTargetPath()
 	if err != nil {
 		return nil, err
@@ -83,7 +103,8 @@ func NewHelper(cfg *config.Config) (ifc.Helper, error) {
 	helper.phaseEntryPointBasePath = filepath.Join(helper.targetPath, helper.phaseRepoDir,
 		helper.docEntryPointPrefix)
 	helper.inventory = inventory.NewInventory(func() (*config.Config, error) { return cfg, nil })
-	if helper.phaseConfigBundle, err = document.NewBundleByPath(helper.phaseBundleRoot); err != nil {
+	helper.phaseConfigBundle, err = document.NewBundleByPath(helper.phaseBundleRoot, helper.bundleOptions...)
+	if err != nil {
 		return nil, err
 	}
 	return helper, nil
@@ -325,3 +346,8 @@ func (helper *Helper) Inventory() inventoryifc.Inventory {
 func (helper *Helper) PhaseConfigBundle() document.Bundle {
 	return helper.phaseConfigBundle
 }
+
+// BundleOptions returns the options to build document bundles with
+func (helper *Helper) BundleOptions() []document.BundleOption {
+	return helper.bundleOptions
+}
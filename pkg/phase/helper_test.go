@@ -27,6 +27,7 @@ import (
 	"opendev.org/airship/airshipctl/pkg/api/v1alpha1"
 	airshipv1 "opendev.org/airship/airshipctl/pkg/api/v1alpha1"
 	"opendev.org/airship/airshipctl/pkg/config"
+	"opendev.org/airship/airshipctl/pkg/document"
 	"opendev.org/airship/airshipctl/pkg/phase"
 	"opendev.org/airship/airshipctl/pkg/phase/executors/errors"
 	"opendev.org/airship/airshipctl/pkg/phase/ifc"
@@ -587,6 +588,19 @@ func TestHelperInventory(t *testing.T) {
 	assert.NotNil(t, inv)
 }
 
+func TestHelperBundleOptions(t *testing.T) {
+	conf := testConfig(t)
+	helper, err := phase.NewHelper(conf)
+	require.NoError(t, err)
+	// native functions and decryption key files from the config are always passed
+	assert.Len(t, helper.BundleOptions(), 2)
+
+	conf.Manifests["dummy_manifest"].Decryption = &config.Decryption{IgnoreMAC: true}
+	helper, err = phase.NewHelper(conf, document.TolerateDecryptionFailures())
+	require.NoError(t, err)
+	assert.Len(t, helper.BundleOptions(), 4)
+}
+
 func testConfig(t *testing.T) *config.Config {
 	t.Helper()
 	confString := `apiVersion: airshipit.org/v1alpha1
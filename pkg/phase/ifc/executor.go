@@ -33,6 +33,11 @@ type Executor interface {
 	Status() (ExecutorStatus, error)
 }
 
+// LiveDiffer is implemented by executors which can compare their documents with the objects of the cluster
+type LiveDiffer interface {
+	LiveDiff() ([]document.DocumentDiff, error)
+}
+
 // ExecutorStatus is a struct which defines the status
 type ExecutorStatus struct{}
 
@@ -51,6 +56,9 @@ type PlanRunOptions struct {
 // RenderOptions holds options for render method
 type RenderOptions struct {
 	FilterSelector document.Selector
+	// ShowOrigin annotates the documents with their source files, kustomizations and patches,
+	// it is supported only when the documents are rendered from phase document entrypoint
+	ShowOrigin bool
 }
 
 // ExecutorFactory for executor instantiation
@@ -38,4 +38,5 @@ type Helper interface {
 	Inventory() ifc.Inventory
 	PhaseEntryPointBasePath() string
 	PhaseConfigBundle() document.Bundle
+	BundleOptions() []document.BundleOption
 }
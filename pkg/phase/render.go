@@ -15,12 +15,14 @@
 package phase
 
 import (
+	"bytes"
 	"io"
-	"os"
 	"strings"
 
 	"opendev.org/airship/airshipctl/pkg/config"
 	"opendev.org/airship/airshipctl/pkg/document"
+	"opendev.org/airship/airshipctl/pkg/document/plugin/replacement"
+	"opendev.org/airship/airshipctl/pkg/fs"
 	"opendev.org/airship/airshipctl/pkg/phase/errors"
 	"opendev.org/airship/airshipctl/pkg/phase/ifc"
 )
@@ -47,15 +49,31 @@ type RenderCommand struct {
 	APIVersion string
 	// Kind filters documents by document kind
 	Kind string
+	// Fields filters documents by field selectors, see document.ParseFieldSelector
+	Fields []string
+	// Exclude drops documents matching any of the selector expressions, see document.ParseSelector
+	Exclude []string
+	// Or adds documents matching any of the selector expressions to the result
+	Or []string
 	// Source identifies source of the bundle, these can be [phase|config|executor]
 	// phase the source will use kustomize root at phase entry point
 	// config will render a bundle that comes from site metadata file, and contains phase and executor docs
 	// executor means that rendering will be delegated to phase executor
 	Source string
-	// FailOnDecryptionError makes sure that encrypted documents are getting decrypted by avoiding setting
-	// env variable TOLERATE_DECRYPTION_FAILURES=true
+	// FailOnDecryptionError makes sure that encrypted documents are getting decrypted, otherwise
+	// the documents which can't be decrypted are rendered encrypted
 	FailOnDecryptionError bool
-	PhaseID               ifc.ID
+	// Output is the format of rendered documents, these can be [yaml|json|resourcelist|dir=<path>]
+	// yaml is multi-document yaml stream, json is kubernetes List, resourcelist is KRM function ResourceList
+	// dir=<path> writes a file per document and kustomization.yaml to the directory
+	Output string
+	// ShowOrigin annotates the documents with their source files, the chain of kustomizations
+	// and the patches applied, only phase source supports it
+	ShowOrigin bool
+	// TraceReplacements makes replacement transformers log their source values, matched targets and
+	// changed fields by annotating their configurations, see replacement.TraceAnnotation
+	TraceReplacements bool
+	PhaseID           ifc.ID
 }
 
 // RunE prints out filtered documents
@@ -64,8 +82,18 @@ func (fo *RenderCommand) RunE(cfgFactory config.Factory, out io.Writer) error {
 		return err
 	}
 
+	var opts []document.BundleOption
 	if !fo.FailOnDecryptionError {
-		os.Setenv("TOLERATE_DECRYPTION_FAILURES", "true")
+		opts = append(opts, document.TolerateDecryptionFailures())
+	}
+
+	if fo.TraceReplacements {
+		opts = append(opts, document.FunctionAnnotation(replacement.Kind, replacement.TraceAnnotation, "true"))
+	}
+
+	sel, err := fo.selector()
+	if err != nil {
+		return err
 	}
 
 	cfg, err := cfgFactory()
@@ -73,19 +101,35 @@ func (fo *RenderCommand) RunE(cfgFactory config.Factory, out io.Writer) error {
 		return err
 	}
 
-	helper, err := NewHelper(cfg)
+	helper, err := NewHelper(cfg, opts...)
 	if err != nil {
 		return err
 	}
-	groupVersion := strings.Split(fo.APIVersion, "/")
-	group := ""
-	version := groupVersion[0]
-	if len(groupVersion) > 1 {
-		group = groupVersion[0]
-		version = strings.Join(groupVersion[1:], "/")
+
+	format, dir := fo.outputFormat()
+	if format == document.YAMLOutputFormat {
+		return fo.render(out, helper, sel)
 	}
-	sel := document.NewSelector().ByLabel(fo.Label).ByAnnotation(fo.Annotation).ByGvk(group, version, fo.Kind)
+	buf := &bytes.Buffer{}
+	if err = fo.render(buf, helper, sel); err != nil {
+		return err
+	}
+	bundle, err := document.NewBundleFromStream(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	switch format {
+	case document.JSONOutputFormat:
+		return bundle.WriteJSON(out)
+	case document.ResourceListOutputFormat:
+		return bundle.WriteResourceList(out)
+	default:
+		return bundle.WriteDir(fs.NewDocumentFs(), dir)
+	}
+}
 
+// render writes out the documents of the source as multi-document yaml stream
+func (fo *RenderCommand) render(out io.Writer, helper ifc.Helper, sel document.Selector) error {
 	if fo.Source == RenderSourceConfig {
 		return renderConfigBundle(out, helper, sel)
 	}
@@ -100,7 +144,53 @@ func (fo *RenderCommand) RunE(cfgFactory config.Factory, out io.Writer) error {
 	if fo.Source == RenderSourceExecutor {
 		executorRender = true
 	}
-	return phase.Render(out, executorRender, ifc.RenderOptions{FilterSelector: sel})
+	return phase.Render(out, executorRender, ifc.RenderOptions{FilterSelector: sel, ShowOrigin: fo.ShowOrigin})
+}
+
+// outputFormat returns the output format and the directory of dir format
+func (fo *RenderCommand) outputFormat() (string, string) {
+	if fo.Output == "" {
+		return document.YAMLOutputFormat, ""
+	}
+	parts := strings.SplitN(fo.Output, "=", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+// selector builds document selector from the filters of the command
+func (fo *RenderCommand) selector() (document.Selector, error) {
+	groupVersion := strings.Split(fo.APIVersion, "/")
+	group := ""
+	version := groupVersion[0]
+	if len(groupVersion) > 1 {
+		group = groupVersion[0]
+		version = strings.Join(groupVersion[1:], "/")
+	}
+	sel := document.NewSelector().ByLabel(fo.Label).ByAnnotation(fo.Annotation).ByGvk(group, version, fo.Kind)
+	for _, expr := range fo.Fields {
+		field, err := document.ParseFieldSelector(expr)
+		if err != nil {
+			return document.Selector{}, err
+		}
+		sel = sel.ByField(field)
+	}
+	for _, expr := range fo.Or {
+		alternative, err := document.ParseSelector(expr)
+		if err != nil {
+			return document.Selector{}, err
+		}
+		sel = sel.Or(alternative)
+	}
+	for _, expr := range fo.Exclude {
+		excluded, err := document.ParseSelector(expr)
+		if err != nil {
+			return document.Selector{}, err
+		}
+		sel = sel.Except(excluded)
+	}
+	return sel, nil
 }
 
 func renderConfigBundle(out io.Writer, h ifc.Helper, sel document.Selector) error {
@@ -126,5 +216,27 @@ func (fo *RenderCommand) Validate() (err error) {
 			ValidSources: []string{RenderSourceConfig, RenderSourceExecutor, RenderSourcePhase},
 		}
 	}
-	return err
+	if err != nil {
+		return err
+	}
+	if fo.ShowOrigin && fo.Source != RenderSourcePhase {
+		return errors.ErrShowOriginNotSupported{Source: fo.Source}
+	}
+
+	format, dir := fo.outputFormat()
+	switch format {
+	case document.YAMLOutputFormat, document.JSONOutputFormat, document.ResourceListOutputFormat:
+		if dir == "" {
+			return nil
+		}
+	case document.DirOutputFormat:
+		if dir != "" {
+			return nil
+		}
+	}
+	return errors.ErrUnknownRenderOutput{
+		Output: fo.Output,
+		ValidOutputs: []string{document.YAMLOutputFormat, document.JSONOutputFormat,
+			document.ResourceListOutputFormat, document.DirOutputFormat + "=<path>"},
+	}
 }
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path"
 	"testing"
 
@@ -25,6 +26,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"opendev.org/airship/airshipctl/pkg/config"
+	"opendev.org/airship/airshipctl/pkg/document"
 	"opendev.org/airship/airshipctl/pkg/phase"
 	"opendev.org/airship/airshipctl/pkg/phase/errors"
 	"opendev.org/airship/airshipctl/pkg/phase/ifc"
@@ -76,6 +78,21 @@ func TestRender(t *testing.T) {
 			expResFile: "allFilters.yaml",
 			expErr:     nil,
 		},
+		{
+			name: "Show Origin",
+			settings: &phase.RenderCommand{
+				Label:      "airshipit.org/deploy-k8s=false",
+				Annotation: "airshipit.org/clustertype=ephemeral",
+				Kind:       "BareMetalHost",
+				Source:     phase.RenderSourcePhase,
+				ShowOrigin: true,
+				PhaseID: ifc.ID{
+					Name: fixturePath,
+				},
+			},
+			expResFile: "allFiltersOrigin.yaml",
+			expErr:     nil,
+		},
 		{
 			name: "Multiple Labels",
 			settings: &phase.RenderCommand{
@@ -99,6 +116,65 @@ func TestRender(t *testing.T) {
 			},
 			expErr: fmt.Errorf("unable to parse requirement: found '(', expected: identifier"),
 		},
+		{
+			name: "Exclude Secrets",
+			settings: &phase.RenderCommand{
+				Exclude: []string{"kind=Secret"},
+				Source:  phase.RenderSourcePhase,
+				PhaseID: ifc.ID{
+					Name: fixturePath,
+				},
+			},
+			expResFile: "multiLabels.yaml",
+			expErr:     nil,
+		},
+		{
+			name: "Field Selector",
+			settings: &phase.RenderCommand{
+				Fields: []string{"spec.networkData.name=node02-network-data"},
+				Source: phase.RenderSourcePhase,
+				PhaseID: ifc.ID{
+					Name: fixturePath,
+				},
+			},
+			expResFile: "multiLabels.yaml",
+			expErr:     nil,
+		},
+		{
+			name: "Malformed Exclude",
+			settings: &phase.RenderCommand{
+				Exclude: []string{"color=red"},
+				Source:  phase.RenderSourcePhase,
+				PhaseID: ifc.ID{
+					Name: fixturePath,
+				},
+			},
+			expErr: document.ErrInvalidSelectorExpression{Expression: "color=red", Reason: `unknown key "color"`},
+		},
+		{
+			name: "unknown output",
+			settings: &phase.RenderCommand{
+				Source: phase.RenderSourcePhase,
+				Output: "dir",
+				PhaseID: ifc.ID{
+					Name: fixturePath,
+				},
+			},
+			expErr: errors.ErrUnknownRenderOutput{Output: "dir",
+				ValidOutputs: []string{document.YAMLOutputFormat, document.JSONOutputFormat,
+					document.ResourceListOutputFormat, document.DirOutputFormat + "=<path>"}},
+		},
+		{
+			name: "show origin of executor source",
+			settings: &phase.RenderCommand{
+				Source:     phase.RenderSourceExecutor,
+				ShowOrigin: true,
+				PhaseID: ifc.ID{
+					Name: fixturePath,
+				},
+			},
+			expErr: errors.ErrShowOriginNotSupported{Source: phase.RenderSourceExecutor},
+		},
 		{
 			name: "source doesn't exist",
 			settings: &phase.RenderCommand{
@@ -136,6 +212,50 @@ func TestRender(t *testing.T) {
 	}
 }
 
+func TestRenderTraceReplacements(t *testing.T) {
+	rs := testutil.DummyConfig()
+	dummyManifest := rs.Manifests["dummy_manifest"]
+	dummyManifest.TargetPath = "testdata"
+	dummyManifest.PhaseRepositoryName = config.DefaultTestPhaseRepo
+	dummyManifest.Repositories = map[string]*config.Repository{
+		config.DefaultTestPhaseRepo: {
+			URLString: "",
+		},
+	}
+	dummyManifest.MetadataPath = "trace_site/metadata.yaml"
+
+	// replacement transformer is run by kustomize as separate process which logs the trace to stderr
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = stderr }()
+	trace := make(chan string)
+	go func() {
+		data, readErr := ioutil.ReadAll(r)
+		assert.NoError(t, readErr)
+		trace <- string(data)
+	}()
+
+	settings := &phase.RenderCommand{
+		Source:            phase.RenderSourcePhase,
+		PhaseID:           ifc.ID{Name: "replacements"},
+		TraceReplacements: true,
+	}
+	out := &bytes.Buffer{}
+	err = settings.RunE(func() (*config.Config, error) {
+		return rs, nil
+	}, out)
+	require.NoError(t, w.Close())
+	logged := <-trace
+	require.NoError(t, err)
+
+	assert.Contains(t, out.String(), "version: v2")
+	assert.Contains(t, logged, `replacements[0]: source value = "v2"`)
+	assert.Contains(t, logged, "replacements[0]: target ConfigMap target matched 1 document(s): ConfigMap target")
+	assert.Contains(t, logged, `replacements[0]: ConfigMap target data.version: "v1" -> "v2"`)
+}
+
 func TestRenderConfigBundle(t *testing.T) {
 	rs := testutil.DummyConfig()
 	dummyManifest := rs.Manifests["dummy_manifest"]
@@ -0,0 +1,162 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package secret
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"opendev.org/airship/airshipctl/pkg/config"
+	"opendev.org/airship/airshipctl/pkg/document/sops"
+)
+
+// DefaultEncryptedRegex matches the keys of kubernetes documents holding secret data
+const DefaultEncryptedRegex = "^(data|stringData)$"
+
+// KeyFilesFromConfig returns the key files listed in decryption section of the current context manifest
+func KeyFilesFromConfig(cfgFactory config.Factory) (sops.KeyFiles, error) {
+	cfg, err := cfgFactory()
+	if err != nil {
+		return sops.KeyFiles{}, err
+	}
+	decryption, err := cfg.CurrentContextDecryption()
+	if err != nil {
+		return sops.KeyFiles{}, err
+	}
+	return sops.KeyFiles{Age: decryption.AgeKeyFiles, PGP: decryption.PGPKeyFiles}, nil
+}
+
+// EncryptCommand holds options for secret encrypt command
+type EncryptCommand struct {
+	Files    []string
+	InPlace  bool
+	Options  sops.EncryptOptions
+	KeyFiles sops.KeyFiles
+	Writer   io.Writer
+}
+
+// RunE encrypts the documents of the files, documents which are already encrypted are left as is
+func (c *EncryptCommand) RunE() error {
+	keys, err := sops.KeysFromEnv(c.KeyFiles)
+	if err != nil {
+		return err
+	}
+	return processFiles(c.Files, c.InPlace, c.Writer, func(node *yaml.RNode) error {
+		if sops.IsEncrypted(node) {
+			return nil
+		}
+		return sops.Encrypt(node, keys, c.Options)
+	})
+}
+
+// DecryptCommand holds options for secret decrypt command
+type DecryptCommand struct {
+	Files     []string
+	InPlace   bool
+	IgnoreMAC bool
+	KeyFiles  sops.KeyFiles
+	Writer    io.Writer
+}
+
+// RunE decrypts encrypted documents of the files
+func (c *DecryptCommand) RunE() error {
+	keys, err := sops.KeysFromEnv(c.KeyFiles)
+	if err != nil {
+		return err
+	}
+	return processFiles(c.Files, c.InPlace, c.Writer, func(node *yaml.RNode) error {
+		if !sops.IsEncrypted(node) {
+			return nil
+		}
+		_, err := sops.Decrypt(node, keys, c.IgnoreMAC)
+		return err
+	})
+}
+
+// RotateCommand holds options for secret rotate command
+type RotateCommand struct {
+	Files    []string
+	InPlace  bool
+	Options  sops.RotateOptions
+	KeyFiles sops.KeyFiles
+	Writer   io.Writer
+}
+
+// RunE re-encrypts encrypted documents of the files with new data keys
+func (c *RotateCommand) RunE() error {
+	keys, err := sops.KeysFromEnv(c.KeyFiles)
+	if err != nil {
+		return err
+	}
+	return processFiles(c.Files, c.InPlace, c.Writer, func(node *yaml.RNode) error {
+		if !sops.IsEncrypted(node) {
+			return nil
+		}
+		return sops.Rotate(node, keys, c.Options)
+	})
+}
+
+// processFiles applies fn to every document of the files and writes the result back
+// to the files if inPlace is set, otherwise to the writer
+func processFiles(files []string, inPlace bool, out io.Writer, fn func(*yaml.RNode) error) error {
+	var all []*yaml.RNode
+	for _, file := range files {
+		nodes, err := readFile(file)
+		if err != nil {
+			return err
+		}
+		for _, node := range nodes {
+			if err = fn(node); err != nil {
+				return ErrProcessDocument{File: file, DocName: node.GetName(), Err: err}
+			}
+		}
+		if !inPlace {
+			all = append(all, nodes...)
+			continue
+		}
+		if err = writeFile(file, nodes); err != nil {
+			return err
+		}
+	}
+	if inPlace {
+		return nil
+	}
+	return kio.ByteWriter{Writer: out}.Write(all)
+}
+
+func readFile(file string) ([]*yaml.RNode, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return (&kio.ByteReader{Reader: bytes.NewReader(data), OmitReaderAnnotations: true}).Read()
+}
+
+func writeFile(file string, nodes []*yaml.RNode) error {
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	buf := &bytes.Buffer{}
+	if err = (kio.ByteWriter{Writer: buf}).Write(nodes); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, buf.Bytes(), info.Mode())
+}
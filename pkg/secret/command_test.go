@@ -0,0 +1,120 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package secret_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"opendev.org/airship/airshipctl/pkg/document/sops"
+	"opendev.org/airship/airshipctl/pkg/secret"
+	"opendev.org/airship/airshipctl/testutil"
+)
+
+const testSecrets = `apiVersion: v1
+kind: Secret
+metadata:
+  name: first
+data:
+  password: cGFzc3dvcmQ=
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: second
+data:
+  key: value
+`
+
+func TestEncryptDecryptRotate(t *testing.T) {
+	identity, err := sops.GenerateAgeIdentity()
+	require.NoError(t, err)
+	newIdentity, err := sops.GenerateAgeIdentity()
+	require.NoError(t, err)
+	os.Setenv(sops.EnvAgeKey, identity.String()+"\n"+newIdentity.String())
+	defer os.Unsetenv(sops.EnvAgeKey)
+
+	dir, cleanup := testutil.TempDir(t, "airship-secret")
+	defer cleanup(t)
+	file := filepath.Join(dir, "secrets.yaml")
+	require.NoError(t, ioutil.WriteFile(file, []byte(testSecrets), 0600))
+
+	encrypt := &secret.EncryptCommand{
+		Files:   []string{file},
+		InPlace: true,
+		Options: sops.EncryptOptions{
+			AgeRecipients:  []string{identity.Recipient()},
+			EncryptedRegex: secret.DefaultEncryptedRegex,
+		},
+	}
+	require.NoError(t, encrypt.RunE())
+	encrypted, err := ioutil.ReadFile(file)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encrypted), "cGFzc3dvcmQ=")
+	assert.Contains(t, string(encrypted), "name: second")
+
+	rotate := &secret.RotateCommand{
+		Files:   []string{file},
+		InPlace: true,
+		Options: sops.RotateOptions{
+			AddAge:    []string{newIdentity.Recipient()},
+			RemoveAge: []string{identity.Recipient()},
+		},
+	}
+	require.NoError(t, rotate.RunE())
+	rotated, err := ioutil.ReadFile(file)
+	require.NoError(t, err)
+	assert.Contains(t, string(rotated), newIdentity.Recipient())
+	assert.NotContains(t, string(rotated), identity.Recipient())
+
+	out := &bytes.Buffer{}
+	decrypt := &secret.DecryptCommand{Files: []string{file}, Writer: out}
+	require.NoError(t, decrypt.RunE())
+	assert.Equal(t, testSecrets, out.String())
+}
+
+func TestDecryptNoKeys(t *testing.T) {
+	identity, err := sops.GenerateAgeIdentity()
+	require.NoError(t, err)
+
+	dir, cleanup := testutil.TempDir(t, "airship-secret")
+	defer cleanup(t)
+	file := filepath.Join(dir, "secrets.yaml")
+	require.NoError(t, ioutil.WriteFile(file, []byte(testSecrets), 0600))
+
+	encrypt := &secret.EncryptCommand{
+		Files:   []string{file},
+		InPlace: true,
+		Options: sops.EncryptOptions{
+			AgeRecipients:  []string{identity.Recipient()},
+			EncryptedRegex: secret.DefaultEncryptedRegex,
+		},
+	}
+	require.NoError(t, encrypt.RunE())
+
+	decrypt := &secret.DecryptCommand{Files: []string{file}, Writer: ioutil.Discard}
+	err = decrypt.RunE()
+	assert.Equal(t, secret.ErrProcessDocument{
+		File:    file,
+		DocName: "first",
+		Err:     sops.ErrNoMatchingKey{Recipients: []string{"age " + identity.Recipient()}},
+	}, err)
+}
@@ -0,0 +1,72 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package secret
+
+import (
+	"fmt"
+)
+
+// ErrProcessDocument returned if the document of the file can't be encrypted or decrypted
+type ErrProcessDocument struct {
+	File    string
+	DocName string
+	Err     error
+}
+
+func (e ErrProcessDocument) Error() string {
+	return fmt.Sprintf("file %s, document %q: %v", e.File, e.DocName, e.Err)
+}
+
+// ErrSecretGenerationNotFound returned if the file doesn't contain SecretGeneration document
+type ErrSecretGenerationNotFound struct {
+	File string
+}
+
+func (e ErrSecretGenerationNotFound) Error() string {
+	return fmt.Sprintf("file %s doesn't contain SecretGeneration document", e.File)
+}
+
+// ErrInvalidSecretDefinition returned if SecretGeneration document is not valid
+type ErrInvalidSecretDefinition struct {
+	Name   string
+	Reason string
+}
+
+func (e ErrInvalidSecretDefinition) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("invalid SecretGeneration document: %s", e.Reason)
+	}
+	return fmt.Sprintf("invalid definition of secret %q: %s", e.Name, e.Reason)
+}
+
+// ErrInvalidGeneratedSecret returned if previously generated secret can't be parsed
+type ErrInvalidGeneratedSecret struct {
+	Name string
+	Err  error
+}
+
+func (e ErrInvalidGeneratedSecret) Error() string {
+	return fmt.Sprintf("invalid generated secret %q: %v", e.Name, e.Err)
+}
+
+// ErrGenerateSecret returned if the secret can't be generated
+type ErrGenerateSecret struct {
+	Name string
+	Err  error
+}
+
+func (e ErrGenerateSecret) Error() string {
+	return fmt.Sprintf("failed to generate secret %q: %v", e.Name, e.Err)
+}
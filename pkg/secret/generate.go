@@ -0,0 +1,412 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package secret
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+	"sigs.k8s.io/yaml"
+
+	"opendev.org/airship/airshipctl/pkg/api/v1alpha1"
+	"opendev.org/airship/airshipctl/pkg/document/plugin/templater/extlib"
+	"opendev.org/airship/airshipctl/pkg/document/sops"
+)
+
+const (
+	// DefaultRenewBefore defines how long before the expiration the secret is regenerated
+	DefaultRenewBefore = 720 * time.Hour
+	// ForceAll can be passed to force regeneration of all the secrets
+	ForceAll = "all"
+
+	generatedKind           = "VariableCatalogue"
+	generatedEncryptedRegex = "^data$"
+
+	defaultCAValidityDays   = 3650
+	defaultCertValidityDays = 365
+	defaultCertKeyBits      = 2048
+	defaultSSHKeyBits       = 4096
+	defaultPasswordLength   = 16
+
+	passwordChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+// GeneratedSecret holds the values of the generated secret
+type GeneratedSecret struct {
+	Type      v1alpha1.SecretType `json:"type"`
+	Generated string              `json:"generated"`
+	Expires   string              `json:"expires,omitempty"`
+	// SpecHash is the hash of the definition the secret was generated from
+	SpecHash string            `json:"specHash,omitempty"`
+	Data     map[string]string `json:"data"`
+}
+
+// generatedCatalogue is the document the generated secrets are stored in
+type generatedCatalogue struct {
+	APIVersion string                      `json:"apiVersion"`
+	Kind       string                      `json:"kind"`
+	Metadata   generatedMetadata           `json:"metadata"`
+	Secrets    map[string]*GeneratedSecret `json:"secrets"`
+}
+
+type generatedMetadata struct {
+	Name string `json:"name"`
+}
+
+// GenerateCommand holds options for secret generate command
+type GenerateCommand struct {
+	SpecFile string
+	Force    []string
+	DryRun   bool
+	KeyFiles sops.KeyFiles
+	Writer   io.Writer
+}
+
+// RunE generates the secrets defined by SecretGeneration document which are missing
+// or about to expire and writes them encrypted to the output file
+func (c *GenerateCommand) RunE() error {
+	spec, err := readSpec(c.SpecFile)
+	if err != nil {
+		return err
+	}
+	if err = validateSpec(spec); err != nil {
+		return err
+	}
+	keys, err := sops.KeysFromEnv(c.KeyFiles)
+	if err != nil {
+		return err
+	}
+
+	outputPath := filepath.Join(filepath.Dir(c.SpecFile), spec.Spec.Output.Path)
+	existing, err := readGenerated(outputPath, spec.Spec.Output.Name, keys)
+	if err != nil {
+		return err
+	}
+
+	renewBefore := DefaultRenewBefore
+	if spec.Spec.RenewBefore != nil {
+		renewBefore = spec.Spec.RenewBefore.Duration
+	}
+	now := time.Now().UTC()
+
+	changed := false
+	result := map[string]*GeneratedSecret{}
+	regenerated := map[string]bool{}
+	for _, def := range spec.Spec.Secrets {
+		reason, err := c.regenerationReason(def, existing[def.Name], regenerated, now.Add(renewBefore))
+		if err != nil {
+			return err
+		}
+		if reason == "" {
+			result[def.Name] = existing[def.Name]
+			fmt.Fprintf(c.Writer, "%s: unchanged\n", def.Name)
+			continue
+		}
+		if result[def.Name], err = generateSecret(def, result, now); err != nil {
+			return ErrGenerateSecret{Name: def.Name, Err: err}
+		}
+		regenerated[def.Name] = true
+		changed = true
+		fmt.Fprintf(c.Writer, "%s: %s\n", def.Name, reason)
+	}
+	for name := range existing {
+		if _, ok := result[name]; !ok {
+			changed = true
+			fmt.Fprintf(c.Writer, "%s: removed\n", name)
+		}
+	}
+
+	if !changed || c.DryRun {
+		return nil
+	}
+	return writeGenerated(outputPath, spec.Spec.Output, result, keys)
+}
+
+// regenerationReason returns the reason why the secret has to be generated
+// or empty string if the existing secret can be kept
+func (c *GenerateCommand) regenerationReason(def v1alpha1.SecretDefinition, old *GeneratedSecret,
+	regenerated map[string]bool, deadline time.Time) (string, error) {
+	switch {
+	case old == nil:
+		return "created", nil
+	case c.forced(def.Name):
+		return "regenerated (forced)", nil
+	case old.Type != def.Type:
+		return "regenerated (type changed)", nil
+	case old.SpecHash != specHash(def):
+		return "regenerated (definition changed)", nil
+	case def.Type == v1alpha1.SecretTypeCert && regenerated[def.CA]:
+		return "regenerated (CA regenerated)", nil
+	case old.Expires == "":
+		return "", nil
+	}
+
+	expires, err := time.Parse(time.RFC3339, old.Expires)
+	if err != nil {
+		return "", ErrInvalidGeneratedSecret{Name: def.Name, Err: err}
+	}
+	if expires.Before(deadline) {
+		return fmt.Sprintf("regenerated (expires %s)", old.Expires), nil
+	}
+	return "", nil
+}
+
+// specHash returns the hash of the secret definition, the secret is regenerated when it changes
+func specHash(def v1alpha1.SecretDefinition) string {
+	// marshaling of the struct of strings, slices and numbers can't fail
+	data, _ := json.Marshal(def)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *GenerateCommand) forced(name string) bool {
+	for _, f := range c.Force {
+		if f == name || f == ForceAll {
+			return true
+		}
+	}
+	return false
+}
+
+func readSpec(file string) (*v1alpha1.SecretGeneration, error) {
+	nodes, err := readFile(file)
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range nodes {
+		if node.GetKind() != "SecretGeneration" {
+			continue
+		}
+		data, err := node.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		spec := &v1alpha1.SecretGeneration{}
+		if err = yaml.Unmarshal(data, spec); err != nil {
+			return nil, err
+		}
+		return spec, nil
+	}
+	return nil, ErrSecretGenerationNotFound{File: file}
+}
+
+func validateSpec(spec *v1alpha1.SecretGeneration) error {
+	if spec.Spec.Output.Path == "" || spec.Spec.Output.Name == "" {
+		return ErrInvalidSecretDefinition{Reason: "output path and name must be set"}
+	}
+	types := map[string]v1alpha1.SecretType{}
+	for _, def := range spec.Spec.Secrets {
+		if def.Name == "" {
+			return ErrInvalidSecretDefinition{Reason: "secret name must be set"}
+		}
+		if _, exists := types[def.Name]; exists {
+			return ErrInvalidSecretDefinition{Name: def.Name, Reason: "secret is defined more than once"}
+		}
+		switch def.Type {
+		case v1alpha1.SecretTypeCA, v1alpha1.SecretTypeCert:
+			if def.Subject == nil || def.Subject.CommonName == "" {
+				return ErrInvalidSecretDefinition{Name: def.Name, Reason: "subject common name must be set"}
+			}
+			if def.Type == v1alpha1.SecretTypeCert && types[def.CA] != v1alpha1.SecretTypeCA {
+				return ErrInvalidSecretDefinition{Name: def.Name,
+					Reason: fmt.Sprintf("ca %q must be defined before the certificate", def.CA)}
+			}
+			for _, ip := range def.IPAddresses {
+				if net.ParseIP(ip) == nil {
+					return ErrInvalidSecretDefinition{Name: def.Name, Reason: fmt.Sprintf("invalid IP address %q", ip)}
+				}
+			}
+		case v1alpha1.SecretTypePassword, v1alpha1.SecretTypeSSHKeyPair:
+		default:
+			return ErrInvalidSecretDefinition{Name: def.Name, Reason: fmt.Sprintf("unknown secret type %q", def.Type)}
+		}
+		types[def.Name] = def.Type
+	}
+	return nil
+}
+
+// readGenerated returns the secrets generated previously, the file may not exist yet
+func readGenerated(file, name string, keys *sops.Keys) (map[string]*GeneratedSecret, error) {
+	nodes, err := readFile(file)
+	if os.IsNotExist(err) {
+		return map[string]*GeneratedSecret{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range nodes {
+		if node.GetKind() != generatedKind || node.GetName() != name {
+			continue
+		}
+		if sops.IsEncrypted(node) {
+			if _, err = sops.Decrypt(node, keys, false); err != nil {
+				return nil, ErrProcessDocument{File: file, DocName: name, Err: err}
+			}
+		}
+		data, err := node.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		catalogue := &generatedCatalogue{}
+		if err = yaml.Unmarshal(data, catalogue); err != nil {
+			return nil, err
+		}
+		if catalogue.Secrets == nil {
+			catalogue.Secrets = map[string]*GeneratedSecret{}
+		}
+		return catalogue.Secrets, nil
+	}
+	return map[string]*GeneratedSecret{}, nil
+}
+
+func writeGenerated(file string, output v1alpha1.SecretGenerationOutput,
+	secrets map[string]*GeneratedSecret, keys *sops.Keys) error {
+	data, err := yaml.Marshal(generatedCatalogue{
+		APIVersion: v1alpha1.GroupVersion.String(),
+		Kind:       generatedKind,
+		Metadata:   generatedMetadata{Name: output.Name},
+		Secrets:    secrets,
+	})
+	if err != nil {
+		return err
+	}
+	node, err := kyaml.Parse(string(data))
+	if err != nil {
+		return err
+	}
+	err = sops.Encrypt(node, keys, sops.EncryptOptions{
+		AgeRecipients:   output.Encryption.Age,
+		PGPFingerprints: output.Encryption.PGP,
+		EncryptedRegex:  generatedEncryptedRegex,
+	})
+	if err != nil {
+		return ErrProcessDocument{File: file, DocName: output.Name, Err: err}
+	}
+
+	buf := &bytes.Buffer{}
+	if err = (kio.ByteWriter{Writer: buf}).Write([]*kyaml.RNode{node}); err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, buf.Bytes(), 0600)
+}
+
+func generateSecret(def v1alpha1.SecretDefinition, generated map[string]*GeneratedSecret,
+	now time.Time) (*GeneratedSecret, error) {
+	secret := &GeneratedSecret{Type: def.Type, Generated: now.Format(time.RFC3339), SpecHash: specHash(def)}
+	if def.ValidityDays > 0 {
+		secret.Expires = now.AddDate(0, 0, def.ValidityDays).Format(time.RFC3339)
+	}
+
+	var err error
+	switch def.Type {
+	case v1alpha1.SecretTypeCA:
+		secret.Data, secret.Expires, err = generateCertificate(def, nil, now)
+	case v1alpha1.SecretTypeCert:
+		secret.Data, secret.Expires, err = generateCertificate(def, generated[def.CA], now)
+	case v1alpha1.SecretTypePassword:
+		secret.Data, err = generatePassword(def)
+	case v1alpha1.SecretTypeSSHKeyPair:
+		secret.Data, err = generateSSHKeyPair(def)
+	}
+	return secret, err
+}
+
+// generateCertificate generates certificate signed by ca or self-signed CA certificate if ca is nil
+func generateCertificate(def v1alpha1.SecretDefinition, ca *GeneratedSecret,
+	now time.Time) (map[string]string, string, error) {
+	validityDays, keyBits := def.ValidityDays, def.KeyBits
+	if validityDays == 0 {
+		validityDays = defaultCertValidityDays
+		if ca == nil {
+			validityDays = defaultCAValidityDays
+		}
+	}
+	if keyBits == 0 {
+		keyBits = defaultCertKeyBits
+	}
+	subject := pkix.Name{
+		CommonName:   def.Subject.CommonName,
+		Organization: def.Subject.Organization,
+	}
+
+	var cert extlib.Certificate
+	var err error
+	if ca == nil {
+		cert, err = extlib.GenerateCertificateAuthority(subject, validityDays, keyBits)
+	} else {
+		ipAddresses := make([]net.IP, 0, len(def.IPAddresses))
+		for _, ip := range def.IPAddresses {
+			ipAddresses = append(ipAddresses, net.ParseIP(ip))
+		}
+		signer := extlib.Certificate{Cert: ca.Data["tls.crt"], Key: ca.Data["tls.key"]}
+		cert, err = extlib.GenerateSignedCertificate(subject, ipAddresses, def.DNSNames, validityDays, keyBits, signer)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return map[string]string{
+		"tls.crt": cert.Cert,
+		"tls.key": cert.Key,
+	}, now.AddDate(0, 0, validityDays).Format(time.RFC3339), nil
+}
+
+func generatePassword(def v1alpha1.SecretDefinition) (map[string]string, error) {
+	length := def.Length
+	if length == 0 {
+		length = defaultPasswordLength
+	}
+	password := make([]byte, length)
+	max := big.NewInt(int64(len(passwordChars)))
+	for i := range password {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return nil, err
+		}
+		password[i] = passwordChars[n.Int64()]
+	}
+	return map[string]string{"password": string(password)}, nil
+}
+
+func generateSSHKeyPair(def v1alpha1.SecretDefinition) (map[string]string, error) {
+	keyBits := def.KeyBits
+	if keyBits == 0 {
+		keyBits = defaultSSHKeyBits
+	}
+	key, err := extlib.GenerateSSHKeyPair(keyBits)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		"ssh-privatekey": key.Private,
+		"ssh-publickey":  key.Public,
+	}, nil
+}
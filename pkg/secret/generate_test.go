@@ -0,0 +1,249 @@
+/*
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package secret_test
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"opendev.org/airship/airshipctl/pkg/document/sops"
+	"opendev.org/airship/airshipctl/pkg/secret"
+	"opendev.org/airship/airshipctl/testutil"
+)
+
+const testGenerationSpec = `apiVersion: airshipit.org/v1alpha1
+kind: SecretGeneration
+metadata:
+  name: site-secrets
+spec:
+  output:
+    path: generated/secrets.yaml
+    name: generated-secrets
+    encryption:
+      age:
+      - %s
+  secrets:
+  - name: kubernetes-ca
+    type: ca
+    keyBits: 1024
+    subject:
+      commonName: Kubernetes API
+  - name: apiserver
+    type: cert
+    ca: kubernetes-ca
+    keyBits: 1024
+    validityDays: 10
+    subject:
+      commonName: kube-apiserver
+    ipAddresses:
+    - 10.23.25.102
+    dnsNames:
+    - kubernetes.default
+  - name: admin-password
+    type: password
+    length: 24
+  - name: ssh
+    type: sshKeyPair
+    keyBits: 1024
+`
+
+func TestGenerate(t *testing.T) {
+	identity, err := sops.GenerateAgeIdentity()
+	require.NoError(t, err)
+	os.Setenv(sops.EnvAgeKey, identity.String())
+	defer os.Unsetenv(sops.EnvAgeKey)
+
+	dir, cleanup := testutil.TempDir(t, "airship-secret")
+	defer cleanup(t)
+	specFile := filepath.Join(dir, "secrets.yaml")
+	outputFile := filepath.Join(dir, "generated", "secrets.yaml")
+	require.NoError(t, ioutil.WriteFile(specFile, []byte(fmt.Sprintf(testGenerationSpec, identity.Recipient())), 0600))
+
+	out := &bytes.Buffer{}
+	cmd := &secret.GenerateCommand{SpecFile: specFile, Writer: out}
+	require.NoError(t, cmd.RunE())
+	// apiserver certificate expires in 10 days, so it's always regenerated
+	assert.Equal(t, "kubernetes-ca: created\napiserver: created\nadmin-password: created\nssh: created\n",
+		out.String())
+	first := readGenerated(t, outputFile)
+	assert.Len(t, first.data("admin-password", "password"), 24)
+	assert.Contains(t, first.data("ssh", "ssh-publickey"), "ssh-rsa ")
+	verifyCert(t, first.data("kubernetes-ca", "tls.crt"), first.data("apiserver", "tls.crt"))
+
+	out.Reset()
+	require.NoError(t, cmd.RunE())
+	second := readGenerated(t, outputFile)
+	assert.Regexp(t, "^kubernetes-ca: unchanged\napiserver: regenerated \\(expires .*\\)\n"+
+		"admin-password: unchanged\nssh: unchanged\n$", out.String())
+	assert.Equal(t, first.data("admin-password", "password"),
+		second.data("admin-password", "password"))
+	assert.NotEqual(t, first.data("apiserver", "tls.crt"), second.data("apiserver", "tls.crt"))
+
+	out.Reset()
+	cmd.Force = []string{"kubernetes-ca"}
+	require.NoError(t, cmd.RunE())
+	third := readGenerated(t, outputFile)
+	assert.Regexp(t, "^kubernetes-ca: regenerated \\(forced\\)\napiserver: regenerated \\(CA regenerated\\)\n",
+		out.String())
+	verifyCert(t, third.data("kubernetes-ca", "tls.crt"), third.data("apiserver", "tls.crt"))
+
+	before, err := ioutil.ReadFile(outputFile)
+	require.NoError(t, err)
+	cmd.Force = []string{secret.ForceAll}
+	cmd.DryRun = true
+	require.NoError(t, cmd.RunE())
+	after, err := ioutil.ReadFile(outputFile)
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+
+	out.Reset()
+	spec := strings.Replace(fmt.Sprintf(testGenerationSpec, identity.Recipient()), "length: 24", "length: 32", 1)
+	require.NoError(t, ioutil.WriteFile(specFile, []byte(spec), 0600))
+	cmd.Force = nil
+	cmd.DryRun = false
+	require.NoError(t, cmd.RunE())
+	assert.Contains(t, out.String(), "admin-password: regenerated (definition changed)\nssh: unchanged\n")
+	fourth := readGenerated(t, outputFile)
+	assert.Len(t, fourth.data("admin-password", "password"), 32)
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        string
+		expectedErr error
+	}{
+		{
+			name:        "no spec",
+			spec:        "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: test\n",
+			expectedErr: secret.ErrSecretGenerationNotFound{},
+		},
+		{
+			name: "no output",
+			spec: `apiVersion: airshipit.org/v1alpha1
+kind: SecretGeneration
+metadata:
+  name: site-secrets
+spec:
+  secrets: []
+`,
+			expectedErr: secret.ErrInvalidSecretDefinition{Reason: "output path and name must be set"},
+		},
+		{
+			name: "unknown ca",
+			spec: `apiVersion: airshipit.org/v1alpha1
+kind: SecretGeneration
+metadata:
+  name: site-secrets
+spec:
+  output:
+    path: out.yaml
+    name: out
+  secrets:
+  - name: cert
+    type: cert
+    ca: ca
+    subject:
+      commonName: test
+`,
+			expectedErr: secret.ErrInvalidSecretDefinition{Name: "cert",
+				Reason: `ca "ca" must be defined before the certificate`},
+		},
+		{
+			name: "unknown type",
+			spec: `apiVersion: airshipit.org/v1alpha1
+kind: SecretGeneration
+metadata:
+  name: site-secrets
+spec:
+  output:
+    path: out.yaml
+    name: out
+  secrets:
+  - name: token
+    type: token
+`,
+			expectedErr: secret.ErrInvalidSecretDefinition{Name: "token", Reason: `unknown secret type "token"`},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			dir, cleanup := testutil.TempDir(t, "airship-secret")
+			defer cleanup(t)
+			specFile := filepath.Join(dir, "secrets.yaml")
+			require.NoError(t, ioutil.WriteFile(specFile, []byte(tt.spec), 0600))
+
+			if e, ok := tt.expectedErr.(secret.ErrSecretGenerationNotFound); ok {
+				e.File = specFile
+				tt.expectedErr = e
+			}
+			cmd := &secret.GenerateCommand{SpecFile: specFile, Writer: ioutil.Discard}
+			assert.Equal(t, tt.expectedErr, cmd.RunE())
+		})
+	}
+}
+
+type generated struct {
+	*kyaml.RNode
+}
+
+// data returns the value of the key from the data of generated secret
+func (g generated) data(name, key string) string {
+	node, err := g.Pipe(kyaml.Lookup("secrets", name, "data", key))
+	if err != nil || node == nil {
+		return ""
+	}
+	return kyaml.GetValue(node)
+}
+
+func readGenerated(t *testing.T, file string) generated {
+	data, err := ioutil.ReadFile(file)
+	require.NoError(t, err)
+	node, err := kyaml.Parse(string(data))
+	require.NoError(t, err)
+	require.True(t, sops.IsEncrypted(node))
+	assert.NotContains(t, string(data), "PRIVATE KEY")
+
+	keys, err := sops.KeysFromEnv(sops.KeyFiles{})
+	require.NoError(t, err)
+	_, err = sops.Decrypt(node, keys, false)
+	require.NoError(t, err)
+	return generated{node}
+}
+
+func verifyCert(t *testing.T, caPEM, certPEM string) {
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM([]byte(caPEM)))
+	block, _ := pem.Decode([]byte(certPEM))
+	require.NotNil(t, block)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kubernetes.default"}, cert.DNSNames)
+	_, err = cert.Verify(x509.VerifyOptions{Roots: roots, DNSName: "kubernetes.default"})
+	assert.NoError(t, err)
+}
@@ -25,10 +25,12 @@ type MockContainer struct {
 	MockImagePull         func() error
 	MockRunCommand        func() error
 	MockGetContainerLogs  func() (io.ReadCloser, error)
+	MockKillContainer     func() error
 	MockRmContainer       func() error
 	MockGetID             func() string
 	MockWaitUntilFinished func() error
 	MockInspectContainer  func() (container.State, error)
+	MockGetImageID        func(string) (string, error)
 }
 
 var _ container.Container = &MockContainer{}
@@ -48,6 +50,11 @@ func (mc *MockContainer) GetContainerLogs(container.GetLogOptions) (io.ReadClose
 	return mc.MockGetContainerLogs()
 }
 
+// KillContainer Container interface implementation for unit test purposes
+func (mc *MockContainer) KillContainer() error {
+	return mc.MockKillContainer()
+}
+
 // RmContainer Container interface implementation for unit test purposes
 func (mc *MockContainer) RmContainer() error {
 	return mc.MockRmContainer()
@@ -67,3 +74,8 @@ func (mc *MockContainer) WaitUntilFinished() error {
 func (mc *MockContainer) InspectContainer() (container.State, error) {
 	return mc.MockInspectContainer()
 }
+
+// GetImageID Container interface implementation for unit test purposes
+func (mc *MockContainer) GetImageID(url string) (string, error) {
+	return mc.MockGetImageID(url)
+}
@@ -149,6 +149,42 @@ func (mb *MockBundle) Append(doc document.Document) error {
 	return args.Error(0)
 }
 
+// Remove mock
+func (mb *MockBundle) Remove(selector document.Selector) error {
+	args := mb.Called(selector)
+	return args.Error(0)
+}
+
+// Replace mock
+func (mb *MockBundle) Replace(doc document.Document) error {
+	args := mb.Called(doc)
+	return args.Error(0)
+}
+
+// Patch mock
+func (mb *MockBundle) Patch(selector document.Selector, patchType string, patch []byte) error {
+	args := mb.Called(selector, patchType, patch)
+	return args.Error(0)
+}
+
+// WriteJSON mock
+func (mb *MockBundle) WriteJSON(out io.Writer) error {
+	args := mb.Called(out)
+	return args.Error(0)
+}
+
+// WriteResourceList mock
+func (mb *MockBundle) WriteResourceList(out io.Writer) error {
+	args := mb.Called(out)
+	return args.Error(0)
+}
+
+// WriteDir mock
+func (mb *MockBundle) WriteDir(fSys fs.FileSystem, dir string) error {
+	args := mb.Called(fSys, dir)
+	return args.Error(0)
+}
+
 var (
 	// EmptyBundleFactory returns empty MockBundle
 	EmptyBundleFactory document.BundleFactoryFunc = func() (document.Bundle, error) {
@@ -16,6 +16,8 @@ package document
 
 import (
 	"k8s.io/apimachinery/pkg/runtime"
+
+	"opendev.org/airship/airshipctl/pkg/document"
 )
 
 // MockDocument implements Document for unit test purposes
@@ -32,12 +34,15 @@ type MockDocument struct {
 	MockGetMap         func() (map[string]interface{}, error)
 	MockGetName        func() string
 	MockGetNamespace   func() string
+	MockGetOrigin      func() (document.Origin, bool)
 	MockGetSlice       func() ([]interface{}, error)
 	MockGetString      func() (string, error)
 	MockGetStringMap   func() (map[string]string, error)
 	MockGetStringSlice func() ([]string, error)
 	MockGetVersion     func() string
 	MockLabel          func()
+	MockSetField       func() error
+	MockDeleteField    func() error
 	MockMarshalJSON    func() ([]byte, error)
 	MockToObject       func() error
 	MockToAPIObject    func() error
@@ -104,6 +109,11 @@ func (md *MockDocument) GetName() string {
 	return md.MockGetName()
 }
 
+// GetOrigin Document interface implementation for unit test purposes
+func (md *MockDocument) GetOrigin() (document.Origin, bool) {
+	return md.MockGetOrigin()
+}
+
 // GetNamespace Document interface implementation for unit test purposes
 func (md *MockDocument) GetNamespace() string {
 	return md.MockGetNamespace()
@@ -139,6 +149,16 @@ func (md *MockDocument) Label(_ map[string]string) {
 	md.MockLabel()
 }
 
+// SetField Document interface implementation for unit test purposes
+func (md *MockDocument) SetField(_ string, _ interface{}) error {
+	return md.MockSetField()
+}
+
+// DeleteField Document interface implementation for unit test purposes
+func (md *MockDocument) DeleteField(_ string) error {
+	return md.MockDeleteField()
+}
+
 // MarshalJSON Document interface implementation for unit test purposes
 func (md *MockDocument) MarshalJSON() ([]byte, error) {
 	return md.MockMarshalJSON()
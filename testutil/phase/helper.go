@@ -156,3 +156,13 @@ func (mh *MockHelper) PhaseConfigBundle() document.Bundle {
 	}
 	return val
 }
+
+// BundleOptions mock
+func (mh *MockHelper) BundleOptions() []document.BundleOption {
+	args := mh.Called()
+	val, ok := args.Get(0).([]document.BundleOption)
+	if !ok {
+		return nil
+	}
+	return val
+}
@@ -49,8 +49,7 @@ unset SOPS_IMPORT_PGP
 
 echo "Sanity check 1: Check that we can decrypt everything with U1 and U2 creds"
 # set user1 key
-cp ${WORKDIR}/manifests/.private-keys/my.key ${WORKDIR}/manifests/.private-keys/my.key.old
-cp ${WORKDIR}/manifests/.private-keys/exampleU1.key ${WORKDIR}/manifests/.private-keys/my.key
+export SOPS_PGP_KEY_FILE=${WORKDIR}/manifests/.private-keys/exampleU1.key
 
 #make sure that decrypted valus stay the same
 decrypted2=$(airshipctl phase run secret-show)
@@ -59,7 +58,7 @@ if [ "${decrypted1}" != "${decrypted2}" ]; then
         exit 1
 fi
 # set user2 key
-cp ${WORKDIR}/manifests/.private-keys/exampleU2.key ${WORKDIR}/manifests/.private-keys/my.key
+export SOPS_PGP_KEY_FILE=${WORKDIR}/manifests/.private-keys/exampleU2.key
 
 #make sure that decrypted valus stay the same
 decrypted2=$(airshipctl phase run secret-show)
@@ -79,13 +78,13 @@ if [ "${decrypted1}" != "${decrypted2}" ]; then
 fi
 
 echo "Sanity check 3: Try to reecnrypt ephemeral by user 3, who can't decrypt target"
-cp ${WORKDIR}/manifests/.private-keys/exampleU3.key ${WORKDIR}/manifests/.private-keys/my.key
-TOLERATE_DECRYPTION_FAILURES=true ONLY_CLUSTERS=ephemeral airshipctl phase run secret-update
+export SOPS_PGP_KEY_FILE=${WORKDIR}/manifests/.private-keys/exampleU3.key
+ONLY_CLUSTERS=ephemeral airshipctl phase run secret-update --tolerate-decryption-failures
 
-decrypted3=$(TOLERATE_DECRYPTION_FAILURES=true airshipctl phase run secret-show)
+decrypted3=$(airshipctl phase run secret-show --tolerate-decryption-failures)
 if [ "${decrypted1}" == "${decrypted3}" ]; then
         echo "reencrypted decrypted value should be different because it has to contain unencrypted data"
         exit 1
 fi
 
-mv ${WORKDIR}/manifests/.private-keys/my.key.old ${WORKDIR}/manifests/.private-keys/my.key
+unset SOPS_PGP_KEY_FILE